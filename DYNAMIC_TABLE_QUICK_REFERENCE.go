@@ -21,7 +21,7 @@ package db
 ```go
 config := db.NewDynamicTableConfig("project_tasks").           // 表名前缀
     WithDescription("项目的任务表").
-    WithParentTable("projects", "").                           // 监听 projects 表
+    WithParentTable("projects", nil).                           // 监听 projects 表
     WithStrategy("auto").                                       // 自动创建
     AddField(db.NewDynamicTableField("id", db.TypeInteger).
         AsPrimaryKey().WithAutoinc()).
@@ -95,7 +95,7 @@ db.NewDynamicTableField("email", db.TypeString).
 
 ```go
 config := db.NewDynamicTableConfig("project_records").
-    WithParentTable("projects", "").
+    WithParentTable("projects", nil).
     WithStrategy("auto").
     AddField(db.NewDynamicTableField("id", db.TypeInteger).
         AsPrimaryKey().WithAutoinc()).
@@ -108,7 +108,7 @@ config := db.NewDynamicTableConfig("project_records").
 
 ```go
 config := db.NewDynamicTableConfig("premium_data").
-    WithParentTable("users", "plan = 'premium'").  // 仅高级用户
+    WithParentTable("users", db.Eq("plan", "premium")).  // 仅高级用户
     WithStrategy("auto").
     AddField(...)
 
@@ -220,7 +220,7 @@ func main() {
 
     // 2. 定义动态表配置
     config := db.NewDynamicTableConfig("user_profiles").
-        WithParentTable("users", "").
+        WithParentTable("users", nil).
         WithStrategy("auto").
         AddField(db.NewDynamicTableField("id", db.TypeInteger).
             AsPrimaryKey().WithAutoinc()).
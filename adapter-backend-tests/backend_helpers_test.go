@@ -121,7 +121,7 @@ func querySQL(ctx context.Context, t *testing.T, repo *db.Repository, sql string
 }
 
 // querySingleRow 查询单行
-func querySingleRow(ctx context.Context, t *testing.T, repo *db.Repository, sql string) *sql.Row {
+func querySingleRow(ctx context.Context, t *testing.T, repo *db.Repository, sql string) *db.Row {
 	return repo.QueryRow(ctx, sql)
 }
 
@@ -3,8 +3,11 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -81,6 +84,9 @@ type Config struct {
 
 	// 其他参数 (可选的适配器特定参数)
 	Options map[string]interface{} `json:"options" yaml:"options"`
+
+	// 严格模式：NewRepository 拒绝适配器不认识的 Options 键（默认宽松，忽略未知键）
+	Strict bool `json:"strict" yaml:"strict"`
 }
 
 // PoolConfig 连接池配置 (参考 Ecto 的设计)
@@ -100,8 +106,9 @@ type AdapterFactory interface {
 
 // Repository 数据库仓储对象 (类似 Ecto.Repo)
 type Repository struct {
-	adapter Adapter
-	mu      sync.RWMutex
+	adapter     Adapter
+	middlewares []func(next QueryFunc) QueryFunc
+	mu          sync.RWMutex
 }
 
 // 全局适配器工厂注册表
@@ -112,6 +119,46 @@ var (
 	configRegistryMutex   sync.RWMutex
 )
 
+// adapterOptionKeys 各适配器识别的 Options 键，用于严格模式校验
+var (
+	adapterOptionKeys = map[string]map[string]bool{
+		"mongodb": {"uri": true},
+	}
+	optionKeysMutex sync.RWMutex
+)
+
+// RegisterAdapterOptions 声明适配器识别的 Options 键（可多次调用追加）
+// 自定义适配器需要声明自己读取的键，否则严格模式下会拒绝这些键
+func RegisterAdapterOptions(adapter string, keys ...string) {
+	optionKeysMutex.Lock()
+	defer optionKeysMutex.Unlock()
+
+	known, ok := adapterOptionKeys[adapter]
+	if !ok {
+		known = make(map[string]bool)
+		adapterOptionKeys[adapter] = known
+	}
+	for _, key := range keys {
+		known[key] = true
+	}
+}
+
+// unknownOptions 返回适配器不识别的 Options 键（已排序）
+func (c *Config) unknownOptions() []string {
+	optionKeysMutex.RLock()
+	known := adapterOptionKeys[c.Adapter]
+	optionKeysMutex.RUnlock()
+
+	unknown := make([]string, 0)
+	for key := range c.Options {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
 // RegisterAdapter 注册适配器工厂
 func RegisterAdapter(factory AdapterFactory) {
 	factoriesMutex.Lock()
@@ -258,6 +305,12 @@ func NewRepository(config *Config) (*Repository, error) {
 		return nil, fmt.Errorf("unsupported adapter: %s", config.Adapter)
 	}
 
+	if config.Strict {
+		if unknown := config.unknownOptions(); len(unknown) > 0 {
+			return nil, fmt.Errorf("unknown options for adapter %s: %s", config.Adapter, strings.Join(unknown, ", "))
+		}
+	}
+
 	// 使用工厂创建适配器
 	adapter, err := factory.Create(config)
 	if err != nil {
@@ -273,7 +326,7 @@ func (r *Repository) Connect(ctx context.Context) error {
 	defer r.mu.Unlock()
 
 	if r.adapter == nil {
-		return fmt.Errorf("adapter is not initialized")
+		return ErrNotConnected
 	}
 	return r.adapter.Connect(ctx, nil)
 }
@@ -295,42 +348,79 @@ func (r *Repository) Ping(ctx context.Context) error {
 	defer r.mu.RUnlock()
 
 	if r.adapter == nil {
-		return fmt.Errorf("adapter is not initialized")
+		return ErrNotConnected
 	}
 	return r.adapter.Ping(ctx)
 }
 
 // Query 执行查询
 func (r *Repository) Query(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	if r.adapter == nil {
-		return nil, fmt.Errorf("adapter is not initialized")
+	res, err := r.invoke(ctx, &QueryOperation{Kind: OpQuery, SQL: sql, Args: args})
+	if err != nil {
+		return nil, err
 	}
-	return r.adapter.Query(ctx, sql, args...)
+	return res.Rows, nil
 }
 
 // QueryRow 执行单行查询
-func (r *Repository) QueryRow(ctx context.Context, sql string, args ...interface{}) *sql.Row {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+// 未连接或中间件拦截时不会返回 nil，而是返回在 Scan 时报告错误的 Row
+func (r *Repository) QueryRow(ctx context.Context, sql string, args ...interface{}) *Row {
+	res, err := r.invoke(ctx, &QueryOperation{Kind: OpQueryRow, SQL: sql, Args: args})
+	if err != nil {
+		return &Row{err: err}
+	}
+	if res.Row == nil {
+		return &Row{err: ErrNotConnected}
+	}
+	return &Row{row: res.Row}
+}
 
-	if r.adapter == nil {
-		return nil
+// ErrNotConnected 仓储未连接（没有可用的适配器）时返回的错误
+var ErrNotConnected = errors.New("adapter is not initialized")
+
+// Row Repository.QueryRow 的返回值，包装 *sql.Row
+// 查询无法执行时 Scan 返回对应错误（例如 ErrNotConnected），而不是对 nil 调用导致 panic；
+// nil 或零值 Row 同样返回 ErrNotConnected
+type Row struct {
+	row *sql.Row
+	err error
+}
+
+// Scan 将结果扫描到 dest，语义与 sql.Row.Scan 相同
+func (r *Row) Scan(dest ...interface{}) error {
+	if r == nil {
+		return ErrNotConnected
+	}
+	if r.err != nil {
+		return r.err
 	}
-	return r.adapter.QueryRow(ctx, sql, args...)
+	if r.row == nil {
+		return ErrNotConnected
+	}
+	return r.row.Scan(dest...)
+}
+
+// Err 返回查询错误（不会触发扫描）
+func (r *Row) Err() error {
+	if r == nil {
+		return ErrNotConnected
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if r.row == nil {
+		return ErrNotConnected
+	}
+	return r.row.Err()
 }
 
 // Exec 执行操作
 func (r *Repository) Exec(ctx context.Context, sql string, args ...interface{}) (sql.Result, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	if r.adapter == nil {
-		return nil, fmt.Errorf("adapter is not initialized")
+	res, err := r.invoke(ctx, &QueryOperation{Kind: OpExec, SQL: sql, Args: args})
+	if err != nil {
+		return nil, err
 	}
-	return r.adapter.Exec(ctx, sql, args...)
+	return res.Result, nil
 }
 
 // Begin 开始事务
@@ -339,7 +429,7 @@ func (r *Repository) Begin(ctx context.Context, opts ...interface{}) (Tx, error)
 	defer r.mu.RUnlock()
 
 	if r.adapter == nil {
-		return nil, fmt.Errorf("adapter is not initialized")
+		return nil, ErrNotConnected
 	}
 	return r.adapter.Begin(ctx, opts...)
 }
@@ -370,6 +460,33 @@ func (r *Repository) GetAdapter() Adapter {
 	return r.adapter
 }
 
+// sqlDialect 返回适配器查询构造器使用的 SQL 方言，非 SQL 适配器返回 nil
+func (r *Repository) sqlDialect() SQLDialect {
+	adapter := r.GetAdapter()
+	if adapter == nil {
+		return nil
+	}
+	provider, ok := adapter.GetQueryBuilderProvider().(interface{ Dialect() SQLDialect })
+	if !ok {
+		return nil
+	}
+	return provider.Dialect()
+}
+
+// Capabilities 返回适配器查询构造器的能力声明，便于可移植代码在运行时按后端分支
+// 未连接或适配器不提供查询构造器（如 MongoDB）时返回 nil
+func (r *Repository) Capabilities() *QueryBuilderCapabilities {
+	adapter := r.GetAdapter()
+	if adapter == nil {
+		return nil
+	}
+	provider := adapter.GetQueryBuilderProvider()
+	if provider == nil {
+		return nil
+	}
+	return provider.GetCapabilities()
+}
+
 // RegisterScheduledTask 注册定时任务
 // 支持按月自动创建表等后台任务，具体实现由各个适配器决定：
 //   - PostgreSQL: 使用触发器和 pg_cron 扩展
@@ -380,7 +497,7 @@ func (r *Repository) RegisterScheduledTask(ctx context.Context, task *ScheduledT
 	defer r.mu.RUnlock()
 
 	if r.adapter == nil {
-		return fmt.Errorf("adapter is not initialized")
+		return ErrNotConnected
 	}
 
 	if err := task.Validate(); err != nil {
@@ -396,7 +513,7 @@ func (r *Repository) UnregisterScheduledTask(ctx context.Context, taskName strin
 	defer r.mu.RUnlock()
 
 	if r.adapter == nil {
-		return fmt.Errorf("adapter is not initialized")
+		return ErrNotConnected
 	}
 
 	if taskName == "" {
@@ -412,7 +529,7 @@ func (r *Repository) ListScheduledTasks(ctx context.Context) ([]*ScheduledTaskSt
 	defer r.mu.RUnlock()
 
 	if r.adapter == nil {
-		return nil, fmt.Errorf("adapter is not initialized")
+		return nil, ErrNotConnected
 	}
 
 	return r.adapter.ListScheduledTasks(ctx)
@@ -454,6 +571,7 @@ type QueryBuilderCapabilities struct {
 	SupportsOffset   bool // OFFSET
 	SupportsJoin     bool // JOIN（关系查询）
 	SupportsSubquery bool // 子查询
+	SupportsReturning bool // INSERT/UPDATE/DELETE ... RETURNING
 	
 	// 优化特性
 	SupportsQueryPlan bool // 查询计划分析
@@ -0,0 +1,123 @@
+package db
+
+import "context"
+
+// ==================== 结构化构建结果 ====================
+
+// BuiltQuery BuildQuery 的结果：除 SQL 和参数外还记录查询引用的表和列，
+// 查询缓存、审计和治理工具可以直接读取，不必重新解析 SQL
+type BuiltQuery struct {
+	SQL  string
+	Args []interface{}
+
+	// Tables 引用的表：主表在前，随后是 JOIN 和子查询中的表，去重
+	Tables []string
+	// Columns 主查询的选择列、JOIN 和 WHERE 条件、分组、排序引用的列，按首次出现的顺序去重；
+	// 表达式选择项和原样输出的条件/排序无法静态分析，不包含在内
+	Columns []string
+	// ArgCount ArgCount 估算的绑定参数个数，条件无法静态计数时可能与 len(Args) 不同
+	ArgCount int
+}
+
+// BuildQuery 构建查询并返回结构化结果，Build 是它的简化形式
+// 绑定参数个数超过方言的 MaxParameters 时返回 *ParameterLimitError
+func (qb *SQLQueryConstructor) BuildQuery(ctx context.Context) (*BuiltQuery, error) {
+	argIndex := 1
+	sql, args, err := qb.build(ctx, &argIndex)
+	if err != nil {
+		return nil, err
+	}
+	if max := qb.dialect.MaxParameters(); max > 0 && len(args) > max {
+		return nil, &ParameterLimitError{Count: len(args), Max: max}
+	}
+	sql = applyKeywordCase(sql, qb.keywordCase)
+	recordSQL(sql, args)
+
+	return &BuiltQuery{
+		SQL:      sql,
+		Args:     args,
+		Tables:   uniqueStrings(qb.referencedTables()),
+		Columns:  uniqueStrings(qb.referencedColumns()),
+		ArgCount: qb.ArgCount(),
+	}, nil
+}
+
+// referencedTables 收集主表、JOIN 表以及 LATERAL 和条件子查询中的表
+func (qb *SQLQueryConstructor) referencedTables() []string {
+	tables := []string{qb.schema.TableName()}
+	for _, join := range qb.joins {
+		if join.sub != nil {
+			tables = append(tables, join.sub.referencedTables()...)
+		} else {
+			tables = append(tables, join.table)
+		}
+		if join.on != nil {
+			tables = append(tables, subqueryTables(join.on)...)
+		}
+	}
+	for _, condition := range qb.conditions {
+		tables = append(tables, subqueryTables(condition)...)
+	}
+	return tables
+}
+
+// subqueryTables 收集条件中 IN / EXISTS 子查询引用的表
+func subqueryTables(condition Condition) []string {
+	switch c := condition.(type) {
+	case *CompositeCondition:
+		var tables []string
+		for _, child := range c.Conditions {
+			tables = append(tables, subqueryTables(child)...)
+		}
+		return tables
+	case *NotCondition:
+		return subqueryTables(c.Condition)
+	case *SimpleCondition:
+		if sub, ok := c.Value.(QueryConstructor); ok && sub != nil {
+			if native, ok := sub.GetNativeBuilder().(*SQLQueryConstructor); ok {
+				return native.referencedTables()
+			}
+		}
+	}
+	return nil
+}
+
+// referencedColumns 按 SELECT、JOIN、WHERE、GROUP BY、ORDER BY 的顺序收集引用的列
+func (qb *SQLQueryConstructor) referencedColumns() []string {
+	var columns []string
+	columns = append(columns, qb.distinctOn...)
+	for _, item := range qb.selectedCols {
+		if item.expr == "" {
+			columns = append(columns, item.column)
+		}
+	}
+	for _, join := range qb.joins {
+		if join.on != nil {
+			columns = append(columns, conditionColumns(join.on)...)
+		}
+	}
+	for _, condition := range qb.conditions {
+		columns = append(columns, conditionColumns(condition)...)
+	}
+	columns = append(columns, qb.groupBys...)
+	for _, order := range qb.orderBys {
+		if !order.raw {
+			columns = append(columns, order.Field)
+		}
+	}
+	return columns
+}
+
+// uniqueStrings 按首次出现的顺序去重，忽略空字符串
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
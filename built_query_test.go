@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestSQLQueryConstructorBuildQuery 测试连接查询的结构化结果记录引用的表、列和参数个数
+func TestSQLQueryConstructorBuildQuery(t *testing.T) {
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("name", TypeString).Build())
+	users.AddField(NewField("status", TypeString).Build())
+	banned := NewBaseSchema("bans")
+	banned.AddField(NewField("user_id", TypeInteger).Build())
+
+	sub := NewSQLQueryConstructor(banned, NewPostgreSQLDialect())
+	sub.Select("user_id")
+	qc := NewSQLQueryConstructor(users, NewPostgreSQLDialect())
+	qc.Join("posts", EqCol("posts.user_id", "users.id"))
+	qc.Select("name", "posts.title")
+	qc.Where(Eq("status", "active"))
+	qc.Where(Not(InSubquery("id", sub)))
+	qc.OrderBy("name", "ASC")
+
+	ctx := context.Background()
+	query, err := qc.BuildQuery(ctx)
+	if err != nil {
+		t.Fatalf("BuildQuery failed: %v", err)
+	}
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if query.SQL != sql || !reflect.DeepEqual(query.Args, args) {
+		t.Errorf("Expected Build to match BuildQuery, got %s %v vs %s %v", sql, args, query.SQL, query.Args)
+	}
+	if want := []string{"users", "posts", "bans"}; !reflect.DeepEqual(query.Tables, want) {
+		t.Errorf("Expected tables %v, got %v", want, query.Tables)
+	}
+	if want := []string{"name", "posts.title", "posts.user_id", "users.id", "status", "id"}; !reflect.DeepEqual(query.Columns, want) {
+		t.Errorf("Expected columns %v, got %v", want, query.Columns)
+	}
+	if query.ArgCount != 1 || len(query.Args) != 1 {
+		t.Errorf("Expected 1 argument, got %d (%v)", query.ArgCount, query.Args)
+	}
+
+	qc.Limit(0)
+	if _, err := qc.BuildQuery(ctx); err == nil {
+		t.Error("Expected chain error to be returned")
+	}
+}
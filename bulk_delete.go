@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// softDeleteField 软删除使用的时间戳字段
+const softDeleteField = "deleted_at"
+
+// DeleteByIDs 按主键批量删除，返回删除的总行数
+// ids 按方言的 MaxParameters 分块，每块生成一条 DELETE ... WHERE pk IN (...)，所有分块在同一事务中执行。
+// schema 含 deleted_at 字段时改为软删除：UPDATE ... SET deleted_at = 当前时间（已软删除的行不受影响）
+func (r *Repository) DeleteByIDs(ctx context.Context, schema Schema, ids []interface{}) (int64, error) {
+	pk := schema.PrimaryKeyField()
+	if pk == nil {
+		return 0, fmt.Errorf("DeleteByIDs: table %s has no primary key", schema.TableName())
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	dialect := r.sqlDialect()
+	if dialect == nil {
+		return 0, fmt.Errorf("DeleteByIDs: adapter %T does not provide a SQL dialect", r.GetAdapter())
+	}
+
+	table := dialect.QuoteIdentifier(schema.TableName())
+	softDelete := schema.GetField(softDeleteField) != nil
+	chunkSize := dialect.MaxParameters()
+	if chunkSize <= 0 {
+		chunkSize = len(ids)
+	}
+
+	var total int64
+	err := r.Transaction(ctx, nil, func(tx Tx) error {
+		for start := 0; start < len(ids); start += chunkSize {
+			end := start + chunkSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			var cond Condition = In(pk.Name, ids[start:end]...)
+			if softDelete {
+				cond = And(cond, IsNull(softDeleteField))
+			}
+			where, args, err := dialect.TranslateCondition(cond, nil)
+			if err != nil {
+				return err
+			}
+
+			stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+			if softDelete {
+				stmt = fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s",
+					table, dialect.QuoteIdentifier(softDeleteField), dialect.CurrentTimestamp(), where)
+			}
+			result, err := tx.Exec(ctx, stmt, args...)
+			if err != nil {
+				return fmt.Errorf("DeleteByIDs: %w", err)
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("DeleteByIDs: %w", err)
+			}
+			total += affected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
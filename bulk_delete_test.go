@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestDeleteByIDsChunking 测试按方言参数上限分块并在同一事务中执行
+func TestDeleteByIDsChunking(t *testing.T) {
+	repo, fake := newFakeRepository(NewSQLServerDialect())
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+
+	ids := make([]interface{}, 2500)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	deleted, err := repo.DeleteByIDs(context.Background(), schema, ids)
+	if err != nil {
+		t.Fatalf("DeleteByIDs failed: %v", err)
+	}
+	// 伪驱动每条语句影响 1 行
+	if deleted != 2 {
+		t.Errorf("Expected total rows from 2 chunks, got %d", deleted)
+	}
+
+	stmts := fake.Statements()
+	if len(stmts) != 4 || stmts[0] != "BEGIN" || stmts[3] != "COMMIT" {
+		t.Fatalf("Expected 2 deletes in one transaction, got %d statements", len(stmts))
+	}
+	if !strings.HasPrefix(stmts[1], "DELETE FROM [users] WHERE [id] IN (@p1, ") || !strings.HasSuffix(stmts[1], "@p2100)") {
+		t.Errorf("Unexpected first chunk: %.80s...", stmts[1])
+	}
+	if !strings.HasSuffix(stmts[2], "@p400)") || strings.Contains(stmts[2], "@p401") {
+		t.Errorf("Expected second chunk to restart numbering with 400 ids: ...%s", stmts[2][len(stmts[2])-40:])
+	}
+}
+
+// TestDeleteByIDsSoftDelete 测试含 deleted_at 的表转换为 UPDATE
+func TestDeleteByIDsSoftDelete(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	schema := NewBaseSchema("posts")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("deleted_at", TypeTime).Null(true).Build())
+
+	if _, err := repo.DeleteByIDs(context.Background(), schema, []interface{}{3, 5}); err != nil {
+		t.Fatalf("DeleteByIDs failed: %v", err)
+	}
+	stmts := fake.Statements()
+	want := `UPDATE "posts" SET "deleted_at" = NOW() WHERE ("id" IN ($1, $2) AND "deleted_at" IS NULL)`
+	if len(stmts) != 3 || stmts[1] != want {
+		t.Errorf("Expected soft delete %s, got %v", want, stmts)
+	}
+
+	if n, err := repo.DeleteByIDs(context.Background(), schema, nil); err != nil || n != 0 {
+		t.Errorf("Expected no-op for empty ids, got %d (%v)", n, err)
+	}
+}
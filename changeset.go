@@ -2,8 +2,10 @@ package db
 
 import (
 	"fmt"
-	"regexp"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Changeset 代表对数据的变更（参考 Ecto.Changeset）
@@ -25,6 +27,9 @@ type Changeset struct {
 	
 	// 变更前的值（用于追踪）
 	previousValues map[string]interface{}
+
+	// Cast 传入的原始参数，包括 schema 未定义的键（如 password_confirmation）
+	params map[string]interface{}
 	
 	// 锁
 	mu sync.RWMutex
@@ -39,6 +44,7 @@ func NewChangeset(schema Schema) *Changeset {
 		schema:          schema,
 		valid:           true,
 		previousValues:  make(map[string]interface{}),
+		params:          make(map[string]interface{}),
 	}
 }
 
@@ -57,39 +63,103 @@ func (cs *Changeset) Cast(data map[string]interface{}) *Changeset {
 	defer cs.mu.Unlock()
 
 	for key, value := range data {
+		cs.params[key] = value
 		field := cs.schema.GetField(key)
 		if field == nil {
 			continue // 忽略未定义的字段
 		}
+		cs.castField(key, field, value)
+	}
+
+	return cs
+}
 
-		// 保存原始值
-		if oldValue, exists := cs.data[key]; exists {
-			cs.previousValues[key] = oldValue
+// CastAs 同 Cast，但 overrides 中列出的字段按覆盖的类型执行 ConvertValue，而不是 schema 声明的类型
+// 只影响本次转换，schema 不变；转换器仍按字段定义执行
+func (cs *Changeset) CastAs(data map[string]interface{}, overrides map[string]FieldType) *Changeset {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	for key, value := range data {
+		cs.params[key] = value
+		field := cs.schema.GetField(key)
+		if field == nil {
+			continue // 忽略未定义的字段
+		}
+		fieldType, ok := overrides[key]
+		if !ok {
+			fieldType = field.Type
 		}
+		cs.castFieldAs(key, field, fieldType, value)
+	}
 
-		// 应用转换器
-		transformedValue := value
-		for _, transformer := range field.Transformers {
-			transformed, err := transformer.Transform(transformedValue)
-			if err != nil {
-				cs.addError(key, fmt.Sprintf("转换器错误: %v", err))
-				continue
-			}
-			transformedValue = transformed
+	return cs
+}
+
+// CastBoolean 按宽松规则转换布尔字段
+// 表单复选框未勾选时不会提交该字段，因此缺失、nil 或空字符串的字段写入 false；
+// 其余值按 valueToBoolean 解析（"on"、"yes"、"1"、"t" 等）
+func (cs *Changeset) CastBoolean(data map[string]interface{}, fields ...string) *Changeset {
+	return cs.CastBooleanDefault(data, false, fields...)
+}
+
+// CastBooleanDefault 同 CastBoolean，缺失或空值时写入 absent
+func (cs *Changeset) CastBooleanDefault(data map[string]interface{}, absent bool, fields ...string) *Changeset {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	for _, key := range fields {
+		field := cs.schema.GetField(key)
+		if field == nil {
+			continue // 忽略未定义的字段
 		}
+		if field.Type != TypeBoolean {
+			cs.addError(key, fmt.Sprintf("字段类型为 %s，不是布尔字段", field.Type))
+			continue
+		}
+
+		value, ok := data[key]
+		if str, isStr := value.(string); !ok || value == nil || (isStr && strings.TrimSpace(str) == "") {
+			value = absent
+		}
+		cs.castField(key, field, value)
+	}
 
-		// 类型转换
-		convertedValue, err := ConvertValue(transformedValue, field.Type)
+	return cs
+}
+
+// castField 对单个字段应用转换器和类型转换后写入 changes，调用方需持有锁
+func (cs *Changeset) castField(key string, field *Field, value interface{}) {
+	cs.castFieldAs(key, field, field.Type, value)
+}
+
+// castFieldAs 同 castField，类型转换使用 fieldType
+func (cs *Changeset) castFieldAs(key string, field *Field, fieldType FieldType, value interface{}) {
+	// 保存原始值
+	if oldValue, exists := cs.data[key]; exists {
+		cs.previousValues[key] = oldValue
+	}
+
+	// 应用转换器
+	transformedValue := value
+	for _, transformer := range field.Transformers {
+		transformed, err := transformer.Transform(transformedValue)
 		if err != nil {
-			cs.addError(key, fmt.Sprintf("类型转换失败: %v", err))
+			cs.addError(key, fmt.Sprintf("转换器错误: %v", err))
 			continue
 		}
+		transformedValue = transformed
+	}
 
-		cs.changes[key] = convertedValue
-		cs.data[key] = convertedValue
+	// 类型转换
+	convertedValue, err := ConvertValue(transformedValue, fieldType)
+	if err != nil {
+		cs.addError(key, fmt.Sprintf("类型转换失败: %v", err))
+		return
 	}
 
-	return cs
+	cs.changes[key] = convertedValue
+	cs.data[key] = convertedValue
 }
 
 // Validate 验证 Changeset
@@ -97,6 +167,57 @@ func (cs *Changeset) Validate() *Changeset {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
+	cs.validate()
+	return cs
+}
+
+// TransformAndValidate 先对所有已有值应用字段的转换器，再执行 Validate
+// 通过 FromMap 或 PutChange 进入的数据不会经过 Cast 的转换器，使用此方法可以保证验证的是转换后的值。
+// 经过 Cast 的值会再次转换，因此转换器应当是幂等的（例如去空白、转小写）
+func (cs *Changeset) TransformAndValidate() *Changeset {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.errors = make(map[string][]string)
+	for _, field := range cs.schema.Fields() {
+		value, exists := cs.data[field.Name]
+		if !exists || value == nil || len(field.Transformers) == 0 {
+			continue
+		}
+
+		transformed := value
+		failed := false
+		for _, transformer := range field.Transformers {
+			next, err := transformer.Transform(transformed)
+			if err != nil {
+				cs.addError(field.Name, fmt.Sprintf("转换器错误: %v", err))
+				failed = true
+				break
+			}
+			transformed = next
+		}
+		if failed || reflect.DeepEqual(transformed, value) {
+			continue
+		}
+
+		if _, changed := cs.changes[field.Name]; !changed {
+			cs.previousValues[field.Name] = value
+		}
+		cs.changes[field.Name] = transformed
+		cs.data[field.Name] = transformed
+	}
+
+	transformErrors := cs.errors
+	cs.validate()
+	for name, messages := range transformErrors {
+		cs.errors[name] = append(messages, cs.errors[name]...)
+		cs.valid = false
+	}
+	return cs
+}
+
+// validate 执行必填检查和字段验证器，调用方需持有锁
+func (cs *Changeset) validate() {
 	cs.errors = make(map[string][]string) // 清空之前的错误
 
 	for _, field := range cs.schema.Fields() {
@@ -111,16 +232,12 @@ func (cs *Changeset) Validate() *Changeset {
 
 		// 应用验证器
 		if exists && value != nil {
-			for _, validator := range field.Validators {
-				if err := validator.Validate(value); err != nil {
-					cs.addError(field.Name, err.Error())
-					cs.valid = false
-				}
+			for _, err := range field.RunValidators(value) {
+				cs.addError(field.Name, err.Error())
+				cs.valid = false
 			}
 		}
 	}
-
-	return cs
 }
 
 // ValidateChange 验证特定字段的变更
@@ -141,6 +258,12 @@ func (cs *Changeset) ValidateChange(fieldName string, validator Validator) *Chan
 	return cs
 }
 
+// ValidateChangeFunc 用闭包验证特定字段的变更，只在字段出现在 changes 中时执行
+// 适合一次性的、依赖上下文的验证，无需单独定义 Validator 类型
+func (cs *Changeset) ValidateChangeFunc(fieldName string, fn func(value interface{}) error) *Changeset {
+	return cs.ValidateChange(fieldName, ValidatorFunc(fn))
+}
+
 // IsValid 检查 Changeset 是否有效
 func (cs *Changeset) IsValid() bool {
 	cs.mu.RLock()
@@ -245,6 +368,28 @@ func (cs *Changeset) PutChange(fieldName string, value interface{}) *Changeset {
 	return cs
 }
 
+// PutChangeIfAbsent 仅当字段既没有变更也不在原始数据中时设置变更
+// 适合在 Cast 用户输入之后补充默认值，不会覆盖已有的值
+func (cs *Changeset) PutChangeIfAbsent(fieldName string, value interface{}) *Changeset {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.schema.GetField(fieldName) == nil {
+		return cs
+	}
+	if _, changed := cs.changes[fieldName]; changed {
+		return cs
+	}
+	if _, exists := cs.data[fieldName]; exists {
+		return cs
+	}
+
+	cs.changes[fieldName] = value
+	cs.data[fieldName] = value
+
+	return cs
+}
+
 // ClearError 清除错误
 func (cs *Changeset) ClearError(fieldName string) *Changeset {
 	cs.mu.Lock()
@@ -303,6 +448,39 @@ func (cs *Changeset) ToMap() map[string]interface{} {
 	return cs.Changes()
 }
 
+// ToGormMap 把变更转换为可直接传给 gormDB.Table(...).Create(map) / Updates(map) 的 map
+// 只包含 schema 中声明的字段，跳过由数据库生成的自增字段；值会重新经过字段的转换器
+// （PutChange 写入的值不经过 Cast，转换器应当是幂等的）和序列化钩子。
+// 转换失败的字段保留原值，需要报错时先调用 TransformAndValidate
+func (cs *Changeset) ToGormMap() map[string]interface{} {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	result := make(map[string]interface{}, len(cs.changes))
+	for name, value := range cs.changes {
+		field := cs.schema.GetField(name)
+		if field == nil || field.Autoinc {
+			continue
+		}
+		if value != nil {
+			transformed := value
+			for _, transformer := range field.Transformers {
+				next, err := transformer.Transform(transformed)
+				if err != nil {
+					transformed = value
+					break
+				}
+				transformed = next
+			}
+			if stored, err := field.StoreValue(transformed); err == nil {
+				value = stored
+			}
+		}
+		result[name] = value
+	}
+	return result
+}
+
 // ValidateRequired 验证必填字段
 func (cs *Changeset) ValidateRequired(fields []string) *Changeset {
 	cs.mu.Lock()
@@ -366,8 +544,8 @@ func (cs *Changeset) ValidateFormat(fieldName string, pattern string, message ..
 		return cs
 	}
 
-	// 使用 regexp 验证
-	re, err := regexp.Compile(pattern)
+	// 使用缓存的 regexp 验证
+	re, err := CompileRegex(pattern)
 	if err != nil {
 		cs.addError(fieldName, fmt.Sprintf("invalid pattern: %v", err))
 		cs.valid = false
@@ -386,6 +564,55 @@ func (cs *Changeset) ValidateFormat(fieldName string, pattern string, message ..
 	return cs
 }
 
+// ValidateDate 验证字段是合法的日期/时间
+// 字符串按 layouts 依次解析（未指定时使用 DefaultTimeLayouts），解析成功后替换为 time.Time
+func (cs *Changeset) ValidateDate(fieldName string, layouts ...string) *Changeset {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	value, exists := cs.data[fieldName]
+	if !exists || value == nil {
+		return cs
+	}
+	if _, ok := value.(time.Time); ok {
+		return cs
+	}
+
+	parsed, err := NewTimeScanner(layouts...).Parse(value)
+	if err != nil {
+		cs.addError(fieldName, fmt.Sprintf("%s is not a valid date", fieldName))
+		cs.valid = false
+		return cs
+	}
+	cs.data[fieldName] = parsed
+	if _, changed := cs.changes[fieldName]; changed {
+		cs.changes[fieldName] = parsed
+	}
+	return cs
+}
+
+// ValidateConfirmation 验证 Cast 传入的 field 与 field_confirmation 一致（如密码、邮箱确认）
+// 不一致时在 field_confirmation 上添加错误；本次 Cast 没有 field 或确认值时不校验。
+// 确认字段只用于校验，不会出现在 Changes 中
+func (cs *Changeset) ValidateConfirmation(fieldName string) *Changeset {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	confirmName := fieldName + "_confirmation"
+	delete(cs.changes, confirmName)
+
+	value, exists := cs.params[fieldName]
+	confirmation, confirmed := cs.params[confirmName]
+	if !exists || !confirmed {
+		return cs
+	}
+	if !valuesEqual(value, confirmation) {
+		cs.addError(confirmName, fmt.Sprintf("%s does not match confirmation", fieldName))
+		cs.valid = false
+	}
+	return cs
+}
+
 // ValidateInclusion 验证字段值在指定列表中
 func (cs *Changeset) ValidateInclusion(fieldName string, list []interface{}) *Changeset {
 	cs.mu.Lock()
@@ -534,3 +761,124 @@ func (cs *Changeset) ApplyAction(action Action) *Changeset {
 
 	return cs
 }
+
+// ==================== 快照与回滚 ====================
+
+// ChangesetSnapshot Changeset 某一时刻的深拷贝，用于 Restore 回滚
+type ChangesetSnapshot struct {
+	data           map[string]interface{}
+	changes        map[string]interface{}
+	errors         map[string][]string
+	previousValues map[string]interface{}
+	params         map[string]interface{}
+	valid          bool
+}
+
+// Snapshot 保存当前的数据、变更和错误
+// 快照是深拷贝，之后对 Changeset 或其中 map/slice 值的修改不会影响快照
+func (cs *Changeset) Snapshot() *ChangesetSnapshot {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return &ChangesetSnapshot{
+		data:           copyValueMap(cs.data),
+		changes:        copyValueMap(cs.changes),
+		errors:         copyErrorMap(cs.errors),
+		previousValues: copyValueMap(cs.previousValues),
+		params:         copyValueMap(cs.params),
+		valid:          cs.valid,
+	}
+}
+
+// Restore 把 Changeset 回滚到快照时的状态
+// 同一个快照可以多次 Restore
+func (cs *Changeset) Restore(snap *ChangesetSnapshot) *Changeset {
+	if snap == nil {
+		return cs
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.data = copyValueMap(snap.data)
+	cs.changes = copyValueMap(snap.changes)
+	cs.errors = copyErrorMap(snap.errors)
+	cs.previousValues = copyValueMap(snap.previousValues)
+	cs.params = copyValueMap(snap.params)
+	cs.valid = snap.valid
+	return cs
+}
+
+func copyValueMap(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		dst[k] = deepCopyValue(v)
+	}
+	return dst
+}
+
+func copyErrorMap(src map[string][]string) map[string][]string {
+	dst := make(map[string][]string, len(src))
+	for k, v := range src {
+		dst[k] = append([]string(nil), v...)
+	}
+	return dst
+}
+
+// deepCopyValue 递归复制 map、slice、数组和指针，其他值按值复制
+func deepCopyValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return deepCopyReflect(reflect.ValueOf(v)).Interface()
+}
+
+func deepCopyReflect(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		dst := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), deepCopyElem(iter.Value(), v.Type().Elem()))
+		}
+		return dst
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		dst := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(deepCopyElem(v.Index(i), v.Type().Elem()))
+		}
+		return dst
+	case reflect.Array:
+		dst := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(deepCopyElem(v.Index(i), v.Type().Elem()))
+		}
+		return dst
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		dst := reflect.New(v.Type().Elem())
+		dst.Elem().Set(deepCopyReflect(v.Elem()))
+		return dst
+	default:
+		return v
+	}
+}
+
+// deepCopyElem 复制容器元素；interface 元素按动态类型复制
+func deepCopyElem(v reflect.Value, elemType reflect.Type) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Zero(elemType)
+		}
+		return deepCopyReflect(v.Elem())
+	}
+	return deepCopyReflect(v)
+}
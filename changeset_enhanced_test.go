@@ -1,7 +1,11 @@
 package db
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestValidateRequired 测试必填字段验证
@@ -363,3 +367,361 @@ func TestCombinedValidations(t *testing.T) {
 		t.Errorf("Combined validations failed, errors: %v", cs.Errors())
 	}
 }
+
+// TestChangesetSnapshotRestore 测试快照回滚后数据、变更和错误与快照完全一致
+func TestChangesetSnapshotRestore(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(&Field{Name: "name", Type: TypeString})
+	schema.AddField(&Field{Name: "tags", Type: TypeArray})
+	schema.AddField(&Field{Name: "meta", Type: TypeJSON})
+
+	cs := FromMap(schema, map[string]interface{}{"name": "Alice"})
+	cs.PutChange("tags", []interface{}{"a", "b"})
+	cs.PutChange("meta", map[string]interface{}{"plan": "free", "limits": []interface{}{1, 2}})
+
+	snap := cs.Snapshot()
+
+	// 投机性修改：替换值、原地修改嵌套值、产生错误
+	cs.PutChange("name", "Bob")
+	cs.Get("tags").([]interface{})[0] = "mutated"
+	meta := cs.Get("meta").(map[string]interface{})
+	meta["plan"] = "pro"
+	meta["limits"].([]interface{})[1] = 99
+	cs.ValidateLength("name", 10, 0)
+	if cs.IsValid() {
+		t.Fatal("Expected changeset to be invalid after speculative changes")
+	}
+
+	cs.Restore(snap)
+
+	if !reflect.DeepEqual(cs.Data(), map[string]interface{}{
+		"name": "Alice",
+		"tags": []interface{}{"a", "b"},
+		"meta": map[string]interface{}{"plan": "free", "limits": []interface{}{1, 2}},
+	}) {
+		t.Errorf("Data not restored: %v", cs.Data())
+	}
+	if !reflect.DeepEqual(cs.Changes(), map[string]interface{}{
+		"tags": []interface{}{"a", "b"},
+		"meta": map[string]interface{}{"plan": "free", "limits": []interface{}{1, 2}},
+	}) {
+		t.Errorf("Changes not restored: %v", cs.Changes())
+	}
+	if len(cs.Errors()) != 0 || !cs.IsValid() {
+		t.Errorf("Expected errors to be cleared, got %v", cs.Errors())
+	}
+	if cs.HasChanged("name") {
+		t.Error("Expected name change to be rolled back")
+	}
+
+	// 修改恢复后的数据不会影响快照，快照可以重复使用
+	cs.Get("tags").([]interface{})[1] = "again"
+	cs.Restore(snap)
+	if !reflect.DeepEqual(cs.Get("tags"), []interface{}{"a", "b"}) {
+		t.Errorf("Expected snapshot to be reusable, got %v", cs.Get("tags"))
+	}
+}
+
+// TestCastBoolean 测试表单布尔值的宽松解析和缺失字段的默认值
+func TestCastBoolean(t *testing.T) {
+	schema := NewBaseSchema("settings")
+	schema.AddField(&Field{Name: "subscribed", Type: TypeBoolean})
+	schema.AddField(&Field{Name: "name", Type: TypeString})
+
+	values := map[string]bool{
+		"on": true, "true": true, "TRUE": true, "1": true, "yes": true, "y": true, "t": true, " On ": true,
+		"off": false, "false": false, "0": false, "no": false, "n": false, "f": false, "": false,
+	}
+	for input, want := range values {
+		cs := NewChangeset(schema).CastBoolean(map[string]interface{}{"subscribed": input}, "subscribed")
+		if !cs.IsValid() {
+			t.Errorf("%q: unexpected errors %v", input, cs.Errors())
+		}
+		if got := cs.Get("subscribed"); got != want {
+			t.Errorf("%q: expected %v, got %v", input, want, got)
+		}
+	}
+
+	// 未勾选的复选框不会提交
+	cs := NewChangeset(schema).CastBoolean(map[string]interface{}{}, "subscribed")
+	if got, ok := cs.GetChanged("subscribed"); !ok || got != false {
+		t.Errorf("Expected absent field to default to false, got %v (changed: %v)", got, ok)
+	}
+
+	cs = NewChangeset(schema).CastBooleanDefault(map[string]interface{}{"subscribed": nil}, true, "subscribed")
+	if got := cs.Get("subscribed"); got != true {
+		t.Errorf("Expected configured absent default true, got %v", got)
+	}
+
+	cs = NewChangeset(schema).CastBoolean(map[string]interface{}{"name": "on"}, "name")
+	if cs.IsValid() {
+		t.Error("Expected error when casting a non-boolean field")
+	}
+}
+
+// TestValidateChangeFunc 测试闭包验证只对已变更字段执行并记录错误
+func TestValidateChangeFunc(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(&Field{Name: "username", Type: TypeString})
+	schema.AddField(&Field{Name: "email", Type: TypeString})
+
+	reserved := map[string]bool{"admin": true, "root": true}
+	calls := 0
+	notReserved := func(value interface{}) error {
+		calls++
+		if reserved[value.(string)] {
+			return fmt.Errorf("用户名 %s 已被保留", value)
+		}
+		return nil
+	}
+
+	cs := NewChangeset(schema).Cast(map[string]interface{}{"username": "admin"})
+	cs.ValidateChangeFunc("username", notReserved)
+	if cs.IsValid() {
+		t.Error("Expected reserved username to be invalid")
+	}
+	if errs := cs.GetError("username"); len(errs) != 1 || errs[0] != "用户名 admin 已被保留" {
+		t.Errorf("Expected closure error to be recorded, got %v", errs)
+	}
+
+	cs = NewChangeset(schema).Cast(map[string]interface{}{"username": "alice"})
+	cs.ValidateChangeFunc("username", notReserved)
+	if !cs.IsValid() {
+		t.Errorf("Expected valid changeset, got %v", cs.Errors())
+	}
+
+	// 未变更的字段不执行闭包
+	calls = 0
+	cs.ValidateChangeFunc("email", notReserved)
+	if calls != 0 {
+		t.Errorf("Expected closure not to run for unchanged field, ran %d times", calls)
+	}
+}
+
+// TestCastTimeLayouts 测试时间字段按常见格式转换，以及 ValidateDate 的自定义格式
+func TestCastTimeLayouts(t *testing.T) {
+	schema := NewBaseSchema("events")
+	schema.AddField(&Field{Name: "starts_at", Type: TypeTime})
+	schema.AddField(&Field{Name: "day", Type: TypeString})
+
+	tests := map[interface{}]time.Time{
+		"2024-01-15":           time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		"2024-01-15T10:00:00Z": time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		"2024-01-15 10:00:00":  time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		int64(1705312800):      time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+	for input, want := range tests {
+		cs := NewChangeset(schema).Cast(map[string]interface{}{"starts_at": input})
+		if !cs.IsValid() {
+			t.Errorf("%v: unexpected errors %v", input, cs.Errors())
+			continue
+		}
+		got, ok := cs.Get("starts_at").(time.Time)
+		if !ok || !got.Equal(want) {
+			t.Errorf("%v: expected %v, got %v", input, want, cs.Get("starts_at"))
+		}
+	}
+
+	cs := NewChangeset(schema).Cast(map[string]interface{}{"day": "15/01/2024"}).ValidateDate("day", "02/01/2006")
+	if !cs.IsValid() {
+		t.Fatalf("Unexpected errors: %v", cs.Errors())
+	}
+	if got, ok := cs.Get("day").(time.Time); !ok || !got.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected ValidateDate to store parsed time, got %v", cs.Get("day"))
+	}
+
+	cs = NewChangeset(schema).Cast(map[string]interface{}{"day": "not a date"}).ValidateDate("day")
+	if cs.IsValid() || len(cs.GetError("day")) != 1 {
+		t.Errorf("Expected invalid date error, got %v", cs.Errors())
+	}
+}
+
+// TestPutChangeIfAbsent 测试默认值不会覆盖已转换或已存在的值
+func TestPutChangeIfAbsent(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(&Field{Name: "name", Type: TypeString})
+	schema.AddField(&Field{Name: "role", Type: TypeString})
+	schema.AddField(&Field{Name: "status", Type: TypeString})
+
+	cs := FromMap(schema, map[string]interface{}{"status": "active"})
+	cs.Cast(map[string]interface{}{"name": "alice"})
+
+	cs.PutChangeIfAbsent("name", "anonymous").
+		PutChangeIfAbsent("status", "pending").
+		PutChangeIfAbsent("role", "member")
+
+	if got := cs.Get("name"); got != "alice" {
+		t.Errorf("Expected cast name to be kept, got %v", got)
+	}
+	if got := cs.Get("status"); got != "active" {
+		t.Errorf("Expected existing status to be kept, got %v", got)
+	}
+	if cs.HasChanged("status") {
+		t.Error("Expected status not to be marked as changed")
+	}
+	if got, ok := cs.GetChanged("role"); !ok || got != "member" {
+		t.Errorf("Expected absent role to be filled, got %v (%v)", got, ok)
+	}
+}
+
+// TestTransformAndValidate 测试 FromMap 构建的 Changeset 在验证前先应用字段转换器
+func TestTransformAndValidate(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("email", TypeString).
+		Transform(&TrimTransformer{}).
+		Transform(&LowercaseTransformer{}).
+		Validate(ValidatorFunc(func(value interface{}) error {
+			if s := value.(string); s != strings.ToLower(strings.TrimSpace(s)) {
+				return fmt.Errorf("email must be normalized, got %q", s)
+			}
+			return nil
+		})).
+		Build())
+
+	raw := map[string]interface{}{"email": "  Alice@Example.COM "}
+	if cs := FromMap(schema, raw).Validate(); cs.IsValid() {
+		t.Fatal("Expected plain Validate to see the untransformed value")
+	}
+
+	cs := FromMap(schema, raw).TransformAndValidate()
+	if !cs.IsValid() {
+		t.Fatalf("Expected transformed changeset to be valid, got %v", cs.Errors())
+	}
+	if got := cs.Get("email"); got != "alice@example.com" {
+		t.Errorf("Expected trimmed and lowercased email, got %q", got)
+	}
+	if got, ok := cs.GetChanged("email"); !ok || got != "alice@example.com" {
+		t.Errorf("Expected transformed value to be recorded as a change, got %v (%v)", got, ok)
+	}
+}
+
+// TestFieldRunValidators 测试在 Changeset 之外一次性执行字段的所有验证器
+func TestFieldRunValidators(t *testing.T) {
+	field := NewField("username", TypeString).
+		Null(true).
+		Validate(&RequiredValidator{}).
+		Validate(&LengthValidator{Min: 3, Max: 10}).
+		Validate(&PatternValidator{Pattern: `^[a-z]+$`}).
+		Build()
+
+	codes := func(errs []error) []string {
+		result := make([]string, len(errs))
+		for i, err := range errs {
+			result[i] = err.(*ValidationError).Code
+		}
+		return result
+	}
+
+	if got := codes(field.RunValidators("A1")); !reflect.DeepEqual(got, []string{"length", "pattern"}) {
+		t.Errorf("Expected length and pattern errors, got %v", got)
+	}
+	if got := codes(field.RunValidators("")); !reflect.DeepEqual(got, []string{"required", "length", "pattern"}) {
+		t.Errorf("Expected required, length and pattern errors, got %v", got)
+	}
+	if errs := field.RunValidators("alice"); len(errs) != 0 {
+		t.Errorf("Expected no errors for a valid value, got %v", errs)
+	}
+	if errs := field.RunValidators(nil); len(errs) != 0 {
+		t.Errorf("Expected nil to skip validators on a nullable field, got %v", errs)
+	}
+
+	required := NewField("email", TypeString).Validate(&EmailValidator{}).Build()
+	if got := codes(required.RunValidators(nil)); !reflect.DeepEqual(got, []string{"required"}) {
+		t.Errorf("Expected only the required error for nil, got %v", got)
+	}
+}
+
+// TestCastAs 测试按覆盖类型转换字段，未覆盖的字段仍按 schema 类型转换
+func TestCastAs(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(&Field{Name: "flag", Type: TypeString})
+	schema.AddField(&Field{Name: "quantity", Type: TypeInteger})
+
+	data := map[string]interface{}{"flag": "yes", "quantity": 3.0}
+	cs := NewChangeset(schema).CastAs(data, map[string]FieldType{"flag": TypeBoolean})
+	if !cs.IsValid() {
+		t.Fatalf("Unexpected errors: %v", cs.Errors())
+	}
+	if got := cs.Get("flag"); got != true {
+		t.Errorf("Expected flag converted with override type to true, got %v (%T)", got, got)
+	}
+	if got := cs.Get("quantity"); got != int64(3) {
+		t.Errorf("Expected quantity converted with schema type to int64 3, got %v (%T)", got, got)
+	}
+	if field := schema.GetField("flag"); field.Type != TypeString {
+		t.Errorf("Expected schema type to be unchanged, got %s", field.Type)
+	}
+
+	cs = NewChangeset(schema).CastAs(map[string]interface{}{"flag": "not a date"}, map[string]FieldType{"flag": TypeTime})
+	if cs.IsValid() {
+		t.Error("Expected conversion error with override type")
+	}
+}
+
+// TestChangesetToGormMap 测试转换为 GORM map 时跳过自增字段并应用转换器和序列化钩子
+func TestChangesetToGormMap(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("email", TypeString).Transform(&TrimTransformer{}).Transform(&LowercaseTransformer{}).Build())
+	schema.AddField(NewField("tags", TypeJSON).Serializer(func(v interface{}) (interface{}, error) {
+		return strings.Join(v.([]string), ","), nil
+	}, nil).Build())
+
+	cs := NewChangeset(schema).
+		PutChange("id", 7).
+		PutChange("email", " Alice@Example.COM ").
+		PutChange("tags", []string{"a", "b"})
+
+	got := cs.ToGormMap()
+	if _, ok := got["id"]; ok {
+		t.Errorf("Expected auto-increment id to be excluded, got %v", got)
+	}
+	if got["email"] != "alice@example.com" {
+		t.Errorf("Expected transformed email, got %q", got["email"])
+	}
+	if got["tags"] != "a,b" {
+		t.Errorf("Expected serialized tags, got %v", got["tags"])
+	}
+	if len(got) != 2 {
+		t.Errorf("Expected 2 entries, got %v", got)
+	}
+}
+
+// TestValidateConfirmation 测试确认字段一致、不一致和缺失时的校验结果
+func TestValidateConfirmation(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("password", TypeString).Build())
+
+	cs := NewChangeset(schema).
+		Cast(map[string]interface{}{"password": "secret", "password_confirmation": "secret"}).
+		ValidateConfirmation("password")
+	if !cs.IsValid() {
+		t.Errorf("Expected matching confirmation to be valid, got %v", cs.Errors())
+	}
+	if _, ok := cs.Changes()["password_confirmation"]; ok || cs.Changes()["password"] != "secret" {
+		t.Errorf("Expected only password in changes, got %v", cs.Changes())
+	}
+
+	cs = NewChangeset(schema).
+		Cast(map[string]interface{}{"password": "secret", "password_confirmation": "secrte"}).
+		ValidateConfirmation("password")
+	if cs.IsValid() || len(cs.GetError("password_confirmation")) != 1 {
+		t.Errorf("Expected mismatch error on password_confirmation, got %v", cs.Errors())
+	}
+
+	cs = NewChangeset(schema).
+		Cast(map[string]interface{}{"password": "secret"}).
+		ValidateConfirmation("password")
+	if !cs.IsValid() {
+		t.Errorf("Expected missing confirmation to be skipped, got %v", cs.Errors())
+	}
+
+	// Restore 同样回滚 Cast 记录的原始参数
+	cs = NewChangeset(schema).Cast(map[string]interface{}{"password": "secret"})
+	snap := cs.Snapshot()
+	cs.Cast(map[string]interface{}{"password_confirmation": "stale"})
+	cs.Restore(snap).ValidateConfirmation("password")
+	if !cs.IsValid() {
+		t.Errorf("Expected restored changeset to drop the stale confirmation, got %v", cs.Errors())
+	}
+}
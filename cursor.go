@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Cursor 分批读取大结果集的游标
+// PostgreSQL 使用服务端游标（DECLARE ... CURSOR / FETCH FORWARD），
+// 其他数据库退化为逐行迭代同一个结果集
+type Cursor struct {
+	ctx       context.Context
+	tx        Tx
+	name      string // 已转义的游标名，仅服务端游标使用
+	rows      *sql.Rows
+	columns   []string
+	typeNames []string
+	closed    bool
+}
+
+// DeclareCursor 在事务中声明游标
+// 服务端游标只在事务内有效，因此 PostgreSQL 下 tx 必须非空；
+// 其他数据库 tx 为空时直接在 Repository 上执行查询
+func (r *Repository) DeclareCursor(ctx context.Context, tx Tx, name, query string, args ...interface{}) (*Cursor, error) {
+	dialect := r.sqlDialect()
+	if dialect != nil && dialect.SupportsServerCursors() {
+		if tx == nil {
+			return nil, fmt.Errorf("DeclareCursor: server-side cursor %s requires a transaction", name)
+		}
+		quoted := dialect.QuoteIdentifier(name)
+		declare := fmt.Sprintf("DECLARE %s CURSOR FOR %s", quoted, query)
+		if _, err := tx.Exec(ctx, declare, args...); err != nil {
+			return nil, fmt.Errorf("DeclareCursor: failed to declare cursor %s: %w", name, err)
+		}
+		return &Cursor{ctx: ctx, tx: tx, name: quoted}, nil
+	}
+
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, args...)
+	} else {
+		rows, err = r.Query(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("DeclareCursor: failed to open cursor %s: %w", name, err)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("DeclareCursor: failed to get columns: %w", err)
+	}
+	return &Cursor{ctx: ctx, tx: tx, rows: rows, columns: columns, typeNames: columnTypeNames(rows)}, nil
+}
+
+// Fetch 读取接下来的最多 n 行，读完后返回空切片
+func (c *Cursor) Fetch(n int) ([]map[string]interface{}, error) {
+	if c.closed {
+		return nil, fmt.Errorf("cursor is closed")
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("fetch size must be positive, got %d", n)
+	}
+
+	if c.rows != nil {
+		return c.fetchRows(n)
+	}
+
+	rows, err := c.tx.Query(c.ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", n, c.name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from cursor %s: %w", c.name, err)
+	}
+	defer rows.Close()
+	return ScanMaps(rows, nil)
+}
+
+// fetchRows 从客户端结果集中读取最多 n 行
+func (c *Cursor) fetchRows(n int) ([]map[string]interface{}, error) {
+	batch := make([]map[string]interface{}, 0, n)
+	for len(batch) < n && c.rows.Next() {
+		row, err := scanMapRow(c.rows, c.columns, c.typeNames, nil)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, row)
+	}
+	return batch, c.rows.Err()
+}
+
+// Close 关闭游标，重复调用是安全的
+func (c *Cursor) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	if c.rows != nil {
+		return c.rows.Close()
+	}
+	if _, err := c.tx.Exec(c.ctx, "CLOSE "+c.name); err != nil {
+		return fmt.Errorf("failed to close cursor %s: %w", c.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDeclareCursorPostgreSQL 测试 PostgreSQL 使用 DECLARE/FETCH 分批读取
+func TestDeclareCursorPostgreSQL(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+
+	batches := [][][]driver.Value{
+		{{int64(1), "a"}, {int64(2), "b"}},
+		{{int64(3), "c"}},
+		{},
+	}
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		if !strings.HasPrefix(query, "FETCH") {
+			return nil, nil
+		}
+		batch := batches[0]
+		batches = batches[1:]
+		return &fakeRows{columns: []string{"id", "name"}, values: batch}, nil
+	}
+
+	ctx := context.Background()
+	tx, err := repo.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	cursor, err := repo.DeclareCursor(ctx, tx, "export", "SELECT id, name FROM users WHERE status = $1", "active")
+	if err != nil {
+		t.Fatalf("DeclareCursor failed: %v", err)
+	}
+
+	first, err := cursor.Fetch(2)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	expected := []map[string]interface{}{{"id": int64(1), "name": "a"}, {"id": int64(2), "name": "b"}}
+	if !reflect.DeepEqual(first, expected) {
+		t.Errorf("Unexpected first batch: %v", first)
+	}
+	second, _ := cursor.Fetch(2)
+	if len(second) != 1 || second[0]["name"] != "c" {
+		t.Errorf("Unexpected second batch: %v", second)
+	}
+	if last, _ := cursor.Fetch(2); len(last) != 0 {
+		t.Errorf("Expected empty batch at end, got %v", last)
+	}
+
+	if err := cursor.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	want := []string{
+		"BEGIN",
+		`DECLARE "export" CURSOR FOR SELECT id, name FROM users WHERE status = $1`,
+		`FETCH FORWARD 2 FROM "export"`,
+		`FETCH FORWARD 2 FROM "export"`,
+		`FETCH FORWARD 2 FROM "export"`,
+		`CLOSE "export"`,
+		"COMMIT",
+	}
+	if got := fake.Statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected statements:\n got: %v\nwant: %v", got, want)
+	}
+
+	if _, err := repo.DeclareCursor(ctx, nil, "export", "SELECT 1"); err == nil {
+		t.Error("Expected error when declaring a server-side cursor outside a transaction")
+	}
+}
+
+// TestDeclareCursorFallback 测试非 PostgreSQL 退化为逐行迭代
+func TestDeclareCursorFallback(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{
+			columns: []string{"id"},
+			values:  [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}},
+		}, nil
+	}
+
+	ctx := context.Background()
+	cursor, err := repo.DeclareCursor(ctx, nil, "export", "SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("DeclareCursor failed: %v", err)
+	}
+	defer cursor.Close()
+
+	sizes := []int{}
+	for {
+		batch, err := cursor.Fetch(2)
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		sizes = append(sizes, len(batch))
+	}
+	if !reflect.DeepEqual(sizes, []int{2, 1}) {
+		t.Errorf("Unexpected batch sizes: %v", sizes)
+	}
+	for _, stmt := range fake.Statements() {
+		if strings.Contains(stmt, "DECLARE") || strings.Contains(stmt, "FETCH") {
+			t.Errorf("Unexpected cursor statement for MySQL: %s", stmt)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DataMigration 数据迁移（回填、数据转换等）
+// 通过 Batch 按主键分页处理大表，每批单独提交事务，避免长时间锁表
+type DataMigration struct {
+	*BaseMigration
+	table      string
+	primaryKey string
+	upFn       func(ctx context.Context, repo *Repository, m *DataMigration) error
+	downFn     func(ctx context.Context, repo *Repository, m *DataMigration) error
+}
+
+// NewDataMigration 创建数据迁移，table/primaryKey 为 Batch 分页的表和主键列
+func NewDataMigration(version, description, table, primaryKey string) *DataMigration {
+	return &DataMigration{
+		BaseMigration: NewBaseMigration(version, description),
+		table:         table,
+		primaryKey:    primaryKey,
+	}
+}
+
+// OnUp 设置 Up 逻辑
+func (m *DataMigration) OnUp(fn func(ctx context.Context, repo *Repository, m *DataMigration) error) *DataMigration {
+	m.upFn = fn
+	return m
+}
+
+// OnDown 设置 Down 逻辑
+func (m *DataMigration) OnDown(fn func(ctx context.Context, repo *Repository, m *DataMigration) error) *DataMigration {
+	m.downFn = fn
+	return m
+}
+
+// Up 执行迁移
+func (m *DataMigration) Up(ctx context.Context, repo *Repository) error {
+	if m.upFn == nil {
+		return nil
+	}
+	return m.upFn(ctx, repo, m)
+}
+
+// Down 回滚迁移
+func (m *DataMigration) Down(ctx context.Context, repo *Repository) error {
+	if m.downFn == nil {
+		return nil
+	}
+	return m.downFn(ctx, repo, m)
+}
+
+// Batch 按主键升序分页读取表中的行，每批调用一次 fn
+// fn 可以直接修改传入的行，返回后被修改的列会在一个事务中按主键写回；
+// fn 返回错误时该批不写回并停止，已提交的批次不会被撤销
+func (m *DataMigration) Batch(ctx context.Context, repo *Repository, batchSize int, fn func(rows []map[string]interface{}) error) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	dialect := repo.sqlDialect()
+	if dialect == nil {
+		dialect = NewMySQLDialect()
+	}
+
+	var lastKey interface{}
+	for batchNum := 1; ; batchNum++ {
+		rows, err := m.fetchBatch(ctx, repo, dialect, lastKey, batchSize)
+		if err != nil {
+			return fmt.Errorf("data migration %s: batch %d: %w", m.Version(), batchNum, err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		originals := make([]map[string]interface{}, len(rows))
+		for i, row := range rows {
+			originals[i] = copyValueMap(row)
+		}
+
+		if err := fn(rows); err != nil {
+			return fmt.Errorf("data migration %s: batch %d: %w", m.Version(), batchNum, err)
+		}
+		if err := m.writeBatch(ctx, repo, dialect, originals, rows); err != nil {
+			return fmt.Errorf("data migration %s: batch %d: %w", m.Version(), batchNum, err)
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+		lastKey = originals[len(originals)-1][m.primaryKey]
+	}
+}
+
+// fetchBatch 读取主键大于 lastKey 的下一批行
+func (m *DataMigration) fetchBatch(ctx context.Context, repo *Repository, dialect SQLDialect, lastKey interface{}, limit int) ([]map[string]interface{}, error) {
+	pk := dialect.QuoteIdentifier(m.primaryKey)
+
+	var query strings.Builder
+	var args []interface{}
+	query.WriteString("SELECT * FROM " + dialect.QuoteIdentifier(m.table))
+	if lastKey != nil {
+		query.WriteString(" WHERE " + pk + " > " + dialect.GetPlaceholder(1))
+		args = append(args, lastKey)
+	}
+	query.WriteString(" ORDER BY " + pk + " ASC ")
+	query.WriteString(dialect.GenerateLimitOffset(&limit, nil))
+
+	rows, err := repo.Query(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return ScanMaps(rows, nil)
+}
+
+// writeBatch 在一个事务中写回每行被修改的列
+func (m *DataMigration) writeBatch(ctx context.Context, repo *Repository, dialect SQLDialect, originals, rows []map[string]interface{}) error {
+	type update struct {
+		query string
+		args  []interface{}
+	}
+	updates := make([]update, 0)
+
+	for i, row := range rows {
+		changed := make([]string, 0)
+		for col, value := range row {
+			if col == m.primaryKey {
+				continue
+			}
+			if old, ok := originals[i][col]; !ok || !reflect.DeepEqual(old, value) {
+				changed = append(changed, col)
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		sort.Strings(changed)
+
+		sets := make([]string, len(changed))
+		args := make([]interface{}, 0, len(changed)+1)
+		for j, col := range changed {
+			sets[j] = dialect.QuoteIdentifier(col) + " = " + dialect.GetPlaceholder(j+1)
+			args = append(args, row[col])
+		}
+		args = append(args, originals[i][m.primaryKey])
+
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s",
+			dialect.QuoteIdentifier(m.table), strings.Join(sets, ", "),
+			dialect.QuoteIdentifier(m.primaryKey), dialect.GetPlaceholder(len(changed)+1))
+		updates = append(updates, update{query: query, args: args})
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	tx, err := repo.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range updates {
+		if _, err := tx.Exec(ctx, u.query, u.args...); err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
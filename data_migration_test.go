@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// scriptUsersTable 让伪驱动按 "id > ?" 和 LIMIT 返回 users 表的分页结果
+func scriptUsersTable(fake *fakeDB, total int) {
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		var after int64
+		if len(args) > 0 {
+			after = args[0].(int64)
+		}
+		limit := 2
+		rows := &fakeRows{columns: []string{"id", "email"}}
+		for id := after + 1; id <= int64(total) && len(rows.values) < limit; id++ {
+			rows.values = append(rows.values, []driver.Value{id, fmt.Sprintf("USER%d@EXAMPLE.COM", id)})
+		}
+		return rows, nil
+	}
+}
+
+// TestDataMigrationBatch 测试按主键分页处理全部行，每批调用一次转换并单独提交
+func TestDataMigrationBatch(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+	scriptUsersTable(fake, 5)
+
+	var batchSizes []int
+	migration := NewDataMigration("20260101", "lowercase emails", "users", "id").
+		OnUp(func(ctx context.Context, repo *Repository, m *DataMigration) error {
+			return m.Batch(ctx, repo, 2, func(rows []map[string]interface{}) error {
+				batchSizes = append(batchSizes, len(rows))
+				for _, row := range rows {
+					row["email"] = strings.ToLower(row["email"].(string))
+				}
+				return nil
+			})
+		})
+
+	if err := migration.Up(context.Background(), repo); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(batchSizes, []int{2, 2, 1}) {
+		t.Errorf("Expected batches [2 2 1], got %v", batchSizes)
+	}
+
+	var selects, updates, commits int
+	for _, stmt := range fake.Statements() {
+		switch {
+		case strings.HasPrefix(stmt, "SELECT"):
+			selects++
+		case strings.HasPrefix(stmt, "UPDATE"):
+			updates++
+			if stmt != `UPDATE "users" SET "email" = $1 WHERE "id" = $2` {
+				t.Errorf("Unexpected update: %s", stmt)
+			}
+		case stmt == "COMMIT":
+			commits++
+		}
+	}
+	if selects != 3 || updates != 5 || commits != 3 {
+		t.Errorf("Expected 3 selects, 5 updates, 3 commits; got %d, %d, %d\n%v", selects, updates, commits, fake.Statements())
+	}
+
+	stmts := fake.Statements()
+	if stmts[0] != `SELECT * FROM "users" ORDER BY "id" ASC LIMIT 2` {
+		t.Errorf("Unexpected first page query: %s", stmts[0])
+	}
+	if !containsStatement(stmts, `SELECT * FROM "users" WHERE "id" > $1 ORDER BY "id" ASC LIMIT 2`) {
+		t.Errorf("Expected keyset page query, got %v", stmts)
+	}
+	if got := fake.LastArgs(); len(got) != 0 {
+		t.Errorf("Expected COMMIT to be last, got args %v", got)
+	}
+}
+
+// TestDataMigrationBatchStopsOnError 测试转换出错时停止且不写回当前批
+func TestDataMigrationBatchStopsOnError(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+	scriptUsersTable(fake, 5)
+
+	failure := errors.New("bad row")
+	calls := 0
+	migration := NewDataMigration("20260102", "backfill", "users", "id")
+	err := migration.Batch(context.Background(), repo, 2, func(rows []map[string]interface{}) error {
+		calls++
+		if calls == 2 {
+			rows[0]["email"] = "changed"
+			return failure
+		}
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected transform error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected processing to stop after failing batch, got %d calls", calls)
+	}
+	for _, stmt := range fake.Statements() {
+		if strings.HasPrefix(stmt, "UPDATE") || stmt == "BEGIN" {
+			t.Errorf("Expected no writes for unchanged or failed batches, got %s", stmt)
+		}
+	}
+}
+
+// TestDataMigrationWithTransactionalRunner 测试启用迁移事务时数据迁移仍能逐批提交
+func TestDataMigrationWithTransactionalRunner(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "tx_data_migration.db")
+	if _, err := repo.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO users (email) VALUES ('ALICE@EXAMPLE.COM'), ('BOB@EXAMPLE.COM')"); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewMigrationRunner(repo).UseTransactions(true)
+	runner.Register(NewDataMigration("001", "lowercase emails", "users", "id").
+		OnUp(func(ctx context.Context, repo *Repository, m *DataMigration) error {
+			return m.Batch(ctx, repo, 1, func(rows []map[string]interface{}) error {
+				for _, row := range rows {
+					row["email"] = strings.ToLower(row["email"].(string))
+				}
+				return nil
+			})
+		}))
+	if err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	var count int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM users WHERE email = lower(email)").Scan(&count); err != nil || count != 2 {
+		t.Errorf("Expected 2 lowercased rows, got %d (%v)", count, err)
+	}
+}
@@ -0,0 +1,504 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ==================== DDL 构造器 ====================
+
+// DDLBuilder 根据 Schema 和方言生成 DDL 语句
+// 迁移和动态表钩子都通过它生成建表语句，类型映射和标识符转义集中在这里。
+// dialect 为 nil 时生成不转义标识符、所有列均为 TEXT 的通用 DDL
+type DDLBuilder struct {
+	dialect SQLDialect
+
+	// 数据库服务端版本（如 "5.7.44"），为空时按各方言的当前版本生成
+	serverVersion string
+
+	// PostgreSQL 的 TypeArray 是否映射为原生数组 TEXT[]，默认与早期迁移保持一致映射为 TEXT
+	nativeArrays bool
+}
+
+// NewDDLBuilder 创建 DDL 构造器
+func NewDDLBuilder(dialect SQLDialect) *DDLBuilder {
+	return &DDLBuilder{dialect: dialect}
+}
+
+// newRepositoryDDLBuilder 使用仓储适配器方言的 DDL 构造器
+func newRepositoryDDLBuilder(repo *Repository) *DDLBuilder {
+	return NewDDLBuilder(repo.sqlDialect())
+}
+
+// ServerVersion 设置数据库服务端版本，部分语句（如 MySQL 8.0 之前的重命名列）按版本选择语法
+func (b *DDLBuilder) ServerVersion(version string) *DDLBuilder {
+	b.serverVersion = version
+	return b
+}
+
+// NativeArrays PostgreSQL 的 TypeArray 字段生成原生数组列 TEXT[]
+// 默认映射为 TEXT，与此前生成的迁移保持一致；动态表钩子一直使用 TEXT[]，会开启该选项
+func (b *DDLBuilder) NativeArrays(enabled bool) *DDLBuilder {
+	b.nativeArrays = enabled
+	return b
+}
+
+// legacyMySQL 是否为不支持 RENAME COLUMN 的 MySQL（8.0 之前）或 MariaDB（10.5 之前）
+func (b *DDLBuilder) legacyMySQL() bool {
+	if b.dialectName() != "mysql" || b.serverVersion == "" {
+		return false
+	}
+	parts := strings.SplitN(b.serverVersion, ".", 3)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	if strings.Contains(strings.ToLower(b.serverVersion), "mariadb") {
+		minor := 0
+		if len(parts) > 1 {
+			minor, _ = strconv.Atoi(parts[1])
+		}
+		return major < 10 || (major == 10 && minor < 5)
+	}
+	return major < 8
+}
+
+// detectServerVersion 查询 MySQL 服务端版本，其他方言或查询失败时返回空字符串（按当前版本生成）
+func detectServerVersion(ctx context.Context, repo *Repository) string {
+	dialect := repo.sqlDialect()
+	if dialect == nil || dialect.Name() != "mysql" {
+		return ""
+	}
+	var version string
+	if err := repo.QueryRow(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return ""
+	}
+	return version
+}
+
+// dialectName 返回方言名称，通用 DDL 返回空字符串
+func (b *DDLBuilder) dialectName() string {
+	if b.dialect == nil {
+		return ""
+	}
+	return b.dialect.Name()
+}
+
+// quote 转义标识符
+func (b *DDLBuilder) quote(name string) string {
+	if b.dialect == nil {
+		return name
+	}
+	return b.dialect.QuoteIdentifier(name)
+}
+
+func (b *DDLBuilder) quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = b.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// CreateTable 生成建表语句（表已存在时不报错）
+// 多个主键字段生成表级 PRIMARY KEY，AddUniqueIndex 声明的索引生成表级 UNIQUE 约束
+func (b *DDLBuilder) CreateTable(schema Schema) string {
+	table := schema.TableName()
+	columnsSQL := strings.Join(b.ColumnDefinitions(schema), ", ")
+
+	if b.dialectName() == "sqlserver" {
+		return fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NULL CREATE TABLE %s (%s)", table, b.quote(table), columnsSQL)
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", b.quote(table), columnsSQL)
+}
+
+// ColumnDefinitions 返回建表语句括号内的列定义和表级约束
+func (b *DDLBuilder) ColumnDefinitions(schema Schema) []string {
+	primary := make([]string, 0)
+	for _, field := range schema.Fields() {
+		if field.Primary {
+			primary = append(primary, field.Name)
+		}
+	}
+	inlinePK := len(primary) == 1
+
+	defs := make([]string, 0, len(schema.Fields())+1)
+	for _, field := range schema.Fields() {
+		defs = append(defs, b.columnDefinition(field, inlinePK))
+	}
+	if len(primary) > 1 {
+		defs = append(defs, "PRIMARY KEY ("+b.quoteList(primary)+")")
+	}
+	if unique, ok := schema.(UniqueIndexSchema); ok {
+		for _, columns := range unique.UniqueIndexes() {
+			if len(columns) == 1 {
+				if field := schema.GetField(columns[0]); field != nil && field.Unique {
+					continue
+				}
+			}
+			defs = append(defs, "UNIQUE ("+b.quoteList(columns)+")")
+		}
+	}
+	return defs
+}
+
+// ColumnDefinition 生成单列定义（用于 ADD COLUMN 或单主键表）
+func (b *DDLBuilder) ColumnDefinition(field *Field) string {
+	return b.columnDefinition(field, true)
+}
+
+func (b *DDLBuilder) columnDefinition(field *Field, inlinePK bool) string {
+	name := b.quote(field.Name)
+
+	if field.Primary && field.Autoinc && inlinePK {
+		switch b.dialectName() {
+		case "postgresql":
+			return name + " SERIAL PRIMARY KEY"
+		case "mysql":
+			return name + " INT AUTO_INCREMENT PRIMARY KEY"
+		case "sqlite":
+			return name + " INTEGER PRIMARY KEY AUTOINCREMENT"
+		case "sqlserver":
+			return name + " INT IDENTITY(1,1) PRIMARY KEY"
+		}
+	}
+
+	column := name + " " + b.ColumnType(field.Type)
+	if field.Collation != "" {
+		if validateCollation(field.Collation) == nil {
+			column += collateClause(b.dialect, field.Collation)
+		} else {
+			// 不合法的名称按标识符转义，交给数据库报错，避免拼接进 DDL（迁移会先校验）
+			column += " COLLATE " + b.quote(field.Collation)
+		}
+	}
+	if field.Primary && inlinePK {
+		column += " PRIMARY KEY"
+	}
+	if !field.Null {
+		column += " NOT NULL"
+	}
+	if field.Default != nil {
+		column += " DEFAULT " + b.formatDefault(field.Default)
+	}
+	if field.Unique && !field.Primary {
+		column += " UNIQUE"
+	}
+	return column
+}
+
+// formatDefault 渲染 DEFAULT 值：Now() 使用方言的当前时间函数，布尔值按方言转换
+func (b *DDLBuilder) formatDefault(value interface{}) string {
+	switch v := value.(type) {
+	case NowValue:
+		if b.dialect == nil {
+			return "CURRENT_TIMESTAMP"
+		}
+		return b.dialect.CurrentTimestamp()
+	case bool:
+		if b.dialectName() == "postgresql" {
+			if v {
+				return "TRUE"
+			}
+			return "FALSE"
+		}
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	if b.dialect == nil {
+		return (&DefaultSQLDialect{}).QuoteValue(value)
+	}
+	return b.dialect.QuoteValue(value)
+}
+
+// DropTable 生成删表语句（表不存在时不报错）
+func (b *DDLBuilder) DropTable(table string) string {
+	if b.dialectName() == "sqlserver" {
+		return fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NOT NULL DROP TABLE %s", table, b.quote(table))
+	}
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s", b.quote(table))
+}
+
+// AddColumn 生成添加列语句
+func (b *DDLBuilder) AddColumn(table string, field *Field) string {
+	keyword := "ADD COLUMN"
+	if b.dialectName() == "sqlserver" {
+		keyword = "ADD"
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s %s", b.quote(table), keyword, b.ColumnDefinition(field))
+}
+
+// AddColumnStatements 生成添加列的语句序列
+// 为已有数据的表添加没有默认值的 NOT NULL 列会失败，此时需要提供 backfill：
+// 先添加可空列，再用 backfill 回填已有行，最后设置 NOT NULL。
+// SQLite 不能修改列约束，改为以 backfill 作为默认值直接添加 NOT NULL 列
+func (b *DDLBuilder) AddColumnStatements(table string, field *Field, backfill interface{}) ([]string, error) {
+	if field.Null || field.Default != nil || field.Primary {
+		return []string{b.AddColumn(table, field)}, nil
+	}
+	if backfill == nil {
+		return nil, fmt.Errorf("adding NOT NULL column %s to %s requires a default or a backfill value", field.Name, table)
+	}
+
+	if b.dialectName() == "sqlite" {
+		withDefault := *field
+		withDefault.Default = backfill
+		return []string{b.AddColumn(table, &withDefault)}, nil
+	}
+
+	nullable := *field
+	nullable.Null = true
+	column := b.quote(field.Name)
+	stmts := []string{
+		b.AddColumn(table, &nullable),
+		fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL", b.quote(table), column, b.formatDefault(backfill), column),
+	}
+
+	columnType := b.ColumnType(field.Type)
+	switch b.dialectName() {
+	case "mysql":
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s NOT NULL", b.quote(table), column, columnType))
+	case "sqlserver":
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s NOT NULL", b.quote(table), column, columnType))
+	default:
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", b.quote(table), column))
+	}
+	return stmts, nil
+}
+
+// DropColumn 生成删除列语句（SQLite 3.35+ 支持）
+func (b *DDLBuilder) DropColumn(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", b.quote(table), b.quote(column))
+}
+
+// RenameColumn 生成重命名列语句
+// PostgreSQL、SQLite（3.25+）和 MySQL 8.0+ 使用 RENAME COLUMN，SQL Server 使用 sp_rename。
+// MySQL 8.0 之前只能通过 CHANGE COLUMN 连同列定义一起重命名，此时返回错误，需改用 RenameColumnWithDefinition
+func (b *DDLBuilder) RenameColumn(table, oldName, newName string) (string, error) {
+	if b.legacyMySQL() {
+		return "", fmt.Errorf("mysql %s cannot rename column %s.%s without its definition, use RenameColumnWithDefinition", b.serverVersion, table, oldName)
+	}
+	if b.dialectName() == "sqlserver" {
+		return fmt.Sprintf("EXEC sp_rename %s, %s, 'COLUMN'", stringLiteral(table+"."+oldName), stringLiteral(newName)), nil
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", b.quote(table), b.quote(oldName), b.quote(newName)), nil
+}
+
+// RenameColumnWithDefinition 把 oldName 列重命名为 field.Name
+// MySQL 8.0 之前生成 CHANGE COLUMN 并按 field 重新声明列定义，其他情况等同于 RenameColumn
+func (b *DDLBuilder) RenameColumnWithDefinition(table, oldName string, field *Field) (string, error) {
+	if b.legacyMySQL() {
+		return fmt.Sprintf("ALTER TABLE %s CHANGE COLUMN %s %s", b.quote(table), b.quote(oldName), b.ColumnDefinition(field)), nil
+	}
+	return b.RenameColumn(table, oldName, field.Name)
+}
+
+// stringLiteral 生成单引号字符串字面量，内部的单引号写成两个
+func stringLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// RenameTable 生成重命名表语句
+func (b *DDLBuilder) RenameTable(oldName, newName string) string {
+	switch b.dialectName() {
+	case "mysql":
+		return fmt.Sprintf("RENAME TABLE %s TO %s", b.quote(oldName), b.quote(newName))
+	case "sqlserver":
+		return fmt.Sprintf("EXEC sp_rename %s, %s", stringLiteral(oldName), stringLiteral(newName))
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", b.quote(oldName), b.quote(newName))
+}
+
+// CreateIndex 生成建索引语句，name 为空时使用 BuildIndexName 生成
+func (b *DDLBuilder) CreateIndex(table, name string, columns []string, unique bool) string {
+	if name == "" {
+		name = BuildIndexName(b.dialect, table, columns...)
+	}
+	keyword := "CREATE INDEX"
+	if unique {
+		keyword = "CREATE UNIQUE INDEX"
+	}
+	return fmt.Sprintf("%s %s ON %s (%s)", keyword, b.quote(name), b.quote(table), b.quoteList(columns))
+}
+
+// CreatePartialIndex 生成带 WHERE 谓词的部分索引，谓词经方言翻译并内联参数
+// 方言不支持部分索引（如 MySQL）时返回错误
+func (b *DDLBuilder) CreatePartialIndex(table string, index *IndexDefinition) (string, error) {
+	stmt := b.CreateIndex(table, index.Name, index.Columns, index.Unique)
+	if index.Predicate == nil {
+		return stmt, nil
+	}
+	if b.dialect == nil || !b.dialect.SupportsPartialIndexes() {
+		return "", fmt.Errorf("partial index %s is not supported by the %s dialect", index.Name, b.dialectName())
+	}
+	predicate, err := translateConditionInline(b.dialect, index.Predicate)
+	if err != nil {
+		return "", fmt.Errorf("partial index %s: %w", index.Name, err)
+	}
+	return stmt + " WHERE " + predicate, nil
+}
+
+// CreateIndexes 生成 schema 通过 AddPartialIndex 声明的索引
+func (b *DDLBuilder) CreateIndexes(schema Schema) ([]string, error) {
+	indexed, ok := schema.(interface{ Indexes() []*IndexDefinition })
+	if !ok {
+		return nil, nil
+	}
+	stmts := make([]string, 0, len(indexed.Indexes()))
+	for _, index := range indexed.Indexes() {
+		stmt, err := b.CreatePartialIndex(schema.TableName(), index)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+// AddForeignKey 生成添加外键约束语句，约束名为 fk_<table>_<column>
+// SQLite 不支持 ALTER TABLE ADD CONSTRAINT，外键只能在建表时声明
+func (b *DDLBuilder) AddForeignKey(table, refTable string, fk *ForeignKeyDef) (string, error) {
+	if fk == nil || fk.FromColumn == "" || fk.ToColumn == "" {
+		return "", fmt.Errorf("foreign key on %s requires both columns", table)
+	}
+	if b.dialectName() == "sqlite" {
+		return "", fmt.Errorf("sqlite does not support adding foreign keys to existing tables")
+	}
+
+	name := "fk_" + table + "_" + fk.FromColumn
+	if b.dialect != nil {
+		name = shortenIdentifier(name, b.dialect.MaxIdentifierLength())
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		b.quote(table), b.quote(name), b.quote(fk.FromColumn), b.quote(refTable), b.quote(fk.ToColumn))
+	if fk.OnDelete != "" {
+		stmt += " ON DELETE " + string(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		stmt += " ON UPDATE " + string(fk.OnUpdate)
+	}
+	return stmt, nil
+}
+
+// ColumnType 把字段类型映射为方言的列类型
+func (b *DDLBuilder) ColumnType(fieldType FieldType) string {
+	switch b.dialectName() {
+	case "postgresql":
+		return mapPostgresType(fieldType, b.nativeArrays)
+	case "mysql":
+		return mapMySQLType(fieldType)
+	case "sqlite":
+		return mapSQLiteType(fieldType)
+	case "sqlserver":
+		return mapSQLServerType(fieldType)
+	default:
+		return "TEXT"
+	}
+}
+
+func mapPostgresType(fieldType FieldType, nativeArrays bool) string {
+	switch fieldType {
+	case TypeString:
+		return "VARCHAR(255)"
+	case TypeInteger:
+		return "INTEGER"
+	case TypeFloat:
+		return "DOUBLE PRECISION"
+	case TypeBoolean:
+		return "BOOLEAN"
+	case TypeTime:
+		return "TIMESTAMP"
+	case TypeBinary:
+		return "BYTEA"
+	case TypeDecimal:
+		return "DECIMAL(18,2)"
+	case TypeJSON:
+		return "JSONB"
+	case TypeArray:
+		if nativeArrays {
+			return "TEXT[]"
+		}
+		return "TEXT"
+	default:
+		return "TEXT"
+	}
+}
+
+func mapMySQLType(fieldType FieldType) string {
+	switch fieldType {
+	case TypeString:
+		return "VARCHAR(255)"
+	case TypeInteger:
+		return "INT"
+	case TypeFloat:
+		return "FLOAT"
+	case TypeBoolean:
+		return "TINYINT(1)"
+	case TypeTime:
+		return "DATETIME"
+	case TypeBinary:
+		return "LONGBLOB"
+	case TypeDecimal:
+		return "DECIMAL(18,2)"
+	case TypeJSON:
+		return "JSON"
+	case TypeArray:
+		return "TEXT"
+	default:
+		return "TEXT"
+	}
+}
+
+func mapSQLiteType(fieldType FieldType) string {
+	switch fieldType {
+	case TypeString:
+		return "TEXT"
+	case TypeInteger:
+		return "INTEGER"
+	case TypeFloat:
+		return "REAL"
+	case TypeBoolean:
+		return "INTEGER" // SQLite 使用 0/1 表示布尔值
+	case TypeTime:
+		return "DATETIME"
+	case TypeBinary:
+		return "BLOB"
+	case TypeDecimal:
+		return "NUMERIC"
+	case TypeJSON:
+		return "TEXT"
+	case TypeArray:
+		return "TEXT"
+	default:
+		return "TEXT"
+	}
+}
+
+func mapSQLServerType(fieldType FieldType) string {
+	switch fieldType {
+	case TypeString:
+		return "NVARCHAR(255)"
+	case TypeInteger:
+		return "INT"
+	case TypeFloat:
+		return "FLOAT"
+	case TypeBoolean:
+		return "BIT"
+	case TypeTime:
+		return "DATETIME2"
+	case TypeBinary:
+		return "VARBINARY(MAX)"
+	case TypeDecimal:
+		return "DECIMAL(18,2)"
+	case TypeJSON:
+		return "NVARCHAR(MAX)"
+	case TypeArray:
+		return "NVARCHAR(MAX)"
+	default:
+		return "NVARCHAR(MAX)"
+	}
+}
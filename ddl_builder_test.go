@@ -0,0 +1,427 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ddlTestSchema() *BaseSchema {
+	schema := NewBaseSchema("posts")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("title", TypeString).Build())
+	schema.AddField(NewField("status", TypeString).Default("draft").Build())
+	schema.AddField(NewField("published", TypeBoolean).Default(false).Build())
+	schema.AddField(NewField("created_at", TypeTime).Default(Now()).Build())
+	schema.AddField(NewField("slug", TypeString).Unique().Build())
+	schema.AddField(NewField("body", TypeString).Null(true).Build())
+	return schema
+}
+
+// TestDDLBuilderCreateTable 测试各方言的建表语句
+func TestDDLBuilderCreateTable(t *testing.T) {
+	schema := ddlTestSchema()
+
+	tests := []struct {
+		dialect SQLDialect
+		want    string
+	}{
+		{
+			NewPostgreSQLDialect(),
+			`CREATE TABLE IF NOT EXISTS "posts" ("id" SERIAL PRIMARY KEY, "title" VARCHAR(255) NOT NULL, "status" VARCHAR(255) NOT NULL DEFAULT 'draft', "published" BOOLEAN NOT NULL DEFAULT FALSE, "created_at" TIMESTAMP NOT NULL DEFAULT NOW(), "slug" VARCHAR(255) NOT NULL UNIQUE, "body" VARCHAR(255))`,
+		},
+		{
+			NewMySQLDialect(),
+			"CREATE TABLE IF NOT EXISTS `posts` (`id` INT AUTO_INCREMENT PRIMARY KEY, `title` VARCHAR(255) NOT NULL, `status` VARCHAR(255) NOT NULL DEFAULT 'draft', `published` TINYINT(1) NOT NULL DEFAULT 0, `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, `slug` VARCHAR(255) NOT NULL UNIQUE, `body` VARCHAR(255))",
+		},
+		{
+			NewSQLiteDialect(),
+			"CREATE TABLE IF NOT EXISTS `posts` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, `title` TEXT NOT NULL, `status` TEXT NOT NULL DEFAULT 'draft', `published` INTEGER NOT NULL DEFAULT 0, `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, `slug` TEXT NOT NULL UNIQUE, `body` TEXT)",
+		},
+		{
+			NewSQLServerDialect(),
+			"IF OBJECT_ID('posts', 'U') IS NULL CREATE TABLE [posts] ([id] INT IDENTITY(1,1) PRIMARY KEY, [title] NVARCHAR(255) NOT NULL, [status] NVARCHAR(255) NOT NULL DEFAULT 'draft', [published] BIT NOT NULL DEFAULT 0, [created_at] DATETIME2 NOT NULL DEFAULT CURRENT_TIMESTAMP, [slug] NVARCHAR(255) NOT NULL UNIQUE, [body] NVARCHAR(255))",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := NewDDLBuilder(tt.dialect).CreateTable(schema); got != tt.want {
+			t.Errorf("%s CreateTable:\n got: %s\nwant: %s", tt.dialect.Name(), got, tt.want)
+		}
+	}
+}
+
+// TestDDLBuilderTableConstraints 测试复合主键和多列唯一索引生成表级约束
+func TestDDLBuilderTableConstraints(t *testing.T) {
+	schema := NewBaseSchema("memberships")
+	schema.AddField(&Field{Name: "org_id", Type: TypeInteger, Primary: true})
+	schema.AddField(&Field{Name: "user_id", Type: TypeInteger, Primary: true})
+	schema.AddField(&Field{Name: "email", Type: TypeString})
+	schema.AddUniqueIndex("org_id", "email")
+
+	got := NewDDLBuilder(NewPostgreSQLDialect()).CreateTable(schema)
+	want := `CREATE TABLE IF NOT EXISTS "memberships" ("org_id" INTEGER NOT NULL, "user_id" INTEGER NOT NULL, "email" VARCHAR(255) NOT NULL, PRIMARY KEY ("org_id", "user_id"), UNIQUE ("org_id", "email"))`
+	if got != want {
+		t.Errorf("CreateTable:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// TestDDLBuilderAlterStatements 测试删表、增删列、索引和外键语句
+func TestDDLBuilderAlterStatements(t *testing.T) {
+	pg := NewDDLBuilder(NewPostgreSQLDialect())
+	mysql := NewDDLBuilder(NewMySQLDialect())
+	sqlite := NewDDLBuilder(NewSQLiteDialect())
+	mssql := NewDDLBuilder(NewSQLServerDialect())
+	views := NewField("views", TypeInteger).Default(0).Build()
+
+	checks := map[string]string{
+		pg.DropTable("posts"):              `DROP TABLE IF EXISTS "posts"`,
+		mssql.DropTable("posts"):           "IF OBJECT_ID('posts', 'U') IS NOT NULL DROP TABLE [posts]",
+		pg.AddColumn("posts", views):       `ALTER TABLE "posts" ADD COLUMN "views" INTEGER NOT NULL DEFAULT 0`,
+		mssql.AddColumn("posts", views):    "ALTER TABLE [posts] ADD [views] INT NOT NULL DEFAULT 0",
+		sqlite.DropColumn("posts", "body"): "ALTER TABLE `posts` DROP COLUMN `body`",
+		mysql.CreateIndex("posts", "", []string{"status", "created_at"}, false): "CREATE INDEX `idx_posts_status_created_at` ON `posts` (`status`, `created_at`)",
+		pg.CreateIndex("posts", "uniq_slug", []string{"slug"}, true):            `CREATE UNIQUE INDEX "uniq_slug" ON "posts" ("slug")`,
+	}
+	for got, want := range checks {
+		if got != want {
+			t.Errorf("\n got: %s\nwant: %s", got, want)
+		}
+	}
+
+	fk := &ForeignKeyDef{FromColumn: "user_id", ToColumn: "id", OnDelete: ActionCascade}
+	stmt, err := pg.AddForeignKey("posts", "users", fk)
+	if err != nil {
+		t.Fatalf("AddForeignKey failed: %v", err)
+	}
+	if want := `ALTER TABLE "posts" ADD CONSTRAINT "fk_posts_user_id" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE`; stmt != want {
+		t.Errorf("AddForeignKey:\n got: %s\nwant: %s", stmt, want)
+	}
+	if _, err := sqlite.AddForeignKey("posts", "users", fk); err == nil || !strings.Contains(err.Error(), "sqlite") {
+		t.Errorf("Expected SQLite to reject adding foreign keys, got %v", err)
+	}
+}
+
+// TestDynamicTableConfigDDL 测试动态表配置经 DDLBuilder 生成的建表语句
+func TestDynamicTableConfigDDL(t *testing.T) {
+	config := NewDynamicTableConfig("orders").
+		AddField(NewDynamicTableField("id", TypeInteger).AsPrimaryKey().WithAutoinc()).
+		AddField(NewDynamicTableField("status", TypeString).AsNotNull().WithDefault("pending"))
+
+	got := NewDDLBuilder(NewPostgreSQLDialect()).CreateTable(config.ToSchema("orders_42"))
+	want := `CREATE TABLE IF NOT EXISTS "orders_42" ("id" SERIAL PRIMARY KEY, "status" VARCHAR(255) NOT NULL DEFAULT 'pending')`
+	if got != want {
+		t.Errorf("CreateTable:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// TestDDLBuilderNativeArrays 测试 PostgreSQL 的 TypeArray 默认仍为 TEXT，开启 NativeArrays 后为 TEXT[]
+func TestDDLBuilderNativeArrays(t *testing.T) {
+	schema := NewBaseSchema("posts")
+	schema.AddField(NewField("tags", TypeArray).Null(true).Build())
+
+	if got := NewDDLBuilder(NewPostgreSQLDialect()).ColumnDefinitions(schema)[0]; got != `"tags" TEXT` {
+		t.Errorf("Expected migrations to keep TEXT for arrays, got %s", got)
+	}
+	if got := NewDDLBuilder(NewPostgreSQLDialect()).NativeArrays(true).ColumnDefinitions(schema)[0]; got != `"tags" TEXT[]` {
+		t.Errorf("Expected native array column, got %s", got)
+	}
+	if got := NewDDLBuilder(NewMySQLDialect()).NativeArrays(true).ColumnType(TypeArray); got != "TEXT" {
+		t.Errorf("Expected MySQL to ignore NativeArrays, got %s", got)
+	}
+
+	hook := NewPostgreSQLDynamicTableHook(nil)
+	fn := hook.generatePLPgSQLFunction(NewDynamicTableConfig("posts").AddField(NewDynamicTableField("tags", TypeArray)))
+	if !strings.Contains(fn, `"tags" TEXT[]`) {
+		t.Errorf("Expected dynamic tables to keep native arrays, got:\n%s", fn)
+	}
+}
+
+// TestDDLBuilderPartialIndex 测试部分索引：PostgreSQL/SQLite 生成 WHERE 谓词，MySQL 返回能力错误
+func TestDDLBuilderPartialIndex(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("email", TypeString).Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("login_count", TypeInteger).Build())
+	schema.AddPartialIndex("idx_users_active_email", []string{"email"},
+		And(Eq("status", "active"), Gt("login_count", 0)))
+
+	pg, err := NewDDLBuilder(NewPostgreSQLDialect()).CreateIndexes(schema)
+	if err != nil {
+		t.Fatalf("CreateIndexes failed: %v", err)
+	}
+	want := `CREATE INDEX "idx_users_active_email" ON "users" ("email") WHERE ("status" = 'active' AND "login_count" > 0)`
+	if len(pg) != 1 || pg[0] != want {
+		t.Errorf("PostgreSQL partial index:\n got: %v\nwant: %s", pg, want)
+	}
+
+	sqlite, err := NewDDLBuilder(NewSQLiteDialect()).CreateIndexes(schema)
+	if err != nil {
+		t.Fatalf("CreateIndexes failed: %v", err)
+	}
+	if len(sqlite) != 1 || !strings.HasSuffix(sqlite[0], "WHERE (`status` = 'active' AND `login_count` > 0)") {
+		t.Errorf("Unexpected SQLite partial index: %v", sqlite)
+	}
+
+	if _, err := NewDDLBuilder(NewMySQLDialect()).CreateIndexes(schema); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected capability error for MySQL, got %v", err)
+	}
+}
+
+// TestSchemaMigrationPartialIndex 测试 SchemaMigration 在 SQLite 上创建部分索引
+func TestSchemaMigrationPartialIndex(t *testing.T) {
+	repo := newSQLiteTestRepository(t, "partial.db")
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("email", TypeString).Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddPartialIndex("idx_users_active_email", []string{"email"}, Eq("status", "active"))
+
+	ctx := context.Background()
+	if err := NewSchemaMigration("001", "create users").CreateTable(schema).Up(ctx, repo); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	var partial int
+	if err := repo.QueryRow(ctx, "SELECT partial FROM pragma_index_list('users') WHERE name = ?", "idx_users_active_email").Scan(&partial); err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if partial != 1 {
+		t.Errorf("Expected idx_users_active_email to be a partial index")
+	}
+}
+
+// TestDDLBuilderAddNotNullColumn 测试没有默认值的 NOT NULL 列按“可空添加 → 回填 → 设置 NOT NULL”生成
+func TestDDLBuilderAddNotNullColumn(t *testing.T) {
+	tier := NewField("tier", TypeString).Build()
+
+	tests := []struct {
+		dialect SQLDialect
+		want    []string
+	}{
+		{NewPostgreSQLDialect(), []string{
+			`ALTER TABLE "users" ADD COLUMN "tier" VARCHAR(255)`,
+			`UPDATE "users" SET "tier" = 'free' WHERE "tier" IS NULL`,
+			`ALTER TABLE "users" ALTER COLUMN "tier" SET NOT NULL`,
+		}},
+		{NewMySQLDialect(), []string{
+			"ALTER TABLE `users` ADD COLUMN `tier` VARCHAR(255)",
+			"UPDATE `users` SET `tier` = 'free' WHERE `tier` IS NULL",
+			"ALTER TABLE `users` MODIFY COLUMN `tier` VARCHAR(255) NOT NULL",
+		}},
+		{NewSQLServerDialect(), []string{
+			"ALTER TABLE [users] ADD [tier] NVARCHAR(255)",
+			"UPDATE [users] SET [tier] = 'free' WHERE [tier] IS NULL",
+			"ALTER TABLE [users] ALTER COLUMN [tier] NVARCHAR(255) NOT NULL",
+		}},
+		{NewSQLiteDialect(), []string{
+			"ALTER TABLE `users` ADD COLUMN `tier` TEXT NOT NULL DEFAULT 'free'",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := NewDDLBuilder(tt.dialect).AddColumnStatements("users", tier, "free")
+		if err != nil {
+			t.Fatalf("%s: AddColumnStatements failed: %v", tt.dialect.Name(), err)
+		}
+		if strings.Join(got, ";\n") != strings.Join(tt.want, ";\n") {
+			t.Errorf("%s:\n got: %v\nwant: %v", tt.dialect.Name(), got, tt.want)
+		}
+	}
+
+	if _, err := NewDDLBuilder(NewPostgreSQLDialect()).AddColumnStatements("users", tier, nil); err == nil {
+		t.Error("Expected error for NOT NULL column without default or backfill")
+	}
+	withDefault := NewField("tier", TypeString).Default("free").Build()
+	if got, _ := NewDDLBuilder(NewPostgreSQLDialect()).AddColumnStatements("users", withDefault, nil); len(got) != 1 {
+		t.Errorf("Expected single ADD COLUMN for column with default, got %v", got)
+	}
+}
+
+// TestSchemaMigrationAddColumnBackfill 测试迁移在 SQLite 上为已有行回填 NOT NULL 列
+func TestSchemaMigrationAddColumnBackfill(t *testing.T) {
+	repo := newSQLiteTestRepository(t, "backfill.db")
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO users (name) VALUES ('alice')"); err != nil {
+		t.Fatal(err)
+	}
+
+	tier := NewField("tier", TypeString).Build()
+	if err := NewSchemaMigration("002", "add tier").AddColumn("users", tier).Up(ctx, repo); err == nil {
+		t.Fatal("Expected error without backfill value")
+	}
+
+	migration := NewSchemaMigration("002", "add tier").AddColumnWithBackfill("users", tier, "free")
+	if err := migration.Up(ctx, repo); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	var got string
+	if err := repo.QueryRow(ctx, "SELECT tier FROM users WHERE name = 'alice'").Scan(&got); err != nil || got != "free" {
+		t.Errorf("Expected existing row to be backfilled, got %q (%v)", got, err)
+	}
+	if err := migration.Down(ctx, repo); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+}
+
+// TestDDLBuilderCollation 测试列排序规则的建表语句及迁移时的名称校验
+func TestDDLBuilderCollation(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("email", TypeString).Collation("NOCASE").Build())
+
+	checks := map[SQLDialect]string{
+		NewPostgreSQLDialect(): `CREATE TABLE IF NOT EXISTS "users" ("email" VARCHAR(255) COLLATE "NOCASE" NOT NULL)`,
+		NewMySQLDialect():      "CREATE TABLE IF NOT EXISTS `users` (`email` VARCHAR(255) COLLATE NOCASE NOT NULL)",
+		NewSQLiteDialect():     "CREATE TABLE IF NOT EXISTS `users` (`email` TEXT COLLATE NOCASE NOT NULL)",
+	}
+	for dialect, want := range checks {
+		if got := NewDDLBuilder(dialect).CreateTable(schema); got != want {
+			t.Errorf("%s CreateTable:\n got: %s\nwant: %s", dialect.Name(), got, want)
+		}
+	}
+
+	repo := newSQLiteTestRepository(t, "collation.db")
+	ctx := context.Background()
+	if err := NewSchemaMigration("001", "create users").CreateTable(schema).Up(ctx, repo); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO users (email) VALUES ('Alice@Example.com')"); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM users WHERE email = 'alice@example.com'").Scan(&count); err != nil || count != 1 {
+		t.Errorf("Expected NOCASE collation to match, got %d (%v)", count, err)
+	}
+
+	bad := NewBaseSchema("bad")
+	bad.AddField(NewField("name", TypeString).Collation("NOCASE; DROP TABLE users").Build())
+	if err := NewSchemaMigration("002", "create bad").CreateTable(bad).Up(ctx, repo); err == nil {
+		t.Error("Expected error for invalid collation name")
+	}
+}
+
+// TestDDLBuilderRename 测试各方言的重命名列和重命名表语句
+func TestDDLBuilderRename(t *testing.T) {
+	tests := []struct {
+		builder *DDLBuilder
+		column  string
+		table   string
+	}{
+		{NewDDLBuilder(NewPostgreSQLDialect()), `ALTER TABLE "posts" RENAME COLUMN "body" TO "content"`, `ALTER TABLE "posts" RENAME TO "articles"`},
+		{NewDDLBuilder(NewSQLiteDialect()), "ALTER TABLE `posts` RENAME COLUMN `body` TO `content`", "ALTER TABLE `posts` RENAME TO `articles`"},
+		{NewDDLBuilder(NewMySQLDialect()), "ALTER TABLE `posts` RENAME COLUMN `body` TO `content`", "RENAME TABLE `posts` TO `articles`"},
+		{NewDDLBuilder(NewMySQLDialect()).ServerVersion("8.0.36"), "ALTER TABLE `posts` RENAME COLUMN `body` TO `content`", "RENAME TABLE `posts` TO `articles`"},
+		{NewDDLBuilder(NewSQLServerDialect()), "EXEC sp_rename 'posts.body', 'content', 'COLUMN'", "EXEC sp_rename 'posts', 'articles'"},
+	}
+	for _, tt := range tests {
+		got, err := tt.builder.RenameColumn("posts", "body", "content")
+		if err != nil {
+			t.Fatalf("%s RenameColumn failed: %v", tt.builder.dialectName(), err)
+		}
+		if got != tt.column {
+			t.Errorf("%s RenameColumn:\n got: %s\nwant: %s", tt.builder.dialectName(), got, tt.column)
+		}
+		if got := tt.builder.RenameTable("posts", "articles"); got != tt.table {
+			t.Errorf("%s RenameTable:\n got: %s\nwant: %s", tt.builder.dialectName(), got, tt.table)
+		}
+	}
+
+	legacy := NewDDLBuilder(NewMySQLDialect()).ServerVersion("5.7.44")
+	if _, err := legacy.RenameColumn("posts", "body", "content"); err == nil {
+		t.Error("Expected MySQL 5.7 RenameColumn to require a column definition")
+	}
+	content := NewField("content", TypeString).Null(true).Build()
+	got, err := legacy.RenameColumnWithDefinition("posts", "body", content)
+	if err != nil {
+		t.Fatalf("RenameColumnWithDefinition failed: %v", err)
+	}
+	if want := "ALTER TABLE `posts` CHANGE COLUMN `body` `content` VARCHAR(255)"; got != want {
+		t.Errorf("RenameColumnWithDefinition:\n got: %s\nwant: %s", got, want)
+	}
+	got, err = NewDDLBuilder(NewPostgreSQLDialect()).RenameColumnWithDefinition("posts", "body", content)
+	if err != nil || got != `ALTER TABLE "posts" RENAME COLUMN "body" TO "content"` {
+		t.Errorf("Expected PostgreSQL to use RENAME COLUMN, got %s (%v)", got, err)
+	}
+
+	sqlserver := NewDDLBuilder(NewSQLServerDialect())
+	got, err = sqlserver.RenameColumn("posts", "it's", "o'clock")
+	if err != nil {
+		t.Fatalf("RenameColumn failed: %v", err)
+	}
+	if want := "EXEC sp_rename 'posts.it''s', 'o''clock', 'COLUMN'"; got != want {
+		t.Errorf("RenameColumn with quotes:\n got: %s\nwant: %s", got, want)
+	}
+	if got, want := sqlserver.RenameTable("x'; DROP TABLE users; --", "y"), "EXEC sp_rename 'x''; DROP TABLE users; --', 'y'"; got != want {
+		t.Errorf("RenameTable with quotes:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// TestSchemaMigrationRename 测试迁移在 SQLite 上重命名表和列，Down 时改回原名
+func TestSchemaMigrationRename(t *testing.T) {
+	repo := newSQLiteTestRepository(t, "rename.db")
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "CREATE TABLE posts (id INTEGER PRIMARY KEY, body TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	migration := NewSchemaMigration("002", "rename posts").
+		RenameTable("posts", "articles").
+		RenameColumn("articles", "body", "content")
+	if err := migration.Up(ctx, repo); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO articles (content) VALUES ('hello')"); err != nil {
+		t.Errorf("Expected renamed table and column, got %v", err)
+	}
+
+	if err := migration.Down(ctx, repo); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	var body string
+	if err := repo.QueryRow(ctx, "SELECT body FROM posts").Scan(&body); err != nil || body != "hello" {
+		t.Errorf("Expected original names after Down, got %q (%v)", body, err)
+	}
+}
+
+// TestSchemaMigrationRenameColumnLegacyMySQL 测试迁移按查询到的 MySQL 版本生成 CHANGE COLUMN
+func TestSchemaMigrationRenameColumnLegacyMySQL(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{columns: []string{"VERSION()"}, values: [][]driver.Value{{"5.7.44-log"}}}, nil
+	}
+
+	content := NewField("content", TypeString).Null(true).Build()
+	migration := NewSchemaMigration("002", "rename body").RenameColumnWithDefinition("posts", "body", content)
+	ctx := context.Background()
+	if err := migration.Up(ctx, repo); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if err := migration.Down(ctx, repo); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	var changes []string
+	for _, stmt := range fake.Statements() {
+		if strings.HasPrefix(stmt, "ALTER TABLE") {
+			changes = append(changes, stmt)
+		}
+	}
+	want := []string{
+		"ALTER TABLE `posts` CHANGE COLUMN `body` `content` VARCHAR(255)",
+		"ALTER TABLE `posts` CHANGE COLUMN `content` `body` VARCHAR(255)",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected %v, got %v", want, changes)
+	}
+
+	if err := NewSchemaMigration("003", "rename").RenameColumn("posts", "a", "b").Up(ctx, repo); err == nil {
+		t.Error("Expected RenameColumn without a definition to fail on MySQL 5.7")
+	}
+	if !NewDDLBuilder(NewMySQLDialect()).ServerVersion("10.4.32-MariaDB").legacyMySQL() {
+		t.Error("Expected MariaDB 10.4 to need CHANGE COLUMN")
+	}
+}
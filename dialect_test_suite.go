@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ==================== 方言一致性检查 ====================
+// 供新方言（SQL Server、Oracle 等）的作者验证条件翻译和查询构建是否符合统一约定。
+// 期望值由被测方言自身的 QuoteIdentifier/GetPlaceholder 等方法推导，因此同一套检查适用于所有方言
+
+// DialectTestResult 单项检查结果
+type DialectTestResult struct {
+	Name    string
+	Passed  bool
+	SQL     string        // 实际生成的 SQL
+	Args    []interface{} // 实际生成的参数
+	Message string        // 失败原因
+}
+
+// DialectTestReport 一次完整检查的结果
+type DialectTestReport struct {
+	Dialect string
+	Results []DialectTestResult
+}
+
+// Passed 是否全部通过
+func (r *DialectTestReport) Passed() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures 返回未通过的检查
+func (r *DialectTestReport) Failures() []DialectTestResult {
+	failures := make([]DialectTestResult, 0)
+	for _, result := range r.Results {
+		if !result.Passed {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// DialectTestSuite 方言一致性检查集
+type DialectTestSuite struct {
+	dialect SQLDialect
+	schema  *BaseSchema
+}
+
+// NewDialectTestSuite 为方言创建检查集
+func NewDialectTestSuite(dialect SQLDialect) *DialectTestSuite {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddField(NewField("age", TypeInteger).Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+	return &DialectTestSuite{dialect: dialect, schema: schema}
+}
+
+// dialectCase 单项检查：build 生成 SQL，want 为期望的 SQL 和参数
+type dialectCase struct {
+	name     string
+	build    func() (string, []interface{}, error)
+	wantSQL  string
+	wantArgs []interface{}
+}
+
+// Run 执行全部检查
+func (s *DialectTestSuite) Run() *DialectTestReport {
+	report := &DialectTestReport{Dialect: s.dialect.Name()}
+	for _, c := range s.cases() {
+		report.Results = append(report.Results, s.runCase(c))
+	}
+	return report
+}
+
+func (s *DialectTestSuite) runCase(c dialectCase) DialectTestResult {
+	sql, args, err := c.build()
+	result := DialectTestResult{Name: c.name, SQL: sql, Args: args}
+	switch {
+	case err != nil:
+		result.Message = fmt.Sprintf("unexpected error: %v", err)
+	case sql != c.wantSQL:
+		result.Message = fmt.Sprintf("expected SQL %q, got %q", c.wantSQL, sql)
+	case !reflect.DeepEqual(normalizeArgs(args), normalizeArgs(c.wantArgs)):
+		result.Message = fmt.Sprintf("expected args %v, got %v", c.wantArgs, args)
+	default:
+		result.Passed = true
+	}
+	return result
+}
+
+// normalizeArgs 把 nil 切片和空切片视为相同
+func normalizeArgs(args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// translate 使用方言的 TranslateCondition 从 1 开始编号
+func (s *DialectTestSuite) translate(cond Condition) func() (string, []interface{}, error) {
+	return func() (string, []interface{}, error) {
+		argIndex := 1
+		return s.dialect.TranslateCondition(cond, &argIndex)
+	}
+}
+
+// build 构建完整查询
+func (s *DialectTestSuite) build(configure func(qc *SQLQueryConstructor)) func() (string, []interface{}, error) {
+	return func() (string, []interface{}, error) {
+		qc := NewSQLQueryConstructor(s.schema, s.dialect)
+		configure(qc)
+		return qc.Build(context.Background())
+	}
+}
+
+func (s *DialectTestSuite) cases() []dialectCase {
+	d := s.dialect
+	q := d.QuoteIdentifier
+	p := d.GetPlaceholder
+	from := " FROM " + q("users")
+	// 未指定 Select 时展开为 schema 的全部列
+	all := q("id") + ", " + q("name") + ", " + q("age") + ", " + q("created_at")
+
+	cases := []dialectCase{
+		{"eq", s.translate(Eq("name", "alice")), q("name") + " = " + p(1), []interface{}{"alice"}},
+		{"ne", s.translate(Ne("name", "bob")), q("name") + " != " + p(1), []interface{}{"bob"}},
+		{"gt", s.translate(Gt("age", 18)), q("age") + " > " + p(1), []interface{}{18}},
+		{"gte", s.translate(Gte("age", 18)), q("age") + " >= " + p(1), []interface{}{18}},
+		{"lt", s.translate(Lt("age", 65)), q("age") + " < " + p(1), []interface{}{65}},
+		{"lte", s.translate(Lte("age", 65)), q("age") + " <= " + p(1), []interface{}{65}},
+		{"in", s.translate(In("id", 1, 2, 3)), q("id") + " IN (" + p(1) + ", " + p(2) + ", " + p(3) + ")", []interface{}{1, 2, 3}},
+		{"between", s.translate(Between("age", 18, 65)), q("age") + " BETWEEN " + p(1) + " AND " + p(2), []interface{}{18, 65}},
+		{"like", s.translate(Like("name", "a%")), q("name") + " LIKE " + p(1), []interface{}{"a%"}},
+		{"now", s.translate(Lt("created_at", Now())), q("created_at") + " < " + d.CurrentTimestamp(), nil},
+		{
+			"and_or",
+			s.translate(And(Eq("name", "alice"), Or(Lt("age", 18), Gt("age", 65)))),
+			"(" + q("name") + " = " + p(1) + " AND (" + q("age") + " < " + p(2) + " OR " + q("age") + " > " + p(3) + "))",
+			[]interface{}{"alice", 18, 65},
+		},
+		{"not", s.translate(Not(Eq("name", "alice"))), "NOT (" + q("name") + " = " + p(1) + ")", []interface{}{"alice"}},
+		{"select_all", s.build(func(qc *SQLQueryConstructor) {}), "SELECT " + all + from, nil},
+		{
+			"select_columns",
+			s.build(func(qc *SQLQueryConstructor) { qc.Select("id", "name") }),
+			"SELECT " + q("id") + ", " + q("name") + from,
+			nil,
+		},
+		{
+			"placeholder_numbering",
+			s.build(func(qc *SQLQueryConstructor) { qc.Where(Eq("name", "alice")).WhereAll(Gt("age", 18), In("id", 1, 2)) }),
+			"SELECT " + all + from + " WHERE " + q("name") + " = " + p(1) +
+				" AND (" + q("age") + " > " + p(2) + " AND " + q("id") + " IN (" + p(3) + ", " + p(4) + "))",
+			[]interface{}{"alice", 18, 1, 2},
+		},
+		{
+			"distinct",
+			s.build(func(qc *SQLQueryConstructor) { qc.Distinct().Select("name") }),
+			"SELECT DISTINCT " + q("name") + from,
+			nil,
+		},
+		{
+			"order_by",
+			s.build(func(qc *SQLQueryConstructor) { qc.OrderBy("name", "DESC") }),
+			"SELECT " + all + from + " ORDER BY " + q("name") + " DESC",
+			nil,
+		},
+	}
+
+	limit, offset := 10, 20
+	cases = append(cases, dialectCase{
+		"limit_offset",
+		s.build(func(qc *SQLQueryConstructor) { qc.OrderBy("id", "ASC").Limit(limit).Offset(offset) }),
+		strings.TrimSpace("SELECT " + all + from + " ORDER BY " + q("id") + " ASC " + d.GenerateLimitOffset(&limit, &offset)),
+		nil,
+	})
+	return cases
+}
@@ -0,0 +1,38 @@
+package db
+
+import "testing"
+
+// TestDialectTestSuite 测试内置方言均满足一致性约定
+func TestDialectTestSuite(t *testing.T) {
+	for _, dialect := range []SQLDialect{NewMySQLDialect(), NewPostgreSQLDialect(), NewSQLiteDialect(), NewSQLServerDialect()} {
+		report := NewDialectTestSuite(dialect).Run()
+		if len(report.Results) == 0 {
+			t.Fatalf("%s: expected suite to run checks", dialect.Name())
+		}
+		for _, failure := range report.Failures() {
+			t.Errorf("%s/%s: %s", report.Dialect, failure.Name, failure.Message)
+		}
+	}
+}
+
+// brokenDialect 条件翻译误用内嵌 DefaultSQLDialect 的错误方言（生成反引号和 ?）
+type brokenDialect struct {
+	*PostgreSQLDialect
+}
+
+func (d *brokenDialect) TranslateCondition(condition Condition, argIndex *int) (string, []interface{}, error) {
+	return d.DefaultSQLDialect.TranslateCondition(condition, argIndex)
+}
+
+// TestDialectTestSuiteReportsFailures 测试检查集能发现不符合约定的方言
+func TestDialectTestSuiteReportsFailures(t *testing.T) {
+	report := NewDialectTestSuite(&brokenDialect{NewPostgreSQLDialect()}).Run()
+	if report.Passed() {
+		t.Fatal("Expected broken dialect to fail the suite")
+	}
+	for _, failure := range report.Failures() {
+		if failure.Message == "" || failure.SQL == "" {
+			t.Errorf("Unexpected failure report: %+v", failure)
+		}
+	}
+}
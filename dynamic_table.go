@@ -3,7 +3,10 @@ package db
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 )
 
 // DynamicTableConfig 动态表配置
@@ -21,9 +24,9 @@ type DynamicTableConfig struct {
 	// 触发条件：关联的父表（当父表插入或更新时触发建表）
 	ParentTable string
 
-	// 触发条件：检查父表的字段值
-	// 例如：type = 'custom' 时才创建此动态表
-	TriggerCondition string
+	// 触发条件：检查父表新插入行的字段值，为 nil 时总是创建
+	// 例如：Eq("type", "custom") 时才创建此动态表
+	TriggerCondition Condition
 
 	// 表创建策略：auto 自动创建，manual 手动创建
 	Strategy string // "auto" or "manual"
@@ -160,7 +163,7 @@ func (c *DynamicTableConfig) AddField(field *DynamicTableField) *DynamicTableCon
 }
 
 // WithParentTable 设置父表（用于自动触发）
-func (c *DynamicTableConfig) WithParentTable(parentTable, triggerCondition string) *DynamicTableConfig {
+func (c *DynamicTableConfig) WithParentTable(parentTable string, triggerCondition Condition) *DynamicTableConfig {
 	c.ParentTable = parentTable
 	c.TriggerCondition = triggerCondition
 	return c
@@ -186,6 +189,24 @@ func (c *DynamicTableConfig) WithOption(key string, value interface{}) *DynamicT
 	return c
 }
 
+// ToSchema 把动态表配置转换为指定表名的 Schema，用于 DDLBuilder 生成建表语句
+func (c *DynamicTableConfig) ToSchema(tableName string) *BaseSchema {
+	schema := NewBaseSchema(tableName)
+	for _, f := range c.Fields {
+		schema.AddField(&Field{
+			Name:    f.Name,
+			Type:    f.Type,
+			Default: f.Default,
+			Null:    f.Null,
+			Primary: f.Primary,
+			Autoinc: f.Autoinc,
+			Index:   f.Index,
+			Unique:  f.Unique,
+		})
+	}
+	return schema
+}
+
 // NewDynamicTableField 创建新的字段
 func NewDynamicTableField(name string, fieldType FieldType) *DynamicTableField {
 	return &DynamicTableField{
@@ -239,3 +260,163 @@ func (f *DynamicTableField) WithDescription(desc string) *DynamicTableField {
 	f.Description = desc
 	return f
 }
+
+// MatchesRecord 判断记录是否满足 TriggerCondition，未设置条件时总是满足
+// record 可以是 map[string]interface{}、结构体或结构体指针，结构体字段按列名（大小写不敏感或 snake_case）匹配。
+// 支持 eq/ne/gt/gte/lt/lte/in/notin/isnull/notnull 以及 And/Or/Not 组合，其他条件视为不满足
+func (c *DynamicTableConfig) MatchesRecord(record interface{}) bool {
+	if c.TriggerCondition == nil {
+		return true
+	}
+	matched, err := evaluateCondition(record, c.TriggerCondition)
+	return err == nil && matched
+}
+
+// evaluateCondition 在 Go 中对记录求值条件
+func evaluateCondition(record interface{}, cond Condition) (bool, error) {
+	switch c := cond.(type) {
+	case *CompositeCondition:
+		isOr := strings.EqualFold(c.Operator, "or")
+		for _, child := range c.Conditions {
+			matched, err := evaluateCondition(record, child)
+			if err != nil {
+				return false, err
+			}
+			if matched == isOr {
+				return isOr, nil
+			}
+		}
+		return !isOr, nil
+	case *NotCondition:
+		matched, err := evaluateCondition(record, c.Condition)
+		return !matched, err
+	case *SimpleCondition:
+		return evaluateSimpleCondition(record, c)
+	default:
+		return false, fmt.Errorf("cannot evaluate condition of type %T", cond)
+	}
+}
+
+func evaluateSimpleCondition(record interface{}, cond *SimpleCondition) (bool, error) {
+	value, found := recordFieldValue(record, cond.Field)
+	if !found {
+		return false, fmt.Errorf("record has no field %s", cond.Field)
+	}
+
+	switch cond.Operator {
+	case "isnull":
+		return value == nil, nil
+	case "notnull":
+		return value != nil, nil
+	case "in", "notin":
+		matched := false
+		for _, candidate := range cond.Value.([]interface{}) {
+			if cmp, ok := compareLiterals(value, candidate); ok && cmp == 0 {
+				matched = true
+				break
+			}
+		}
+		return matched == (cond.Operator == "in"), nil
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		cmp, ok := compareLiterals(value, cond.Value)
+		if !ok {
+			return cond.Operator == "ne", nil
+		}
+		switch cond.Operator {
+		case "eq":
+			return cmp == 0, nil
+		case "ne":
+			return cmp != 0, nil
+		case "gt":
+			return cmp > 0, nil
+		case "gte":
+			return cmp >= 0, nil
+		case "lt":
+			return cmp < 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	default:
+		return false, fmt.Errorf("cannot evaluate operator %s", cond.Operator)
+	}
+}
+
+// recordFieldValue 读取记录中的列值，指针字段解引用（nil 指针视为 NULL）
+func recordFieldValue(record interface{}, column string) (interface{}, bool) {
+	if m, ok := record.(map[string]interface{}); ok {
+		value, found := m[column]
+		return value, found
+	}
+
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	field, ok := findStructField(v, column)
+	if !ok {
+		return nil, false
+	}
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil, true
+		}
+		field = field.Elem()
+	}
+	return field.Interface(), true
+}
+
+// compareLiterals 比较两个值，数字按数值、字符串按字典序、时间按先后比较；无法比较时 ok 为 false
+func compareLiterals(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, a == nil && b == nil
+	}
+	if af, ok := literalFloat(a); ok {
+		if bf, ok := literalFloat(b); ok {
+			switch {
+			case af < bf:
+				return -1, true
+			case af > bf:
+				return 1, true
+			}
+			return 0, true
+		}
+		return 0, false
+	}
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv), true
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv), true
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			if av == bv {
+				return 0, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func literalFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
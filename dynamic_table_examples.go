@@ -15,7 +15,7 @@ func ExamplePostgreSQLDynamicTable() {
 	// 每个项目创建时自动创建一个专属表来存储该项目的自定义字段值
 	customFieldConfig := NewDynamicTableConfig("project_custom_fields").
 		WithDescription("动态存储项目的自定义字段值").
-		WithParentTable("projects", "type = 'advanced'"). // 只有高级项目才创建
+		WithParentTable("projects", Eq("type", "advanced")). // 只有高级项目才创建
 		WithStrategy("auto").
 		AddField(
 			NewDynamicTableField("id", TypeInteger).
@@ -92,7 +92,7 @@ func ExampleMySQLDynamicTable() {
 	// 1. 定义店铺订单表的配置
 	shopOrderConfig := NewDynamicTableConfig("shop_orders").
 		WithDescription("为每个店铺创建独立的订单表").
-		WithParentTable("shops", "status = 'active'"). // 只有活跃店铺创建
+		WithParentTable("shops", Eq("status", "active")). // 只有活跃店铺创建
 		WithStrategy("auto").
 		AddField(
 			NewDynamicTableField("id", TypeInteger).
@@ -166,7 +166,7 @@ func ExampleSQLiteDynamicTable() {
 	// 1. 定义日志表配置
 	logConfig := NewDynamicTableConfig("app_logs").
 		WithDescription("为每个应用创建独立的日志表").
-		WithParentTable("applications", "log_enabled = 1").
+		WithParentTable("applications", Eq("log_enabled", 1)).
 		WithStrategy("auto").
 		AddField(
 			NewDynamicTableField("id", TypeInteger).
@@ -232,7 +232,7 @@ func RealWorldExample(repo *Repository, ctx context.Context) error {
 	// 第1步：定义内容表配置
 	contentTableConfig := NewDynamicTableConfig("project_contents").
 		WithDescription("为每个项目存储内容数据").
-		WithParentTable("projects", "").
+		WithParentTable("projects", nil).
 		WithStrategy("auto").
 		AddField(
 			NewDynamicTableField("id", TypeInteger).
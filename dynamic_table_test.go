@@ -3,6 +3,8 @@ package db
 import (
 	"context"
 	"testing"
+
+	"gorm.io/gorm"
 )
 
 // TestDynamicTableRegistry 测试动态表注册表
@@ -54,7 +56,7 @@ func TestDynamicTableRegistry(t *testing.T) {
 func TestDynamicTableConfigBuilder(t *testing.T) {
 	config := NewDynamicTableConfig("users_data").
 		WithDescription("User data table").
-		WithParentTable("users", "status = 'active'").
+		WithParentTable("users", Eq("status", "active")).
 		WithStrategy("auto").
 		AddField(
 			NewDynamicTableField("id", TypeInteger).
@@ -343,3 +345,109 @@ func TestIntegrationFlow(t *testing.T) {
 
 	_ = ctx
 }
+
+// TestPostgreSQLTriggerCondition 测试组合条件翻译为触发器 WHEN 表达式
+func TestPostgreSQLTriggerCondition(t *testing.T) {
+	hook := NewPostgreSQLDynamicTableHook(&PostgreSQLAdapter{})
+
+	config := NewDynamicTableConfig("project_tasks").
+		WithParentTable("projects", And(Eq("type", "custom"), IsNotNull("owner_id"), Not(In("plan", "free", "trial"))))
+	got, err := hook.buildTriggerCondition(config)
+	if err != nil {
+		t.Fatalf("buildTriggerCondition failed: %v", err)
+	}
+	want := `(NEW."type" = 'custom' AND NEW."owner_id" IS NOT NULL AND NOT (NEW."plan" IN ('free', 'trial')))`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	// 字符串中的单引号需要转义
+	config.WithParentTable("projects", Eq("name", "O'Brien"))
+	if got, _ = hook.buildTriggerCondition(config); got != `NEW."name" = 'O''Brien'` {
+		t.Errorf("Expected escaped literal, got %s", got)
+	}
+
+	config.WithParentTable("projects", nil)
+	if got, _ = hook.buildTriggerCondition(config); got != "TRUE" {
+		t.Errorf("Expected TRUE without condition, got %s", got)
+	}
+}
+
+// TestExtractFieldValue 测试从结构体、指针和嵌入 gorm.Model 的结构体中提取 ID
+func TestExtractFieldValue(t *testing.T) {
+	type plain struct {
+		Id   int64
+		Name string
+	}
+	type tagged struct {
+		Code string `gorm:"column:code;primaryKey"`
+	}
+	type withModel struct {
+		gorm.Model
+		Title string
+	}
+
+	tests := []struct {
+		name     string
+		record   interface{}
+		expected interface{}
+	}{
+		{"plain struct", plain{Id: 7, Name: "a"}, int64(7)},
+		{"pointer", &plain{Id: 8}, int64(8)},
+		{"primaryKey tag", tagged{Code: "x1"}, "x1"},
+		{"embedded gorm.Model", &withModel{Model: gorm.Model{ID: 9}}, uint(9)},
+		{"nil pointer", (*plain)(nil), nil},
+		{"not a struct", 42, nil},
+	}
+	for _, tt := range tests {
+		if got := extractFieldValue(tt.record, "ID"); got != tt.expected {
+			t.Errorf("%s: expected %#v, got %#v", tt.name, tt.expected, got)
+		}
+	}
+
+	hook := &MySQLDynamicTableHook{}
+	params := hook.extractParamsFromRecord(&withModel{Model: gorm.Model{ID: 3}}, NewDynamicTableConfig("posts"))
+	if params["id"] != uint(3) {
+		t.Errorf("Expected id param 3, got %#v", params["id"])
+	}
+}
+
+// TestShouldCreateDynamicTable 测试按触发条件对新建记录求值
+func TestShouldCreateDynamicTable(t *testing.T) {
+	type account struct {
+		ID          int
+		Status      string
+		AccountType *string
+	}
+	premium := "premium"
+
+	config := NewDynamicTableConfig("account_logs").WithParentTable("accounts", Eq("status", "active"))
+	hook := &MySQLDynamicTableHook{}
+	if !hook.shouldCreateDynamicTable(&account{ID: 1, Status: "active"}, config) {
+		t.Error("Expected matching record to create table")
+	}
+	if hook.shouldCreateDynamicTable(&account{ID: 2, Status: "disabled"}, config) {
+		t.Error("Expected non-matching record not to create table")
+	}
+	if !(&SQLiteDynamicTableHook{}).shouldCreateDynamicTable(map[string]interface{}{"status": "active"}, config) {
+		t.Error("Expected matching map record to create table")
+	}
+
+	config.TriggerCondition = And(In("account_type", "premium", "enterprise"), Not(Eq("id", 0)))
+	if !config.MatchesRecord(account{ID: 3, AccountType: &premium}) {
+		t.Error("Expected IN condition on snake_case column to match")
+	}
+	if config.MatchesRecord(account{ID: 3}) {
+		t.Error("Expected nil pointer field not to match IN condition")
+	}
+
+	config.TriggerCondition = Like("status", "act%")
+	if config.MatchesRecord(account{Status: "active"}) {
+		t.Error("Expected unsupported operator not to match")
+	}
+
+	config.TriggerCondition = nil
+	if !config.MatchesRecord(account{}) {
+		t.Error("Expected record to match when there is no trigger condition")
+	}
+}
@@ -0,0 +1,319 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ==================== 测试用伪驱动 ====================
+// fakeDB 记录所有执行过的语句，并按脚本返回查询结果，
+// 用于在没有真实数据库的情况下验证生成的 SQL
+
+// fakeRows 脚本化的查询结果
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]interface{}
+	opened     int
+	pings      int
+
+	// 可选的脚本：返回 nil 时使用默认值（空结果 / 影响 1 行）
+	queryFn func(query string, args []interface{}) (*fakeRows, error)
+	execFn  func(query string, args []interface{}) (driver.Result, error)
+	pingFn  func() error
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	f.mu.Lock()
+	f.statements = append(f.statements, query)
+	f.args = append(f.args, values)
+	f.mu.Unlock()
+	return values
+}
+
+// Statements 返回已执行语句的副本
+func (f *fakeDB) Statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.statements...)
+}
+
+// LastArgs 返回最后一条语句的参数
+func (f *fakeDB) LastArgs() []interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.args) == 0 {
+		return nil
+	}
+	return f.args[len(f.args)-1]
+}
+
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	f.mu.Lock()
+	f.opened++
+	f.mu.Unlock()
+	return &fakeConn{db: f}, nil
+}
+
+func (f *fakeDB) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fake driver must be opened via connector")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return &fakeDriverTx{db: c.db}, nil
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	c.db.mu.Lock()
+	c.db.pings++
+	fn := c.db.pingFn
+	c.db.mu.Unlock()
+	if fn != nil {
+		return fn()
+	}
+	return nil
+}
+
+// CheckNamedValue 接受任意参数类型，便于断言原始参数
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values := c.db.record(query, args)
+	if c.db.execFn != nil {
+		if res, err := c.db.execFn(query, values); res != nil || err != nil {
+			return res, err
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := c.db.record(query, args)
+	if c.db.queryFn != nil {
+		rows, err := c.db.queryFn(query, values)
+		if err != nil {
+			return nil, err
+		}
+		if rows != nil {
+			return &fakeDriverRows{rows: rows}, nil
+		}
+	}
+	return &fakeDriverRows{rows: &fakeRows{columns: []string{"result"}}}, nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, toNamedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, toNamedValues(args))
+}
+
+func toNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+type fakeDriverTx struct {
+	db *fakeDB
+}
+
+func (t *fakeDriverTx) Commit() error {
+	t.db.record("COMMIT", nil)
+	return nil
+}
+
+func (t *fakeDriverTx) Rollback() error {
+	t.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeDriverRows struct {
+	rows *fakeRows
+	pos  int
+}
+
+func (r *fakeDriverRows) Columns() []string {
+	return r.rows.columns
+}
+
+func (r *fakeDriverRows) Close() error {
+	return nil
+}
+
+func (r *fakeDriverRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows.values) {
+		return io.EOF
+	}
+	copy(dest, r.rows.values[r.pos])
+	r.pos++
+	return nil
+}
+
+// ==================== 测试用伪适配器 ====================
+
+// fakeAdapter 基于 fakeDB 的 Adapter 实现，方言可配置
+type fakeAdapter struct {
+	db      *sql.DB
+	dialect SQLDialect
+}
+
+// newFakeRepository 创建使用伪驱动的 Repository
+func newFakeRepository(dialect SQLDialect) (*Repository, *fakeDB) {
+	fake := &fakeDB{}
+	adapter := &fakeAdapter{db: sql.OpenDB(fake), dialect: dialect}
+	return &Repository{adapter: adapter}, fake
+}
+
+func (a *fakeAdapter) Connect(ctx context.Context, config *Config) error {
+	return nil
+}
+
+func (a *fakeAdapter) Close() error {
+	return a.db.Close()
+}
+
+func (a *fakeAdapter) Ping(ctx context.Context) error {
+	return a.db.PingContext(ctx)
+}
+
+func (a *fakeAdapter) Begin(ctx context.Context, opts ...interface{}) (Tx, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeTx{tx: tx}, nil
+}
+
+func (a *fakeAdapter) Query(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error) {
+	return a.db.QueryContext(ctx, sql, args...)
+}
+
+func (a *fakeAdapter) QueryRow(ctx context.Context, sql string, args ...interface{}) *sql.Row {
+	return a.db.QueryRowContext(ctx, sql, args...)
+}
+
+func (a *fakeAdapter) Exec(ctx context.Context, sql string, args ...interface{}) (sql.Result, error) {
+	return a.db.ExecContext(ctx, sql, args...)
+}
+
+func (a *fakeAdapter) GetRawConn() interface{} {
+	return a.db
+}
+
+func (a *fakeAdapter) RegisterScheduledTask(ctx context.Context, task *ScheduledTaskConfig) error {
+	return fmt.Errorf("fake adapter: scheduled tasks not supported")
+}
+
+func (a *fakeAdapter) UnregisterScheduledTask(ctx context.Context, taskName string) error {
+	return fmt.Errorf("fake adapter: scheduled tasks not supported")
+}
+
+func (a *fakeAdapter) ListScheduledTasks(ctx context.Context) ([]*ScheduledTaskStatus, error) {
+	return nil, fmt.Errorf("fake adapter: scheduled tasks not supported")
+}
+
+func (a *fakeAdapter) GetQueryBuilderProvider() QueryConstructorProvider {
+	return NewDefaultSQLQueryConstructorProvider(a.dialect)
+}
+
+func (a *fakeAdapter) GetDatabaseFeatures() *DatabaseFeatures {
+	return &DatabaseFeatures{DatabaseName: a.dialect.Name()}
+}
+
+func (a *fakeAdapter) GetQueryFeatures() *QueryFeatures {
+	switch a.dialect.Name() {
+	case "postgresql":
+		return NewPostgreSQLQueryFeatures()
+	case "sqlite":
+		return NewSQLiteQueryFeatures()
+	case "sqlserver":
+		return NewSQLServerQueryFeatures()
+	default:
+		return NewMySQLQueryFeatures()
+	}
+}
+
+type fakeTx struct {
+	tx *sql.Tx
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
+func (t *fakeTx) Query(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, sql, args...)
+}
+
+func (t *fakeTx) QueryRow(ctx context.Context, sql string, args ...interface{}) *sql.Row {
+	return t.tx.QueryRowContext(ctx, sql, args...)
+}
+
+func (t *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, sql, args...)
+}
+
+// containsStatement 检查是否执行过包含指定片段的语句
+func containsStatement(statements []string, fragment string) bool {
+	for _, stmt := range statements {
+		if strings.Contains(stmt, fragment) {
+			return true
+		}
+	}
+	return false
+}
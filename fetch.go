@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrMultipleRows One 查询到不止一行时返回的错误
+var ErrMultipleRows = errors.New("query returned more than one row")
+
+// All 执行 qc 构建的查询并把所有行扫描到 dest
+// dest 为 *[]map[string]interface{}（按 ScanMaps 规则转换时间等类型）或结构体切片指针
+// （*[]T / *[]*T，列名按 db 标签或蛇形字段名匹配，带序列化钩子的字段调用 load）
+func (r *Repository) All(ctx context.Context, schema Schema, qc QueryConstructor, dest interface{}) error {
+	query, args, err := qc.Build(ctx)
+	if err != nil {
+		return err
+	}
+	rows, err := r.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if maps, ok := dest.(*[]map[string]interface{}); ok {
+		if maps == nil {
+			return fmt.Errorf("All: dest must not be nil")
+		}
+		result, err := ScanMaps(rows, schema)
+		if err != nil {
+			return err
+		}
+		*maps = result
+		return nil
+	}
+	return ScanStructsWithSchema(rows, schema, dest)
+}
+
+// Stream 执行 qc 构建的查询并逐行调用 fn，不在内存中缓存整个结果集，适合导出等大结果集场景
+// 行按 ScanMaps 规则转换；fn 返回错误时立即停止并原样返回该错误。结果集总会被关闭
+func (r *Repository) Stream(ctx context.Context, qc QueryConstructor, fn func(row map[string]interface{}) error) error {
+	query, args, err := qc.Build(ctx)
+	if err != nil {
+		return err
+	}
+	var schema Schema
+	if sqlQC, ok := qc.(*SQLQueryConstructor); ok {
+		schema = sqlQC.schema
+	}
+
+	rows, err := r.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("Stream: failed to get columns: %w", err)
+	}
+	typeNames := columnTypeNames(rows)
+	for rows.Next() {
+		row, err := scanMapRow(rows, columns, typeNames, schema)
+		if err != nil {
+			return fmt.Errorf("Stream: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Stream: rows error: %w", err)
+	}
+	return nil
+}
+
+// One 执行查询并要求恰好返回一行，dest 为 *map[string]interface{} 或结构体指针
+// 没有行时返回 sql.ErrNoRows，多于一行时返回 ErrMultipleRows。
+// SQL 构造器在副本上加 LIMIT 2 执行（已有更小的 LIMIT 时保留），只需读取足以判断多行的结果
+func (r *Repository) One(ctx context.Context, schema Schema, qc QueryConstructor, dest interface{}) error {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+		return fmt.Errorf("One: dest must be a non-nil pointer")
+	}
+	elemType := destVal.Elem().Type()
+	if elemType.Kind() != reflect.Struct && elemType != reflect.TypeOf(map[string]interface{}{}) {
+		return fmt.Errorf("One: dest must be a pointer to struct or map[string]interface{}")
+	}
+
+	if native, ok := qc.GetNativeBuilder().(*SQLQueryConstructor); ok {
+		limited := native.Clone().(*SQLQueryConstructor)
+		if limited.limitVal == nil || *limited.limitVal > 2 {
+			limited.Limit(2)
+		}
+		qc = limited
+	}
+
+	slice := reflect.New(reflect.SliceOf(elemType))
+	if err := r.All(ctx, schema, qc, slice.Interface()); err != nil {
+		return err
+	}
+	switch n := slice.Elem().Len(); {
+	case n == 0:
+		return sql.ErrNoRows
+	case n > 1:
+		return ErrMultipleRows
+	}
+	destVal.Elem().Set(slice.Elem().Index(0))
+	return nil
+}
+
+// Count 统计 schema 对应表中满足所有条件（AND）的行数
+func (r *Repository) Count(ctx context.Context, schema Schema, conditions ...Condition) (int64, error) {
+	qc, err := r.conditionQuery("Count", schema, conditions)
+	if err != nil {
+		return 0, err
+	}
+	query, args, err := qc.BuildCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := r.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists 判断 schema 对应表中是否存在满足所有条件（AND）的行，找到第一行即停止扫描
+func (r *Repository) Exists(ctx context.Context, schema Schema, conditions ...Condition) (bool, error) {
+	qc, err := r.conditionQuery("Exists", schema, conditions)
+	if err != nil {
+		return false, err
+	}
+	query, args, err := qc.BuildExists(ctx)
+	if err != nil {
+		return false, err
+	}
+	var exists bool
+	if err := r.QueryRow(ctx, query, args...).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// conditionQuery 按适配器方言创建带条件的查询构造器
+func (r *Repository) conditionQuery(op string, schema Schema, conditions []Condition) (*SQLQueryConstructor, error) {
+	dialect := r.sqlDialect()
+	if dialect == nil {
+		return nil, fmt.Errorf("%s: adapter %T does not provide a SQL dialect", op, r.GetAdapter())
+	}
+	qc := NewSQLQueryConstructor(schema, dialect)
+	for _, condition := range conditions {
+		qc.Where(condition)
+	}
+	return qc, nil
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fetchTestUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	Age  int64  `db:"age"`
+}
+
+func newFetchTestRepository(values [][]driver.Value) (*Repository, *fakeDB) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{columns: []string{"id", "name", "age"}, values: values}, nil
+	}
+	return repo, fake
+}
+
+// TestRepositoryAll 测试执行构造器查询并扫描到 map 切片和结构体切片
+func TestRepositoryAll(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+	rows := [][]driver.Value{{int64(1), "alice", int64(30)}, {int64(2), "bob", int64(25)}}
+
+	repo, fake := newFetchTestRepository(rows)
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Gt("age", 18))
+	var maps []map[string]interface{}
+	if err := repo.All(ctx, schema, qc, &maps); err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+	if len(maps) != 2 || maps[0]["name"] != "alice" || maps[1]["id"] != int64(2) {
+		t.Errorf("Unexpected maps: %v", maps)
+	}
+	if statements := fake.Statements(); len(statements) != 1 || statements[0] != `SELECT "id", "name", "age" FROM "users" WHERE "age" > $1` {
+		t.Errorf("Unexpected statements: %v", statements)
+	}
+
+	repo, _ = newFetchTestRepository(rows)
+	var users []fetchTestUser
+	if err := repo.All(ctx, schema, NewSQLQueryConstructor(schema, NewPostgreSQLDialect()), &users); err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+	if len(users) != 2 || users[0] != (fetchTestUser{ID: 1, Name: "alice", Age: 30}) || users[1].Name != "bob" {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Limit(0)
+	if err := repo.All(ctx, schema, qc, &users); err == nil {
+		t.Error("Expected build error to be returned")
+	}
+}
+
+// TestRepositoryOne 测试恰好一行时扫描成功，零行和多行时返回错误
+func TestRepositoryOne(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+
+	repo, _ := newFetchTestRepository([][]driver.Value{{int64(1), "alice", int64(30)}})
+	var user fetchTestUser
+	if err := repo.One(ctx, schema, qc, &user); err != nil {
+		t.Fatalf("One failed: %v", err)
+	}
+	if user.ID != 1 || user.Name != "alice" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+	var row map[string]interface{}
+	if err := repo.One(ctx, schema, qc, &row); err != nil || row["age"] != int64(30) {
+		t.Errorf("Unexpected map row %v (%v)", row, err)
+	}
+
+	repo, _ = newFetchTestRepository(nil)
+	if err := repo.One(ctx, schema, qc, &user); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+
+	repo, _ = newFetchTestRepository([][]driver.Value{{int64(1), "a", int64(1)}, {int64(2), "b", int64(2)}})
+	if err := repo.One(ctx, schema, qc, &user); !errors.Is(err, ErrMultipleRows) {
+		t.Errorf("Expected ErrMultipleRows, got %v", err)
+	}
+}
+
+// TestRepositoryOneLimit 测试 One 在构造器副本上加 LIMIT 2，不修改调用方的构造器
+func TestRepositoryOneLimit(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Eq("name", "alice"))
+
+	repo, fake := newFetchTestRepository([][]driver.Value{{int64(1), "alice", int64(30)}})
+	var user fetchTestUser
+	if err := repo.One(ctx, schema, qc, &user); err != nil {
+		t.Fatalf("One failed: %v", err)
+	}
+	if statements := fake.Statements(); len(statements) != 1 || statements[0] != `SELECT "id", "name", "age" FROM "users" WHERE "name" = $1 LIMIT 2` {
+		t.Errorf("Expected LIMIT 2 query, got %v", statements)
+	}
+	if sql, _, _ := qc.Build(ctx); strings.Contains(sql, "LIMIT") {
+		t.Errorf("Expected caller's constructor to stay unchanged, got %s", sql)
+	}
+
+	repo, fake = newFetchTestRepository([][]driver.Value{{int64(1), "alice", int64(30)}})
+	qc.Limit(1)
+	if err := repo.One(ctx, schema, qc, &user); err != nil {
+		t.Fatalf("One failed: %v", err)
+	}
+	if statements := fake.Statements(); len(statements) != 1 || !strings.HasSuffix(statements[0], "LIMIT 1") {
+		t.Errorf("Expected smaller LIMIT to be kept, got %v", statements)
+	}
+}
+
+// TestRepositoryCountExists 测试 Count/Exists 生成的语句和标量结果
+func TestRepositoryCountExists(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+
+	tests := []struct {
+		dialect SQLDialect
+		count   string
+		exists  string
+	}{
+		{NewPostgreSQLDialect(), `SELECT COUNT(*) FROM "users" WHERE "age" > $1`, `SELECT EXISTS(SELECT 1 FROM "users" WHERE "age" > $1 LIMIT 1)`},
+		{NewMySQLDialect(), "SELECT COUNT(*) FROM `users` WHERE `age` > ?", "SELECT EXISTS(SELECT 1 FROM `users` WHERE `age` > ? LIMIT 1)"},
+		{NewSQLServerDialect(), "SELECT COUNT(*) FROM [users] WHERE [age] > @p1", "SELECT CASE WHEN EXISTS(SELECT 1 FROM [users] WHERE [age] > @p1 ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY) THEN 1 ELSE 0 END"},
+	}
+	for _, tt := range tests {
+		repo, fake := newFakeRepository(tt.dialect)
+		fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+			if strings.Contains(query, "COUNT(*)") {
+				return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(7)}}}, nil
+			}
+			return &fakeRows{columns: []string{"exists"}, values: [][]driver.Value{{int64(1)}}}, nil
+		}
+
+		count, err := repo.Count(ctx, schema, Gt("age", 18))
+		if err != nil || count != 7 {
+			t.Errorf("%s: expected count 7, got %d (%v)", tt.dialect.Name(), count, err)
+		}
+		exists, err := repo.Exists(ctx, schema, Gt("age", 18))
+		if err != nil || !exists {
+			t.Errorf("%s: expected exists, got %v (%v)", tt.dialect.Name(), exists, err)
+		}
+		if statements := fake.Statements(); !reflect.DeepEqual(statements, []string{tt.count, tt.exists}) {
+			t.Errorf("%s: unexpected statements %v", tt.dialect.Name(), statements)
+		}
+		if args := fake.LastArgs(); !reflect.DeepEqual(args, []interface{}{18}) {
+			t.Errorf("%s: unexpected args %v", tt.dialect.Name(), args)
+		}
+	}
+
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{columns: []string{"exists"}, values: [][]driver.Value{{false}}}, nil
+	}
+	if exists, err := repo.Exists(ctx, schema); err != nil || exists {
+		t.Errorf("Expected not exists, got %v (%v)", exists, err)
+	}
+}
+
+// TestRepositoryStream 测试逐行回调、回调出错时提前停止并关闭结果集
+func TestRepositoryStream(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+	values := make([][]driver.Value, 10000)
+	for i := range values {
+		values[i] = []driver.Value{int64(i + 1), "user", int64(20)}
+	}
+	repo, _ := newFetchTestRepository(values)
+	db := repo.GetAdapter().GetRawConn().(*sql.DB)
+
+	var seen int64
+	err := repo.Stream(ctx, NewSQLQueryConstructor(schema, NewPostgreSQLDialect()), func(row map[string]interface{}) error {
+		seen++
+		if row["id"] != seen {
+			t.Fatalf("Unexpected row %d: %v", seen, row)
+		}
+		return nil
+	})
+	if err != nil || seen != 10000 {
+		t.Fatalf("Expected 10000 rows, got %d (%v)", seen, err)
+	}
+
+	errStop := errors.New("stop")
+	seen = 0
+	err = repo.Stream(ctx, NewSQLQueryConstructor(schema, NewPostgreSQLDialect()), func(row map[string]interface{}) error {
+		seen++
+		if seen == 100 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || seen != 100 {
+		t.Errorf("Expected early stop after 100 rows, got %d (%v)", seen, err)
+	}
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("Expected rows to be closed after early stop, %d connections still in use", inUse)
+	}
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldSerializer 字段序列化钩子
+// Store 把 Go 值转换为数据库值，Load 把数据库值还原为 Go 值
+type FieldSerializer struct {
+	Store func(interface{}) (interface{}, error)
+	Load  func(interface{}) (interface{}, error)
+}
+
+// StoreValue 按字段的序列化钩子转换待写入的值（nil 原样返回）
+func (f *Field) StoreValue(value interface{}) (interface{}, error) {
+	if f.Serializer == nil || f.Serializer.Store == nil || value == nil {
+		return value, nil
+	}
+	stored, err := f.Serializer.Store(value)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: store failed: %w", f.Name, err)
+	}
+	return stored, nil
+}
+
+// LoadValue 按字段的序列化钩子还原扫描到的值（nil 原样返回）
+func (f *Field) LoadValue(value interface{}) (interface{}, error) {
+	if f.Serializer == nil || f.Serializer.Load == nil || value == nil {
+		return value, nil
+	}
+	loaded, err := f.Serializer.Load(value)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: load failed: %w", f.Name, err)
+	}
+	return loaded, nil
+}
+
+// serializeChanges 对写入路径上的变更应用字段的 store 钩子
+func serializeChanges(schema Schema, changes map[string]interface{}) (map[string]interface{}, error) {
+	if schema == nil {
+		return changes, nil
+	}
+
+	result := make(map[string]interface{}, len(changes))
+	for name, value := range changes {
+		if field := schema.GetField(name); field != nil {
+			stored, err := field.StoreValue(value)
+			if err != nil {
+				return nil, err
+			}
+			value = stored
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// serializedScanTarget 扫描时先调用 load，再写入结构体字段
+type serializedScanTarget struct {
+	field *Field
+	dest  reflect.Value
+}
+
+func (t *serializedScanTarget) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		// 驱动复用的缓冲区在下一次 Next 后失效，先复制一份
+		src = append([]byte(nil), b...)
+	}
+	loaded, err := t.field.LoadValue(src)
+	if err != nil {
+		return err
+	}
+	return assignScannedValue(t.dest, loaded)
+}
+
+// assignScannedValue 把值写入 reflect 字段，必要时进行指针和类型转换
+func assignScannedValue(dest reflect.Value, value interface{}) error {
+	if value == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(dest.Type()):
+		dest.Set(v)
+	case v.Kind() == reflect.Ptr && v.Type().Elem().AssignableTo(dest.Type()):
+		if v.IsNil() {
+			dest.Set(reflect.Zero(dest.Type()))
+		} else {
+			dest.Set(v.Elem())
+		}
+	case dest.Kind() == reflect.Ptr && v.Type().AssignableTo(dest.Type().Elem()):
+		ptr := reflect.New(dest.Type().Elem())
+		ptr.Elem().Set(v)
+		dest.Set(ptr)
+	case v.Type().ConvertibleTo(dest.Type()):
+		dest.Set(v.Convert(dest.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", value, dest.Type())
+	}
+	return nil
+}
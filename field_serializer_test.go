@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+type testColor int
+
+const (
+	colorRed testColor = iota + 1
+	colorBlue
+)
+
+var testColorNames = map[testColor]string{colorRed: "red", colorBlue: "blue"}
+
+func storeTestColor(v interface{}) (interface{}, error) {
+	c, ok := v.(testColor)
+	if !ok {
+		return nil, fmt.Errorf("expected testColor, got %T", v)
+	}
+	return testColorNames[c], nil
+}
+
+func loadTestColor(v interface{}) (interface{}, error) {
+	var name string
+	switch s := v.(type) {
+	case string:
+		name = s
+	case []byte:
+		name = string(s)
+	default:
+		return nil, fmt.Errorf("unexpected stored color %T", v)
+	}
+	for c, n := range testColorNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown color %q", name)
+}
+
+// TestFieldSerializerRoundTrip 测试自定义类型经由 store/load 往返
+func TestFieldSerializerRoundTrip(t *testing.T) {
+	schema := NewBaseSchema("widgets")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("color", TypeString).Serializer(storeTestColor, loadTestColor).Build())
+
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+
+	// 写入路径：changeset 中的 Go 值经 store 转换为数据库值
+	cs := NewChangeset(schema).Cast(map[string]interface{}{"color": colorBlue})
+	if _, err := NewQueryBuilder(schema, repo).Insert(cs); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	args := fake.LastArgs()
+	if len(args) != 1 || args[0] != "blue" {
+		t.Errorf("Expected stored value \"blue\", got %v", args)
+	}
+
+	// 扫描路径：数据库值经 load 还原为 Go 值
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{
+			columns: []string{"id", "color"},
+			values:  [][]driver.Value{{int64(1), []byte("red")}, {int64(2), "blue"}},
+		}, nil
+	}
+
+	type widget struct {
+		ID    int64     `db:"id"`
+		Color testColor `db:"color"`
+	}
+
+	rows, err := repo.Query(context.Background(), "SELECT * FROM widgets")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	var widgets []widget
+	if err := ScanStructsWithSchema(rows, schema, &widgets); err != nil {
+		t.Fatalf("ScanStructsWithSchema failed: %v", err)
+	}
+	rows.Close()
+	if len(widgets) != 2 || widgets[0].Color != colorRed || widgets[1].Color != colorBlue {
+		t.Errorf("Unexpected scanned widgets: %+v", widgets)
+	}
+
+	rows, err = repo.Query(context.Background(), "SELECT * FROM widgets")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	defer rows.Close()
+	maps, err := ScanMaps(rows, schema)
+	if err != nil {
+		t.Fatalf("ScanMaps failed: %v", err)
+	}
+	if maps[0]["color"] != colorRed {
+		t.Errorf("Expected loaded color in map, got %v (%T)", maps[0]["color"], maps[0]["color"])
+	}
+}
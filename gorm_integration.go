@@ -162,18 +162,22 @@ func (a *gormAdapter) GetQueryFeatures() *QueryFeatures {
 
 // gormTx 实现 Tx 接口
 type gormTx struct {
-	tx *gorm.DB
+	tx    *gorm.DB
+	state txState
 }
 
 func (t *gormTx) Commit(ctx context.Context) error {
-	return t.tx.Commit().Error
+	return t.state.finish(true, func() error { return t.tx.Commit().Error })
 }
 
 func (t *gormTx) Rollback(ctx context.Context) error {
-	return t.tx.Rollback().Error
+	return t.state.finish(false, func() error { return t.tx.Rollback().Error })
 }
 
 func (t *gormTx) Exec(ctx context.Context, sql string, args ...interface{}) (sql.Result, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	result := t.tx.Exec(sql, args...)
 	if result.Error != nil {
 		return nil, result.Error
@@ -182,6 +186,9 @@ func (t *gormTx) Exec(ctx context.Context, sql string, args ...interface{}) (sql
 }
 
 func (t *gormTx) Query(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	rows, err := t.tx.Raw(sql, args...).Rows()
 	if err != nil {
 		return nil, err
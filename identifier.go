@@ -0,0 +1,74 @@
+package db
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ==================== 标识符长度限制 ====================
+
+// identifierHashLength 截断标识符时追加的哈希后缀长度（不含下划线）
+const identifierHashLength = 8
+
+// shortenIdentifier 把超过 maxLen 字节的标识符截断，并追加原名的哈希后缀
+// 数据库会静默截断过长的标识符（PostgreSQL 63 字节、MySQL 64 字节），
+// 前缀相同的长名截断后会互相冲突；哈希后缀保证结果确定且唯一。maxLen <= 0 表示不限制
+func shortenIdentifier(name string, maxLen int) string {
+	if maxLen <= 0 || len(name) <= maxLen {
+		return name
+	}
+
+	sum := sha1.Sum([]byte(name))
+	return truncateWithSuffix(name, maxLen, hex.EncodeToString(sum[:])[:identifierHashLength])
+}
+
+// truncateWithSuffix 把 name 截断为 maxLen 字节，末尾为 "_" + suffix，不拆分多字节字符
+func truncateWithSuffix(name string, maxLen int, suffix string) string {
+	if maxLen <= len(suffix)+1 {
+		return suffix[:maxLen]
+	}
+
+	cut := maxLen - len(suffix) - 1
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return name[:cut] + "_" + suffix
+}
+
+// BuildIndexName 生成索引名 idx_<table>_<col1>_<col2>...
+// 超过方言的 MaxIdentifierLength 时截断并追加哈希后缀
+func BuildIndexName(dialect SQLDialect, table string, columns ...string) string {
+	name := "idx_" + table
+	if len(columns) > 0 {
+		name += "_" + strings.Join(columns, "_")
+	}
+	if dialect == nil {
+		return name
+	}
+	return shortenIdentifier(name, dialect.MaxIdentifierLength())
+}
+
+// ==================== 排序规则 ====================
+
+// collationPattern 允许的排序规则名，例如 utf8mb4_unicode_ci、NOCASE、en-US-x-icu、de_DE.utf8
+var collationPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// validateCollation 校验排序规则名是安全的标识符
+func validateCollation(name string) error {
+	if !collationPattern.MatchString(name) {
+		return fmt.Errorf("invalid collation name %q", name)
+	}
+	return nil
+}
+
+// collateClause 生成 " COLLATE <name>"，PostgreSQL 的排序规则是区分大小写的标识符，需要转义
+func collateClause(dialect SQLDialect, name string) string {
+	if dialect != nil && dialect.Name() == "postgresql" {
+		return " COLLATE " + dialect.QuoteIdentifier(name)
+	}
+	return " COLLATE " + name
+}
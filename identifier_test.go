@@ -0,0 +1,85 @@
+package db
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// TestBuildIndexNameMaxLength 测试超长索引名按方言上限确定性截断且保持唯一
+func TestBuildIndexNameMaxLength(t *testing.T) {
+	pg := NewPostgreSQLDialect()
+	table := "tenant_customer_subscription_billing_events"
+	columns := []string{"organization_identifier", "created_at"}
+
+	name := BuildIndexName(pg, table, columns...)
+	if len(name) != pg.MaxIdentifierLength() {
+		t.Fatalf("Expected index name to be shortened to %d bytes, got %d: %s", pg.MaxIdentifierLength(), len(name), name)
+	}
+	if !strings.HasPrefix(name, "idx_"+table) {
+		t.Errorf("Expected shortened name to keep readable prefix, got %s", name)
+	}
+	if again := BuildIndexName(pg, table, columns...); again != name {
+		t.Errorf("Expected deterministic name, got %s and %s", name, again)
+	}
+
+	// 截断前缀相同的两个名字必须不同
+	other := BuildIndexName(pg, table, "organization_identifier", "updated_at")
+	if other == name {
+		t.Errorf("Expected names with same truncated prefix to differ, both %s", name)
+	}
+
+	if short := BuildIndexName(pg, "users", "email"); short != "idx_users_email" {
+		t.Errorf("Expected short name to be unchanged, got %s", short)
+	}
+	if sqlite := BuildIndexName(NewSQLiteDialect(), table, columns...); sqlite != "idx_"+table+"_organization_identifier_created_at" {
+		t.Errorf("Expected SQLite name to be unlimited, got %s", sqlite)
+	}
+	if mysql := BuildIndexName(NewMySQLDialect(), table, columns...); len(mysql) != 64 {
+		t.Errorf("Expected MySQL name to be shortened to 64 bytes, got %d", len(mysql))
+	}
+}
+
+// TestDynamicTableNameMaxLength 测试动态表钩子生成的名称不超过方言上限
+func TestDynamicTableNameMaxLength(t *testing.T) {
+	hook := NewPostgreSQLDynamicTableHook(nil)
+	config := &DynamicTableConfig{TableName: strings.Repeat("project_", 8)}
+
+	for _, name := range []string{
+		hook.generateTableName(config, map[string]interface{}{"id": 1}),
+		hook.generateFunctionName(config),
+		hook.generateTriggerName(config),
+	} {
+		if len(name) > 63 {
+			t.Errorf("Generated name exceeds 63 bytes: %s", name)
+		}
+	}
+	if hook.generateTableName(config, map[string]interface{}{"id": 1}) == hook.generateTableName(config, map[string]interface{}{"id": 2}) {
+		t.Error("Expected shortened table names to stay unique")
+	}
+}
+
+// TestDynamicTableTriggerNameShortening 测试触发器函数按与 Go 端相同的规则截断表名
+func TestDynamicTableTriggerNameShortening(t *testing.T) {
+	hook := NewPostgreSQLDynamicTableHook(nil)
+
+	long := &DynamicTableConfig{TableName: strings.Repeat("project_", 8)}
+	name := hook.generateTableName(long, map[string]interface{}{"id": 42})
+	fn := hook.generatePLPgSQLFunction(long)
+	if !strings.Contains(fn, "v_table_name := '"+name[:54]+"' || '_' || left(md5(v_table_name), 8)") {
+		t.Errorf("Expected function to shorten with prefix %s, got:\n%s", name[:54], fn)
+	}
+	sum := md5.Sum([]byte(long.TableName + "_42"))
+	if name != name[:54]+"_"+hex.EncodeToString(sum[:])[:8] {
+		t.Errorf("Expected md5 suffix matching the trigger function, got %s", name)
+	}
+
+	short := &DynamicTableConfig{TableName: "orders"}
+	if fn := hook.generatePLPgSQLFunction(short); !strings.Contains(fn, "v_table_name := 'orders_' || left(NEW.id::text, 47) || '_' || left(md5(v_table_name), 8)") {
+		t.Errorf("Expected id part to be truncated in the function, got:\n%s", fn)
+	}
+	if name := hook.generateTableName(short, map[string]interface{}{"id": 7}); name != "orders_7" {
+		t.Errorf("Expected short names to stay unchanged, got %s", name)
+	}
+}
@@ -0,0 +1,330 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SQLInsertConstructor INSERT 语句构造器
+// 列按 schema 字段顺序输出，多行插入时每行生成一组占位符
+type SQLInsertConstructor struct {
+	schema    Schema
+	dialect   SQLDialect
+	rows      []map[string]interface{}
+	returning []string
+	emulate   bool
+}
+
+// NewSQLInsertConstructor 创建 INSERT 构造器
+func NewSQLInsertConstructor(schema Schema, dialect SQLDialect) *SQLInsertConstructor {
+	return &SQLInsertConstructor{
+		schema:  schema,
+		dialect: dialect,
+		rows:    make([]map[string]interface{}, 0),
+	}
+}
+
+// Values 添加一行
+func (ic *SQLInsertConstructor) Values(values map[string]interface{}) *SQLInsertConstructor {
+	ic.rows = append(ic.rows, values)
+	return ic
+}
+
+// ValuesMany 添加多行，每行的列必须相同
+func (ic *SQLInsertConstructor) ValuesMany(rows []map[string]interface{}) *SQLInsertConstructor {
+	ic.rows = append(ic.rows, rows...)
+	return ic
+}
+
+// Returning 追加 RETURNING 子句返回插入的行（例如自增 id），"*" 表示所有列
+// 仅支持 RETURNING 的方言（PostgreSQL、SQLite）可用，其他方言在 Build 时返回错误
+func (ic *SQLInsertConstructor) Returning(fields ...string) *SQLInsertConstructor {
+	ic.returning = append(ic.returning, fields...)
+	return ic
+}
+
+// EmulateReturning 方言不支持 RETURNING（MySQL）时，由 Repository.InsertReturning
+// 在同一事务中先插入、再按 LAST_INSERT_ID() 查询插入的行，使各方言行为一致。仅支持单行插入
+func (ic *SQLInsertConstructor) EmulateReturning() *SQLInsertConstructor {
+	ic.emulate = true
+	return ic
+}
+
+// columns 校验每行的列并返回按 schema 字段顺序排列的列名
+func (ic *SQLInsertConstructor) columns() ([]string, error) {
+	if len(ic.rows) == 0 {
+		return nil, fmt.Errorf("insert into %s requires at least one row", ic.schema.TableName())
+	}
+
+	for key := range ic.rows[0] {
+		if ic.schema.GetField(key) == nil {
+			return nil, fmt.Errorf("insert into %s: unknown field %s", ic.schema.TableName(), key)
+		}
+	}
+	columns := make([]string, 0, len(ic.rows[0]))
+	for _, field := range ic.schema.Fields() {
+		if _, ok := ic.rows[0][field.Name]; ok {
+			columns = append(columns, field.Name)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("insert into %s requires at least one column", ic.schema.TableName())
+	}
+
+	for i, row := range ic.rows[1:] {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("insert into %s: row %d has %d columns, expected %d", ic.schema.TableName(), i+1, len(row), len(columns))
+		}
+		for _, col := range columns {
+			if _, ok := row[col]; !ok {
+				return nil, fmt.Errorf("insert into %s: row %d is missing column %s", ic.schema.TableName(), i+1, col)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// Build 构建 INSERT 语句
+// 绑定参数个数超过方言的 MaxParameters 时返回 *ParameterLimitError
+func (ic *SQLInsertConstructor) Build(ctx context.Context) (string, []interface{}, error) {
+	columns, err := ic.columns()
+	if err != nil {
+		return "", nil, err
+	}
+	returning, err := returningClause(ic.dialect, ic.returning)
+	if err != nil {
+		return "", nil, err
+	}
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = ic.dialect.QuoteIdentifier(col)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	sql.WriteString(ic.dialect.QuoteIdentifier(ic.schema.TableName()))
+	sql.WriteString(" (" + strings.Join(quoted, ", ") + ") VALUES ")
+
+	argIndex := 1
+	translator := &DefaultSQLTranslator{dialect: ic.dialect, argIndex: &argIndex}
+	args := make([]interface{}, 0, len(ic.rows)*len(columns))
+	for i, row := range ic.rows {
+		values, err := serializeChanges(ic.schema, row)
+		if err != nil {
+			return "", nil, err
+		}
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString("(")
+		for j, col := range columns {
+			if j > 0 {
+				sql.WriteString(", ")
+			}
+			args = translator.writeValue(&sql, args, values[col])
+		}
+		sql.WriteString(")")
+	}
+	sql.WriteString(returning)
+
+	if max := ic.dialect.MaxParameters(); max > 0 && len(args) > max {
+		return "", nil, &ParameterLimitError{Count: len(args), Max: max}
+	}
+	recordSQL(sql.String(), args)
+	return sql.String(), args, nil
+}
+
+// SQLInsertSelectConstructor INSERT ... SELECT 语句构造器，数据不经过应用程序直接在数据库内复制/转换
+type SQLInsertSelectConstructor struct {
+	target  Schema
+	columns []string
+	source  *SQLQueryConstructor
+}
+
+// InsertSelect 创建 INSERT INTO target (columns) SELECT ... 构造器，方言使用 source 的方言
+// source 的选择列个数必须与 columns 一致（未指定 Select 时按 source schema 的可查询字段数计算），在 Build 时校验
+func InsertSelect(target Schema, columns []string, source *SQLQueryConstructor) *SQLInsertSelectConstructor {
+	return &SQLInsertSelectConstructor{target: target, columns: columns, source: source}
+}
+
+// Build 构建 INSERT ... SELECT 语句，source 的参数原样传递
+func (is *SQLInsertSelectConstructor) Build(ctx context.Context) (string, []interface{}, error) {
+	table := is.target.TableName()
+	if is.source == nil {
+		return "", nil, fmt.Errorf("insert into %s requires a source query", table)
+	}
+	if len(is.columns) == 0 {
+		return "", nil, fmt.Errorf("insert into %s requires at least one column", table)
+	}
+	dialect := is.source.dialect
+	quoted := make([]string, len(is.columns))
+	for i, col := range is.columns {
+		if is.target.GetField(col) == nil {
+			return "", nil, fmt.Errorf("insert into %s: unknown field %s", table, col)
+		}
+		quoted[i] = dialect.QuoteIdentifier(col)
+	}
+
+	selected := len(is.source.selectedCols)
+	if selected == 0 {
+		selected = len(selectableColumns(is.source.schema))
+	}
+	if selected != len(is.columns) {
+		return "", nil, fmt.Errorf("insert into %s: source selects %d columns, expected %d", table, selected, len(is.columns))
+	}
+
+	argIndex := 1
+	selectSQL, args, err := is.source.build(ctx, &argIndex)
+	if err != nil {
+		return "", nil, err
+	}
+	if max := dialect.MaxParameters(); max > 0 && len(args) > max {
+		return "", nil, &ParameterLimitError{Count: len(args), Max: max}
+	}
+	sql := "INSERT INTO " + dialect.QuoteIdentifier(table) + " (" + strings.Join(quoted, ", ") + ") " + selectSQL
+	recordSQL(sql, args)
+	return sql, args, nil
+}
+
+// returningClause 生成 " RETURNING ..." 子句，fields 为空时返回空字符串
+func returningClause(dialect SQLDialect, fields []string) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+	if !dialect.SupportsReturning() {
+		return "", fmt.Errorf("RETURNING is not supported by the %s dialect", dialect.Name())
+	}
+	return " RETURNING " + returningColumns(dialect, fields), nil
+}
+
+// returningColumns 转义返回列，"*" 原样输出
+func returningColumns(dialect SQLDialect, fields []string) string {
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		if field == "*" {
+			quoted[i] = field
+			continue
+		}
+		quoted[i] = dialect.QuoteIdentifier(field)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// InsertReturning 执行带 Returning 的 INSERT 并返回插入的行
+// 方言支持 RETURNING 时直接查询；MySQL 且设置了 EmulateReturning 时在同一事务中
+// 先插入，再执行 SELECT ... WHERE pk = LAST_INSERT_ID()（插入时显式给出主键则按该值查询）
+func (r *Repository) InsertReturning(ctx context.Context, ic *SQLInsertConstructor) ([]map[string]interface{}, error) {
+	if len(ic.returning) == 0 {
+		return nil, fmt.Errorf("insert into %s: InsertReturning requires Returning", ic.schema.TableName())
+	}
+	if ic.dialect.SupportsReturning() || !ic.emulate || ic.dialect.Name() != "mysql" {
+		query, args, err := ic.Build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := r.Query(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		return ScanMaps(rows, ic.schema)
+	}
+
+	pk := ic.schema.PrimaryKeyField()
+	if pk == nil {
+		return nil, fmt.Errorf("insert into %s: emulated RETURNING requires a primary key", ic.schema.TableName())
+	}
+	if len(ic.rows) != 1 {
+		return nil, fmt.Errorf("insert into %s: emulated RETURNING supports a single row, got %d", ic.schema.TableName(), len(ic.rows))
+	}
+	plain := *ic
+	plain.returning = nil
+	insertSQL, insertArgs, err := plain.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selectSQL := "SELECT " + returningColumns(ic.dialect, ic.returning) +
+		" FROM " + ic.dialect.QuoteIdentifier(ic.schema.TableName()) +
+		" WHERE " + ic.dialect.QuoteIdentifier(pk.Name) + " = "
+	var selectArgs []interface{}
+	if id, ok := ic.rows[0][pk.Name]; ok && id != nil {
+		selectSQL += ic.dialect.GetPlaceholder(1)
+		selectArgs = append(selectArgs, id)
+	} else {
+		selectSQL += "LAST_INSERT_ID()"
+	}
+
+	var result []map[string]interface{}
+	err = r.Transaction(ctx, nil, func(tx Tx) error {
+		if _, err := tx.Exec(ctx, insertSQL, insertArgs...); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, selectSQL, selectArgs...)
+		if err != nil {
+			return fmt.Errorf("failed to load inserted row: %w", err)
+		}
+		defer rows.Close()
+		result, err = ScanMaps(rows, ic.schema)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Insert 按 changeset 的 schema 和变更生成 INSERT 并执行，changeset 无效时返回其错误
+// 方言支持 RETURNING（PostgreSQL、SQLite）且变更中没有主键时，通过 RETURNING 取回主键并写回 changeset，
+// 返回结果的 LastInsertId 为该主键
+func (r *Repository) Insert(ctx context.Context, cs *Changeset) (sql.Result, error) {
+	if !cs.IsValid() {
+		return nil, fmt.Errorf("changeset 验证失败: %v", cs.Errors())
+	}
+	dialect := r.sqlDialect()
+	if dialect == nil {
+		return nil, fmt.Errorf("Insert: adapter %T does not provide a SQL dialect", r.GetAdapter())
+	}
+
+	changes := cs.Changes()
+	ic := NewSQLInsertConstructor(cs.schema, dialect).Values(changes)
+	pk := cs.schema.PrimaryKeyField()
+	if pk == nil || changes[pk.Name] != nil || !dialect.SupportsReturning() {
+		query, args, err := ic.Build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return r.Exec(ctx, query, args...)
+	}
+
+	rows, err := r.InsertReturning(ctx, ic.Returning(pk.Name))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("insert into %s: expected 1 returned row, got %d", cs.schema.TableName(), len(rows))
+	}
+	id := rows[0][pk.Name]
+	cs.PutChange(pk.Name, id)
+	return &insertResult{id: id}, nil
+}
+
+// insertResult 通过 RETURNING 取回主键的单行插入结果
+type insertResult struct {
+	id interface{}
+}
+
+func (r *insertResult) LastInsertId() (int64, error) {
+	id, err := valueToInt64(r.id)
+	if err != nil {
+		return 0, fmt.Errorf("returned primary key %v is not an integer: %w", r.id, err)
+	}
+	return id.(int64), nil
+}
+
+func (r *insertResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func newInsertTestSchema() *BaseSchema {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddField(NewField("age", TypeInteger).Build())
+	return schema
+}
+
+// TestSQLInsertConstructor 测试单行和多行插入的列顺序、占位符和参数
+func TestSQLInsertConstructor(t *testing.T) {
+	schema := newInsertTestSchema()
+	rows := []map[string]interface{}{
+		{"age": 30, "name": "alice"},
+		{"name": "bob", "age": 25},
+	}
+
+	tests := []struct {
+		dialect SQLDialect
+		single  string
+		batch   string
+	}{
+		{
+			NewMySQLDialect(),
+			"INSERT INTO `users` (`name`, `age`) VALUES (?, ?)",
+			"INSERT INTO `users` (`name`, `age`) VALUES (?, ?), (?, ?)",
+		},
+		{
+			NewPostgreSQLDialect(),
+			`INSERT INTO "users" ("name", "age") VALUES ($1, $2)`,
+			`INSERT INTO "users" ("name", "age") VALUES ($1, $2), ($3, $4)`,
+		},
+	}
+	for _, tt := range tests {
+		sql, args, err := NewSQLInsertConstructor(schema, tt.dialect).Values(rows[0]).Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.single {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.single, sql)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"alice", 30}) {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+
+		sql, args, err = NewSQLInsertConstructor(schema, tt.dialect).ValuesMany(rows).Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.batch {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.batch, sql)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"alice", 30, "bob", 25}) {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+	}
+}
+
+// TestSQLInsertConstructorValidation 测试未知字段、空插入和列不一致的行
+func TestSQLInsertConstructorValidation(t *testing.T) {
+	schema := newInsertTestSchema()
+	dialect := NewMySQLDialect()
+
+	if _, _, err := NewSQLInsertConstructor(schema, dialect).Values(map[string]interface{}{"nickname": "x"}).Build(context.Background()); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+	if _, _, err := NewSQLInsertConstructor(schema, dialect).Build(context.Background()); err == nil {
+		t.Error("Expected error for insert without rows")
+	}
+	mismatched := []map[string]interface{}{{"name": "a", "age": 1}, {"name": "b"}}
+	if _, _, err := NewSQLInsertConstructor(schema, dialect).ValuesMany(mismatched).Build(context.Background()); err == nil {
+		t.Error("Expected error for rows with different columns")
+	}
+}
+
+// TestReturningClause 测试 INSERT/UPDATE 的 RETURNING 子句以及不支持的方言
+func TestReturningClause(t *testing.T) {
+	schema := newInsertTestSchema()
+	ctx := context.Background()
+
+	sql, _, err := NewSQLInsertConstructor(schema, NewPostgreSQLDialect()).
+		Values(map[string]interface{}{"name": "alice"}).
+		Returning("id", "name").
+		Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `INSERT INTO "users" ("name") VALUES ($1) RETURNING "id", "name"`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	sql, _, err = NewSQLUpdateConstructor(schema, NewSQLiteDialect()).
+		Set("age", 1).
+		Where(Eq("id", 2)).
+		Returning("*").
+		Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "UPDATE `users` SET `age` = ? WHERE `id` = ? RETURNING *"; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	mysql := NewMySQLDialect()
+	if _, _, err := NewSQLInsertConstructor(schema, mysql).Values(map[string]interface{}{"name": "a"}).Returning("id").Build(ctx); err == nil {
+		t.Error("Expected insert RETURNING to fail on mysql")
+	}
+	if _, _, err := NewSQLUpdateConstructor(schema, mysql).Set("age", 1).Where(Eq("id", 2)).Returning("id").Build(ctx); err == nil {
+		t.Error("Expected update RETURNING to fail on mysql")
+	}
+
+	// SQLite 实际执行 RETURNING 取回自增 id
+	repo := newSQLiteTestRepository(t, "returning.db")
+	if _, err := repo.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, age INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	sql, args, err := NewSQLInsertConstructor(schema, NewSQLiteDialect()).Values(map[string]interface{}{"name": "bob"}).Returning("id").Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id int64
+	if err := repo.QueryRow(ctx, sql, args...).Scan(&id); err != nil || id != 1 {
+		t.Errorf("Expected RETURNING id 1, got %d (%v)", id, err)
+	}
+}
+
+// TestInsertReturningMySQLEmulation 测试 MySQL 插入后在同一事务中按 LAST_INSERT_ID() 取回插入的行
+func TestInsertReturningMySQLEmulation(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{
+			columns: []string{"id", "name", "age"},
+			values:  [][]driver.Value{{int64(42), "alice", int64(30)}},
+		}, nil
+	}
+
+	rows, err := repo.InsertReturning(ctx, NewSQLInsertConstructor(schema, NewMySQLDialect()).
+		Values(map[string]interface{}{"name": "alice", "age": 30}).
+		Returning("*").
+		EmulateReturning())
+	if err != nil {
+		t.Fatalf("InsertReturning failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["id"] != int64(42) || rows[0]["name"] != "alice" {
+		t.Errorf("Unexpected returned rows: %v", rows)
+	}
+
+	expected := []string{
+		"BEGIN",
+		"INSERT INTO `users` (`name`, `age`) VALUES (?, ?)",
+		"SELECT * FROM `users` WHERE `id` = LAST_INSERT_ID()",
+		"COMMIT",
+	}
+	if statements := fake.Statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected %v, got %v", expected, statements)
+	}
+
+	if _, err := repo.InsertReturning(ctx, NewSQLInsertConstructor(schema, NewMySQLDialect()).
+		Values(map[string]interface{}{"name": "bob"}).Returning("id")); err == nil {
+		t.Error("Expected error without EmulateReturning on mysql")
+	}
+}
+
+// TestInsertSelect 测试 INSERT ... SELECT 的语句、参数传递和列数校验
+func TestInsertSelect(t *testing.T) {
+	archive := NewBaseSchema("users_archive")
+	archive.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	archive.AddField(NewField("name", TypeString).Build())
+	ctx := context.Background()
+
+	source := NewSQLQueryConstructor(newInsertTestSchema(), NewPostgreSQLDialect())
+	source.Select("id", "name")
+	source.Where(Lt("age", 18))
+	sql, args, err := InsertSelect(archive, []string{"id", "name"}, source).Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `INSERT INTO "users_archive" ("id", "name") SELECT "id", "name" FROM "users" WHERE "age" < $1`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{18}) {
+		t.Errorf("Expected source args to pass through, got %v", args)
+	}
+
+	all := NewSQLQueryConstructor(newInsertTestSchema(), NewPostgreSQLDialect())
+	if _, _, err := InsertSelect(archive, []string{"id", "name"}, all).Build(ctx); err == nil {
+		t.Error("Expected column count mismatch for SELECT * of three fields")
+	}
+	if _, _, err := InsertSelect(archive, []string{"id", "nickname"}, source).Build(ctx); err == nil {
+		t.Error("Expected error for unknown target column")
+	}
+}
+
+// TestRepositoryInsertChangeset 测试由 changeset 插入：无效时不执行，PostgreSQL 通过 RETURNING 写回主键
+func TestRepositoryInsertChangeset(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{int64(42)}}}, nil
+	}
+	cs := NewChangeset(schema).Cast(map[string]interface{}{"name": "alice", "age": 30})
+	result, err := repo.Insert(ctx, cs)
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if id, err := result.LastInsertId(); err != nil || id != 42 {
+		t.Errorf("Expected LastInsertId 42, got %d (%v)", id, err)
+	}
+	if got := cs.Get("id"); got != int64(42) {
+		t.Errorf("Expected primary key written back to changeset, got %v", got)
+	}
+	expected := []string{`INSERT INTO "users" ("name", "age") VALUES ($1, $2) RETURNING "id"`}
+	if statements := fake.Statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected %v, got %v", expected, statements)
+	}
+	if args := fake.LastArgs(); !reflect.DeepEqual(args, []interface{}{"alice", int64(30)}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	repo, fake = newFakeRepository(NewMySQLDialect())
+	if _, err := repo.Insert(ctx, NewChangeset(schema).Cast(map[string]interface{}{"name": "bob", "age": 20})); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if statements := fake.Statements(); !reflect.DeepEqual(statements, []string{"INSERT INTO `users` (`name`, `age`) VALUES (?, ?)"}) {
+		t.Errorf("Unexpected statements: %v", statements)
+	}
+
+	invalid := NewChangeset(schema).Cast(map[string]interface{}{"age": "not a number"})
+	repo, fake = newFakeRepository(NewMySQLDialect())
+	if _, err := repo.Insert(ctx, invalid); err == nil {
+		t.Error("Expected error for invalid changeset")
+	}
+	if statements := fake.Statements(); len(statements) != 0 {
+		t.Errorf("Expected no statements for invalid changeset, got %v", statements)
+	}
+}
@@ -0,0 +1,325 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ==================== JSON Schema 导出 ====================
+
+// JSONSchemaDraft 导出时使用的 JSON Schema 版本
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaDocument JSON Schema 顶层对象
+type jsonSchemaDocument struct {
+	Schema     string               `json:"$schema,omitempty"`
+	Title      string               `json:"title,omitempty"`
+	Type       string               `json:"type"`
+	Properties jsonSchemaProperties `json:"properties"`
+	Required   []string             `json:"required,omitempty"`
+}
+
+// jsonSchemaProperty 单个字段的 JSON Schema 描述
+// Type 可能是字符串，也可能是 ["string", "null"] 形式的数组
+type jsonSchemaProperty struct {
+	Type            interface{} `json:"type,omitempty"`
+	Format          string      `json:"format,omitempty"`
+	ContentEncoding string      `json:"contentEncoding,omitempty"`
+	MinLength       *int        `json:"minLength,omitempty"`
+	MaxLength       *int        `json:"maxLength,omitempty"`
+	Pattern         string      `json:"pattern,omitempty"`
+	Default         interface{} `json:"default,omitempty"`
+	ReadOnly        bool        `json:"readOnly,omitempty"`
+	WriteOnly       bool        `json:"writeOnly,omitempty"`
+}
+
+// jsonSchemaProperties 保持字段顺序的 properties 对象
+type jsonSchemaProperties struct {
+	names []string
+	props map[string]*jsonSchemaProperty
+}
+
+func (p jsonSchemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range p.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.props[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ExportJSONSchema 将 Schema 导出为 JSON Schema
+// 非空字段（自增主键和带默认值的字段除外）列入 required，
+// 长度、正则和邮箱验证器分别映射为 minLength/maxLength、pattern、format
+func ExportJSONSchema(s Schema) ([]byte, error) {
+	if s == nil {
+		return nil, fmt.Errorf("schema cannot be nil")
+	}
+
+	doc := jsonSchemaDocument{
+		Schema: JSONSchemaDraft,
+		Title:  s.TableName(),
+		Type:   "object",
+		Properties: jsonSchemaProperties{
+			props: make(map[string]*jsonSchemaProperty),
+		},
+		Required: make([]string, 0),
+	}
+
+	for _, field := range s.Fields() {
+		prop, err := fieldToJSONSchemaProperty(field)
+		if err != nil {
+			return nil, err
+		}
+		doc.Properties.names = append(doc.Properties.names, field.Name)
+		doc.Properties.props[field.Name] = prop
+
+		if !field.Null && !field.Autoinc && field.Default == nil {
+			doc.Required = append(doc.Required, field.Name)
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// fieldToJSONSchemaProperty 将字段映射为 JSON Schema 属性
+func fieldToJSONSchemaProperty(field *Field) (*jsonSchemaProperty, error) {
+	prop := &jsonSchemaProperty{Default: field.Default, ReadOnly: field.Autoinc, WriteOnly: field.WriteOnly}
+
+	var jsonType string
+	switch field.Type {
+	case TypeString:
+		jsonType = "string"
+	case TypeInteger:
+		jsonType = "integer"
+	case TypeFloat, TypeDecimal:
+		jsonType = "number"
+	case TypeBoolean:
+		jsonType = "boolean"
+	case TypeTime:
+		jsonType = "string"
+		prop.Format = "date-time"
+	case TypeBinary:
+		jsonType = "string"
+		prop.ContentEncoding = "base64"
+	case TypeMap, TypeJSON:
+		jsonType = "object"
+	case TypeArray:
+		jsonType = "array"
+	default:
+		return nil, fmt.Errorf("field %s: unsupported field type for JSON Schema: %s", field.Name, field.Type)
+	}
+
+	if field.Null {
+		prop.Type = []string{jsonType, "null"}
+	} else {
+		prop.Type = jsonType
+	}
+
+	for _, validator := range field.Validators {
+		switch v := validator.(type) {
+		case *LengthValidator:
+			if v.Min > 0 {
+				prop.MinLength = schemaIntPtr(v.Min)
+			}
+			if v.Max > 0 {
+				prop.MaxLength = schemaIntPtr(v.Max)
+			}
+		case *MinLengthValidator:
+			prop.MinLength = schemaIntPtr(v.Length)
+		case *MaxLengthValidator:
+			prop.MaxLength = schemaIntPtr(v.Length)
+		case *PatternValidator:
+			prop.Pattern = v.Pattern
+		case *EmailValidator:
+			prop.Format = "email"
+		}
+	}
+
+	return prop, nil
+}
+
+func schemaIntPtr(v int) *int {
+	return &v
+}
+
+// ==================== JSON Schema 导入 ====================
+
+// ImportJSONSchema 从 JSON Schema 对象构造 BaseSchema
+// 字段类型由 type/format 推断，required 字段为非空，
+// pattern、format: email、minLength/maxLength 转换为对应的验证器。
+// tableName 为空时使用 JSON Schema 的 title
+func ImportJSONSchema(data []byte, tableName string) (*BaseSchema, error) {
+	var doc struct {
+		Title      string          `json:"title"`
+		Type       interface{}     `json:"type"`
+		Properties json.RawMessage `json:"properties"`
+		Required   []string        `json:"required"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+
+	if tableName == "" {
+		tableName = doc.Title
+	}
+	if tableName == "" {
+		return nil, fmt.Errorf("table name is required (JSON Schema has no title)")
+	}
+	if jsonType, _ := parseJSONSchemaType(doc.Type); jsonType != "object" {
+		return nil, fmt.Errorf("JSON Schema root must be of type object")
+	}
+
+	names, err := jsonObjectKeys(doc.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema properties: %w", err)
+	}
+	props := make(map[string]*jsonSchemaProperty)
+	if len(names) > 0 {
+		if err := json.Unmarshal(doc.Properties, &props); err != nil {
+			return nil, fmt.Errorf("invalid JSON Schema properties: %w", err)
+		}
+	}
+
+	required := make(map[string]bool, len(doc.Required))
+	for _, name := range doc.Required {
+		required[name] = true
+	}
+
+	schema := NewBaseSchema(tableName)
+	for _, name := range names {
+		field, err := jsonSchemaPropertyToField(name, props[name], required[name])
+		if err != nil {
+			return nil, err
+		}
+		schema.AddField(field)
+	}
+
+	return schema, nil
+}
+
+// jsonSchemaPropertyToField 将 JSON Schema 属性转换为字段
+func jsonSchemaPropertyToField(name string, prop *jsonSchemaProperty, required bool) (*Field, error) {
+	if prop == nil {
+		return nil, fmt.Errorf("property %s: definition cannot be null", name)
+	}
+
+	jsonType, nullable := parseJSONSchemaType(prop.Type)
+
+	var fieldType FieldType
+	switch jsonType {
+	case "string":
+		switch {
+		case prop.Format == "date-time" || prop.Format == "date":
+			fieldType = TypeTime
+		case prop.ContentEncoding == "base64":
+			fieldType = TypeBinary
+		default:
+			fieldType = TypeString
+		}
+	case "integer":
+		fieldType = TypeInteger
+	case "number":
+		fieldType = TypeFloat
+	case "boolean":
+		fieldType = TypeBoolean
+	case "object":
+		fieldType = TypeJSON
+	case "array":
+		fieldType = TypeArray
+	default:
+		return nil, fmt.Errorf("property %s: unsupported JSON Schema type: %v", name, prop.Type)
+	}
+
+	builder := NewField(name, fieldType).
+		Null(nullable || (!required && prop.Default == nil))
+	if prop.Default != nil {
+		builder.Default(prop.Default)
+	}
+
+	if prop.MinLength != nil || prop.MaxLength != nil {
+		length := &LengthValidator{}
+		if prop.MinLength != nil {
+			length.Min = *prop.MinLength
+		}
+		if prop.MaxLength != nil {
+			length.Max = *prop.MaxLength
+		}
+		builder.Validate(length)
+	}
+	if prop.Pattern != "" {
+		builder.Validate(&PatternValidator{Pattern: prop.Pattern})
+	}
+	if prop.Format == "email" {
+		builder.Validate(&EmailValidator{})
+	}
+
+	return builder.Build(), nil
+}
+
+// parseJSONSchemaType 解析 type 关键字，返回主类型以及是否允许 null
+func parseJSONSchemaType(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, false
+	case []interface{}:
+		mainType, nullable := "", false
+		for _, item := range v {
+			s, _ := item.(string)
+			if s == "null" {
+				nullable = true
+			} else if mainType == "" {
+				mainType = s
+			}
+		}
+		return mainType, nullable
+	default:
+		return "", false
+	}
+}
+
+// jsonObjectKeys 按出现顺序返回 JSON 对象的键
+func jsonObjectKeys(raw json.RawMessage) ([]string, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected JSON object")
+	}
+
+	keys := make([]string, 0)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestExportJSONSchema 测试 Schema 导出为 JSON Schema
+func TestExportJSONSchema(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("email", TypeString).Validate(&EmailValidator{}).Build())
+	schema.AddField(NewField("name", TypeString).Validate(&LengthValidator{Min: 2, Max: 50}).Build())
+	schema.AddField(NewField("code", TypeString).Null(true).Validate(&PatternValidator{Pattern: "^[A-Z]{3}$"}).Build())
+	schema.AddField(NewField("age", TypeInteger).Null(true).Build())
+	schema.AddField(NewField("balance", TypeDecimal).Build())
+	schema.AddField(NewField("active", TypeBoolean).Default(true).Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+	schema.AddField(NewField("tags", TypeArray).Null(true).Build())
+	schema.AddField(NewField("meta", TypeJSON).Null(true).Build())
+
+	data, err := ExportJSONSchema(schema)
+	if err != nil {
+		t.Fatalf("ExportJSONSchema failed: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Exported schema is not valid JSON: %v", err)
+	}
+
+	if doc["title"] != "users" || doc["type"] != "object" {
+		t.Errorf("Unexpected title/type: %v / %v", doc["title"], doc["type"])
+	}
+
+	required := make([]string, 0)
+	for _, r := range doc["required"].([]interface{}) {
+		required = append(required, r.(string))
+	}
+	expectedRequired := []string{"email", "name", "balance", "created_at"}
+	if !reflect.DeepEqual(required, expectedRequired) {
+		t.Errorf("Expected required %v, got %v", expectedRequired, required)
+	}
+
+	props := doc["properties"].(map[string]interface{})
+	tests := []struct {
+		field    string
+		jsonType interface{}
+		format   interface{}
+	}{
+		{"id", "integer", nil},
+		{"email", "string", "email"},
+		{"age", []interface{}{"integer", "null"}, nil},
+		{"balance", "number", nil},
+		{"active", "boolean", nil},
+		{"created_at", "string", "date-time"},
+		{"tags", []interface{}{"array", "null"}, nil},
+		{"meta", []interface{}{"object", "null"}, nil},
+	}
+	for _, tt := range tests {
+		prop := props[tt.field].(map[string]interface{})
+		if !reflect.DeepEqual(prop["type"], tt.jsonType) {
+			t.Errorf("%s: expected type %v, got %v", tt.field, tt.jsonType, prop["type"])
+		}
+		if !reflect.DeepEqual(prop["format"], tt.format) {
+			t.Errorf("%s: expected format %v, got %v", tt.field, tt.format, prop["format"])
+		}
+	}
+
+	name := props["name"].(map[string]interface{})
+	if name["minLength"] != float64(2) || name["maxLength"] != float64(50) {
+		t.Errorf("Expected length constraints 2..50, got %v..%v", name["minLength"], name["maxLength"])
+	}
+	code := props["code"].(map[string]interface{})
+	if code["pattern"] != "^[A-Z]{3}$" {
+		t.Errorf("Expected pattern constraint, got %v", code["pattern"])
+	}
+}
+
+// TestImportJSONSchema 测试从 JSON Schema 导入 BaseSchema
+func TestImportJSONSchema(t *testing.T) {
+	data := []byte(`{
+		"title": "accounts",
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "pattern": "^[^@]+@[^@]+$", "maxLength": 120},
+			"nickname": {"type": ["string", "null"]},
+			"score": {"type": "number"},
+			"born_at": {"type": "string", "format": "date-time"},
+			"verified": {"type": "boolean", "default": false}
+		},
+		"required": ["email", "score"]
+	}`)
+
+	schema, err := ImportJSONSchema(data, "users")
+	if err != nil {
+		t.Fatalf("ImportJSONSchema failed: %v", err)
+	}
+
+	if schema.TableName() != "users" {
+		t.Errorf("Expected table name users, got %s", schema.TableName())
+	}
+
+	names := make([]string, 0)
+	for _, f := range schema.Fields() {
+		names = append(names, f.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"email", "nickname", "score", "born_at", "verified"}) {
+		t.Errorf("Unexpected field order: %v", names)
+	}
+
+	email := schema.GetField("email")
+	if email.Type != TypeString || email.Null {
+		t.Errorf("Expected required string email, got type=%s null=%v", email.Type, email.Null)
+	}
+	var pattern *PatternValidator
+	for _, v := range email.Validators {
+		if p, ok := v.(*PatternValidator); ok {
+			pattern = p
+		}
+	}
+	if pattern == nil || pattern.Pattern != "^[^@]+@[^@]+$" {
+		t.Errorf("Expected PatternValidator on email, got %v", email.Validators)
+	}
+
+	if f := schema.GetField("nickname"); !f.Null {
+		t.Error("Expected nickname to be nullable")
+	}
+	if f := schema.GetField("score"); f.Type != TypeFloat || f.Null {
+		t.Errorf("Expected required float score, got type=%s null=%v", f.Type, f.Null)
+	}
+	if f := schema.GetField("born_at"); f.Type != TypeTime {
+		t.Errorf("Expected time type for born_at, got %s", f.Type)
+	}
+	if f := schema.GetField("verified"); f.Null || f.Default != false {
+		t.Errorf("Expected verified to be not-null with default false, got null=%v default=%v", f.Null, f.Default)
+	}
+}
+
+// TestImportJSONSchemaErrors 测试非法 JSON Schema
+func TestImportJSONSchemaErrors(t *testing.T) {
+	if _, err := ImportJSONSchema([]byte(`{"type": "object", "properties": {}}`), ""); err == nil {
+		t.Error("Expected error when no table name is available")
+	}
+	if _, err := ImportJSONSchema([]byte(`{"type": "array"}`), "t"); err == nil {
+		t.Error("Expected error for non-object root")
+	}
+	if _, err := ImportJSONSchema([]byte(`{"type": "object", "properties": {"x": {"type": "null"}}}`), "t"); err == nil {
+		t.Error("Expected error for unsupported property type")
+	}
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// ==================== 键集分页 ====================
+
+// keysetCursor After/Before 记录的游标行
+type keysetCursor struct {
+	row    map[string]interface{}
+	before bool
+}
+
+// After 键集分页：只返回按当前 ORDER BY 排在 row（通常是上一页最后一行）之后的行
+// row 需要包含所有排序列的值。排序列必须包含主键或唯一列作为决胜列，否则 Build 返回错误；
+// 调用 AutoTiebreaker 后会自动追加主键排序
+func (qb *SQLQueryConstructor) After(row map[string]interface{}) *SQLQueryConstructor {
+	qb.keyset = &keysetCursor{row: row}
+	return qb
+}
+
+// Before 键集分页：只返回按当前 ORDER BY 排在 row 之前的行
+// 只生成条件、不改变排序方向，向前翻页时通常需要配合反向的 OrderBy 再把结果倒序
+func (qb *SQLQueryConstructor) Before(row map[string]interface{}) *SQLQueryConstructor {
+	qb.keyset = &keysetCursor{row: row, before: true}
+	return qb
+}
+
+// AutoTiebreaker 排序列不能保证唯一时自动追加主键升序作为决胜列
+func (qb *SQLQueryConstructor) AutoTiebreaker() *SQLQueryConstructor {
+	qb.autoTiebreaker = true
+	return qb
+}
+
+// buildKeyset 把游标展开为 WHERE 条件后构建查询
+func (qb *SQLQueryConstructor) buildKeyset(ctx context.Context, argIndex *int) (string, []interface{}, error) {
+	orderBys, err := qb.keysetOrder()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := qb.checkKeysetGrouping(orderBys); err != nil {
+		return "", nil, err
+	}
+	cond, err := keysetCondition(orderBys, qb.keyset)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resolved := *qb
+	resolved.keyset = nil
+	resolved.orderBys = orderBys
+	resolved.conditions = append(append([]Condition{}, qb.conditions...), cond)
+	return resolved.build(ctx, argIndex)
+}
+
+// keysetOrder 校验排序列能唯一确定行的顺序，必要时追加主键
+func (qb *SQLQueryConstructor) keysetOrder() ([]OrderBy, error) {
+	if qb.uniqueOrdering() {
+		return qb.orderBys, nil
+	}
+	pk := qb.schema.PrimaryKeyField()
+	if !qb.autoTiebreaker || pk == nil {
+		return nil, fmt.Errorf("keyset pagination on %s requires ORDER BY to include a primary key or unique column", qb.schema.TableName())
+	}
+	orderBys := append(append([]OrderBy{}, qb.orderBys...), OrderBy{Field: pk.Name, Direction: "ASC"})
+	return orderBys, nil
+}
+
+// checkKeysetGrouping 严格分组模式下，分组查询的排序列和决胜列都必须在 GROUP BY 中，否则生成的 ORDER BY 无效
+func (qb *SQLQueryConstructor) checkKeysetGrouping(orderBys []OrderBy) error {
+	if !qb.strictGrouping || len(qb.groupBys) == 0 {
+		return nil
+	}
+	for _, order := range orderBys {
+		if !containsString(qb.groupBys, order.Field) {
+			return fmt.Errorf("keyset pagination on %s: order column %s must appear in GROUP BY", qb.schema.TableName(), order.Field)
+		}
+	}
+	return nil
+}
+
+// uniqueOrdering 排序列是否包含主键、唯一列，或覆盖某个唯一索引的全部列
+func (qb *SQLQueryConstructor) uniqueOrdering() bool {
+	ordered := make(map[string]bool, len(qb.orderBys))
+	for _, order := range qb.orderBys {
+		ordered[order.Field] = true
+		if field := qb.schema.GetField(order.Field); field != nil && (field.Primary || field.Unique) {
+			return true
+		}
+	}
+
+	indexed, ok := qb.schema.(interface{ UniqueIndexes() [][]string })
+	if !ok {
+		return false
+	}
+	for _, columns := range indexed.UniqueIndexes() {
+		covered := true
+		for _, col := range columns {
+			if !ordered[col] {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// keysetCondition 生成 (a > ?) OR (a = ? AND b > ?) ... 形式的条件，每列按自身的排序方向比较
+func keysetCondition(orderBys []OrderBy, cursor *keysetCursor) (Condition, error) {
+	branches := make([]Condition, 0, len(orderBys))
+	for i, order := range orderBys {
+		value, ok := cursor.row[order.Field]
+		if !ok || value == nil {
+			return nil, fmt.Errorf("keyset cursor is missing a value for order column %s", order.Field)
+		}
+
+		parts := make([]Condition, 0, i+1)
+		for _, prev := range orderBys[:i] {
+			parts = append(parts, Eq(prev.Field, cursor.row[prev.Field]))
+		}
+		// 升序的 After 取更大的值，降序或 Before 取更小的值，两者同时成立时抵消
+		if (order.Direction == "DESC") != cursor.before {
+			parts = append(parts, Lt(order.Field, value))
+		} else {
+			parts = append(parts, Gt(order.Field, value))
+		}
+		branches = append(branches, And(parts...))
+	}
+	return Or(branches...), nil
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newKeysetTestSchema() *BaseSchema {
+	schema := NewBaseSchema("posts")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("slug", TypeString).Unique().Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+	schema.AddField(NewField("score", TypeInteger).Build())
+	return schema
+}
+
+// TestKeysetAfter 测试按排序方向展开的游标条件和参数顺序
+func TestKeysetAfter(t *testing.T) {
+	qc := NewSQLQueryConstructor(newKeysetTestSchema(), NewPostgreSQLDialect())
+	qc.Where(Gt("score", 0))
+	qc.OrderBy("score", "DESC")
+	qc.OrderBy("id", "ASC")
+	qc.Limit(20)
+	qc.After(map[string]interface{}{"score": 10, "id": 7})
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "id", "slug", "created_at", "score" FROM "posts" WHERE "score" > $1 AND (("score" < $2) OR ("score" = $3 AND "id" > $4)) ORDER BY "score" DESC, "id" ASC LIMIT 20`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{0, 10, 10, 7}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	qc = NewSQLQueryConstructor(newKeysetTestSchema(), NewPostgreSQLDialect())
+	qc.OrderBy("slug", "ASC")
+	qc.Before(map[string]interface{}{"slug": "m"})
+	sql, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "id", "slug", "created_at", "score" FROM "posts" WHERE (("slug" < $1)) ORDER BY "slug" ASC`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+// TestKeysetTiebreaker 测试排序列不唯一时报错，以及 AutoTiebreaker 追加主键
+func TestKeysetTiebreaker(t *testing.T) {
+	row := map[string]interface{}{"created_at": "2024-01-01", "id": 3}
+
+	qc := NewSQLQueryConstructor(newKeysetTestSchema(), NewMySQLDialect())
+	qc.OrderBy("created_at", "DESC")
+	qc.After(row)
+	if _, _, err := qc.Build(context.Background()); err == nil {
+		t.Error("Expected error for keyset ordering without a unique column")
+	}
+
+	qc.AutoTiebreaker()
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "SELECT `id`, `slug`, `created_at`, `score` FROM `posts` WHERE ((`created_at` < ?) OR (`created_at` = ? AND `id` > ?)) ORDER BY `created_at` DESC, `id` ASC"
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"2024-01-01", "2024-01-01", 3}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	qc = NewSQLQueryConstructor(newKeysetTestSchema(), NewMySQLDialect())
+	qc.OrderBy("score", "ASC")
+	qc.After(map[string]interface{}{"score": 1}).AutoTiebreaker()
+	if _, _, err := qc.Build(context.Background()); err == nil {
+		t.Error("Expected error when the cursor lacks the tiebreaker value")
+	}
+
+	schema := NewBaseSchema("events")
+	schema.AddField(NewField("day", TypeString).Build())
+	schema.AddField(NewField("seq", TypeInteger).Build())
+	schema.AddUniqueIndex("day", "seq")
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.OrderBy("day", "ASC")
+	qc.OrderBy("seq", "ASC")
+	qc.After(map[string]interface{}{"day": "mon", "seq": 2})
+	if _, _, err := qc.Build(context.Background()); err != nil {
+		t.Errorf("Expected unique index columns to be accepted, got %v", err)
+	}
+}
+
+// TestKeysetCountExistsArgCount 测试分页构造器的计数、存在性查询忽略游标，ArgCount 包含游标参数
+func TestKeysetCountExistsArgCount(t *testing.T) {
+	ctx := context.Background()
+	qc := NewSQLQueryConstructor(newKeysetTestSchema(), NewPostgreSQLDialect())
+	qc.Where(Eq("score", 5))
+	qc.OrderBy("slug", "ASC")
+	qc.After(map[string]interface{}{"slug": "m"})
+
+	sql, args, err := qc.BuildCount(ctx)
+	if err != nil {
+		t.Fatalf("BuildCount failed: %v", err)
+	}
+	if expected := `SELECT COUNT(*) FROM "posts" WHERE "score" = $1`; sql != expected || len(args) != 1 {
+		t.Errorf("Expected %s, got %s %v", expected, sql, args)
+	}
+	sql, _, err = qc.BuildExists(ctx)
+	if err != nil {
+		t.Fatalf("BuildExists failed: %v", err)
+	}
+	if expected := `SELECT EXISTS(SELECT 1 FROM "posts" WHERE "score" = $1 LIMIT 1)`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(newKeysetTestSchema(), NewPostgreSQLDialect())
+	qc.Where(Eq("score", 5))
+	qc.OrderBy("created_at", "DESC")
+	qc.After(map[string]interface{}{"created_at": "2024-01-01", "id": 3}).AutoTiebreaker()
+	if _, _, err := qc.BuildCount(ctx); err != nil {
+		t.Errorf("BuildCount failed: %v", err)
+	}
+	_, args, err = qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if qc.ArgCount() != len(args) {
+		t.Errorf("Expected ArgCount %d, got %d", len(args), qc.ArgCount())
+	}
+}
+// TestKeysetGroupBy 测试严格分组模式下排序列或决胜列不在 GROUP BY 中时拒绝键集分页，以及限定列的分组
+// TestKeysetGroupBy 测试分组查询的排序列或决胜列不在 GROUP BY 中时拒绝键集分页
+func TestKeysetGroupBy(t *testing.T) {
+	qc := NewSQLQueryConstructor(newKeysetTestSchema(), NewPostgreSQLDialect())
+	qc.Select("slug")
+	qc.GroupBy("slug")
+	qc.OrderBy("score", "ASC")
+	qc.After(map[string]interface{}{"score": 1, "id": 2}).AutoTiebreaker()
+	if _, _, err := qc.Build(context.Background()); err != nil {
+		t.Errorf("Expected grouping check to be off without strict grouping, got %v", err)
+	}
+	qc.StrictGrouping(true)
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "GROUP BY") {
+		t.Errorf("Expected error when the tiebreaker column is not grouped, got %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(newKeysetTestSchema(), NewPostgreSQLDialect())
+	qc.Select("slug")
+	qc.GroupBy("slug")
+	qc.OrderBy("slug", "ASC")
+	qc.After(map[string]interface{}{"slug": "m"})
+	sql, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "slug" FROM "posts" WHERE (("slug" > $1)) GROUP BY "slug" ORDER BY "slug" ASC`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(newKeysetTestSchema(), NewMySQLDialect())
+	qc.Select("posts.slug")
+	qc.GroupBy("posts.slug")
+	if sql, _, _ := qc.Build(context.Background()); !strings.HasSuffix(sql, " GROUP BY `posts`.`slug`") {
+		t.Errorf("Expected qualified GROUP BY column, got %s", sql)
+	}
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"strings"
+)
+
+// ==================== 关键字大小写 ====================
+
+// KeywordCase 生成 SQL 中关键字的大小写
+type KeywordCase string
+
+const (
+	// KeywordCasePreserve 保持构造器的输出不变（默认，构造器生成的关键字为大写）
+	KeywordCasePreserve KeywordCase = "preserve"
+	// KeywordCaseUpper 关键字统一大写，包括 Raw/OrderByRaw 等原样片段中的关键字
+	KeywordCaseUpper KeywordCase = "upper"
+	// KeywordCaseLower 关键字统一小写
+	KeywordCaseLower KeywordCase = "lower"
+)
+
+// sqlKeywords 参与大小写转换的关键字，函数名（COUNT、SUM 等）不在其中
+var sqlKeywords = map[string]bool{
+	"ALL": true, "AND": true, "ANY": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true,
+	"CASE": true, "COLLATE": true, "CROSS": true, "DELETE": true, "DESC": true, "DISTINCT": true,
+	"ELSE": true, "END": true, "ESCAPE": true, "EXISTS": true, "FALSE": true, "FETCH": true,
+	"FROM": true, "GROUP": true, "HAVING": true, "ILIKE": true, "IN": true, "INNER": true,
+	"INSERT": true, "INTO": true, "IS": true, "JOIN": true, "LATERAL": true, "LEFT": true,
+	"LIKE": true, "LIMIT": true, "NEXT": true, "NOT": true, "NULL": true, "OFFSET": true,
+	"ON": true, "ONLY": true, "OR": true, "ORDER": true, "RETURNING": true, "ROWS": true,
+	"SELECT": true, "SET": true, "THEN": true, "TRUE": true, "UNION": true, "UPDATE": true,
+	"VALUES": true, "WHEN": true, "WHERE": true,
+}
+
+// KeywordCase 设置 Build 输出中关键字的大小写，默认 KeywordCasePreserve
+// 只转换引号外的关键字，字符串字面量和转义后的标识符保持不变
+func (qb *SQLQueryConstructor) KeywordCase(mode KeywordCase) *SQLQueryConstructor {
+	qb.keywordCase = mode
+	return qb
+}
+
+// applyKeywordCase 按 mode 转换 sql 中引号外的关键字
+func applyKeywordCase(sql string, mode KeywordCase) string {
+	if mode != KeywordCaseUpper && mode != KeywordCaseLower {
+		return sql
+	}
+	convert := strings.ToUpper
+	if mode == KeywordCaseLower {
+		convert = strings.ToLower
+	}
+
+	var out strings.Builder
+	out.Grow(len(sql))
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			// 字符串和标识符原样输出，'' 之类的转义会被当作相邻的两段引号处理
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(sql[i+1:], closing)
+			if end < 0 {
+				out.WriteString(sql[i:])
+				return out.String()
+			}
+			out.WriteString(sql[i : i+end+2])
+			i += end + 2
+		case isWordByte(c):
+			start := i
+			for i < len(sql) && isWordByte(sql[i]) {
+				i++
+			}
+			word := sql[start:i]
+			// $1、@p1 之类的占位符以及 表.列 中的片段不是关键字
+			if start > 0 && (sql[start-1] == '@' || sql[start-1] == '$' || sql[start-1] == '.') {
+				out.WriteString(word)
+			} else if sqlKeywords[strings.ToUpper(word)] {
+				out.WriteString(convert(word))
+			} else {
+				out.WriteString(word)
+			}
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
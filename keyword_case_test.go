@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestKeywordCase 测试关键字统一转为小写/大写，字符串、标识符和占位符保持不变
+func TestKeywordCase(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewSQLServerDialect()).KeywordCase(KeywordCaseLower)
+	qc.Where(Or(Eq("status", "paid"), Between("amount", 10, 20))).
+		Where(IsNull("id")).
+		Where(Raw("[status] <> 'SELECT FROM'"))
+	qc.OrderBy("amount", "DESC").Limit(5)
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "select [id], [status], [amount] from [orders] where ([status] = @p1 or [amount] between @p2 and @p3) and [id] is null and ([status] <> 'SELECT FROM')" +
+		" order by [amount] desc offset 0 rows fetch next 5 rows only"
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if len(args) != 3 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	outside := strings.Replace(sql, "'SELECT FROM'", "", 1)
+	if strings.Contains(outside, "SELECT") || strings.Contains(outside, "WHERE") {
+		t.Errorf("Expected no uppercase keywords, got %s", sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).KeywordCase(KeywordCaseUpper)
+	qc.Where(Raw(`"status" in (select 'x')`))
+	qc.OrderByRaw("amount desc")
+	if sql, _, err = qc.Build(ctx); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "id", "status", "amount" FROM "orders" WHERE ("status" IN (SELECT 'x')) ORDER BY amount DESC`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Raw("status in ('a')"))
+	if sql, _, _ = qc.Build(ctx); sql != `SELECT "id", "status", "amount" FROM "orders" WHERE (status in ('a'))` {
+		t.Errorf("Expected preserve by default, got %s", sql)
+	}
+}
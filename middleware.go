@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// 操作类型常量
+const (
+	OpQuery    = "query"     // Repository.Query
+	OpQueryRow = "query_row" // Repository.QueryRow
+	OpExec     = "exec"      // Repository.Exec
+)
+
+// QueryOperation 描述一次经过 Repository 的数据库调用
+type QueryOperation struct {
+	Kind string        // 操作类型: OpQuery | OpQueryRow | OpExec
+	SQL  string        // SQL 语句
+	Args []interface{} // 参数
+}
+
+// QueryResult 数据库调用的结果，按操作类型填充对应字段
+type QueryResult struct {
+	Rows   *sql.Rows  // OpQuery
+	Row    *sql.Row   // OpQueryRow
+	Result sql.Result // OpExec
+}
+
+// QueryFunc 核心查询/执行函数，中间件通过包装它实现横切逻辑
+type QueryFunc func(ctx context.Context, op *QueryOperation) (*QueryResult, error)
+
+// Use 注册中间件
+// 中间件按注册顺序由外向内包装：先注册的最先执行 before 逻辑、最后执行 after 逻辑
+// 可用于链路追踪、指标统计、租户检查、日志等。
+// 中间件执行时不持有仓储的锁，可以再次通过该仓储执行查询（如写审计表）；重入的调用同样会经过整条链，注意避免无限递归
+func (r *Repository) Use(mw func(next QueryFunc) QueryFunc) {
+	if mw == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, mw)
+}
+
+// invoke 通过中间件链执行操作
+// 在读锁内取出适配器和中间件的快照后释放锁再执行整条链：sync.RWMutex 不允许递归读锁，
+// 中间件重入仓储时若有 Close/Connect 在等待写锁就会死锁
+func (r *Repository) invoke(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+	r.mu.RLock()
+	adapter := r.adapter
+	middlewares := r.middlewares
+	r.mu.RUnlock()
+
+	if adapter == nil {
+		return nil, ErrNotConnected
+	}
+	var fn QueryFunc = func(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+		return execOperation(ctx, adapter, op)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		fn = middlewares[i](fn)
+	}
+	return fn(ctx, op)
+}
+
+// execOperation 链的最内层：直接调用适配器
+func execOperation(ctx context.Context, adapter Adapter, op *QueryOperation) (*QueryResult, error) {
+	switch op.Kind {
+	case OpQuery:
+		rows, err := adapter.Query(ctx, op.SQL, op.Args...)
+		if err != nil {
+			return nil, err
+		}
+		return &QueryResult{Rows: rows}, nil
+	case OpQueryRow:
+		return &QueryResult{Row: adapter.QueryRow(ctx, op.SQL, op.Args...)}, nil
+	default:
+		result, err := adapter.Exec(ctx, op.SQL, op.Args...)
+		if err != nil {
+			return nil, err
+		}
+		return &QueryResult{Result: result}, nil
+	}
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRepositoryMiddlewareOrder 测试中间件按注册顺序由外向内包装
+func TestRepositoryMiddlewareOrder(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+
+	var trace []string
+	fake.execFn = func(query string, args []interface{}) (driver.Result, error) {
+		trace = append(trace, "exec")
+		return nil, nil
+	}
+
+	named := func(name string) func(next QueryFunc) QueryFunc {
+		return func(next QueryFunc) QueryFunc {
+			return func(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+				trace = append(trace, name+":before:"+op.Kind)
+				res, err := next(ctx, op)
+				trace = append(trace, name+":after")
+				return res, err
+			}
+		}
+	}
+	repo.Use(named("outer"))
+	repo.Use(named("inner"))
+
+	if _, err := repo.Exec(context.Background(), "DELETE FROM users WHERE id = ?", 1); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+
+	expected := []string{"outer:before:exec", "inner:before:exec", "exec", "inner:after", "outer:after"}
+	if !reflect.DeepEqual(trace, expected) {
+		t.Errorf("Expected %v, got %v", expected, trace)
+	}
+}
+
+// TestRepositoryMiddlewareShortCircuit 测试中间件可以拦截调用
+func TestRepositoryMiddlewareShortCircuit(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+
+	denied := errors.New("tenant check failed")
+	repo.Use(func(next QueryFunc) QueryFunc {
+		return func(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+			return nil, denied
+		}
+	})
+
+	if _, err := repo.Query(context.Background(), "SELECT * FROM users"); !errors.Is(err, denied) {
+		t.Errorf("Expected middleware error, got %v", err)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("Expected no statements to reach the driver, got %v", fake.Statements())
+	}
+}
+
+// TestRepositoryMiddlewareReentry 测试中间件重入仓储时，即使有写锁在等待也不会死锁
+func TestRepositoryMiddlewareReentry(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+
+	repo.Use(func(next QueryFunc) QueryFunc {
+		return func(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+			if op.SQL == "DELETE FROM users" {
+				// 模拟并发的 Close/Connect：写锁在等待时再次获取读锁会死锁
+				go func() {
+					repo.mu.Lock()
+					repo.mu.Unlock()
+				}()
+				time.Sleep(20 * time.Millisecond)
+				if _, err := repo.Exec(ctx, "INSERT INTO audit_log (action) VALUES (?)", "delete"); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, op)
+		}
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := repo.Exec(context.Background(), "DELETE FROM users")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Exec deadlocked when middleware re-entered the repository")
+	}
+
+	expected := []string{"INSERT INTO audit_log (action) VALUES (?)", "DELETE FROM users"}
+	if got := fake.Statements(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
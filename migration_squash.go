@@ -0,0 +1,228 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ==================== 迁移压缩 ====================
+
+// SquashedMigration 由多个迁移压缩而来的迁移
+// 在新数据库上执行时，被替换的版本会一并记录为已执行
+type SquashedMigration interface {
+	MigrationInterface
+	Replaces() []string
+}
+
+var (
+	createTablePattern = regexp.MustCompile("(?i)\\bCREATE\\s+TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?[`\"\\[]?(\\w+)")
+	alterTablePattern  = regexp.MustCompile("(?i)\\bALTER\\s+TABLE\\s+[`\"\\[]?(\\w+)")
+	dropTablePattern   = regexp.MustCompile("(?i)\\bDROP\\s+TABLE\\s+(?:IF\\s+EXISTS\\s+)?[`\"\\[]?(\\w+)")
+)
+
+// Squash 把 upToVersion（含）之前注册的迁移压缩为一个建表迁移
+// 表结构通过 DescribeTable 从当前数据库读取，因此数据库必须恰好迁移到 upToVersion。
+// 只还原列、类型、非空、默认值和主键；索引、唯一约束和外键需要另行迁移
+func (r *MigrationRunner) Squash(ctx context.Context, upToVersion string) (*RawSQLMigration, error) {
+	cutoff := -1
+	for i, migration := range r.migrations {
+		if migration.Version() == upToVersion {
+			cutoff = i
+			break
+		}
+	}
+	if cutoff < 0 {
+		return nil, fmt.Errorf("migration %s not found in registered migrations", upToVersion)
+	}
+
+	if err := r.ensureMigrationTable(ctx); err != nil {
+		return nil, err
+	}
+	executed, err := r.getExecutedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replaces := make([]string, 0, cutoff+1)
+	tables := make([]string, 0)
+	for i, migration := range r.migrations {
+		version := migration.Version()
+		_, applied := executed[version]
+		if i > cutoff {
+			if applied {
+				return nil, fmt.Errorf("cannot squash up to %s: later migration %s is already applied", upToVersion, version)
+			}
+			continue
+		}
+		if !applied {
+			return nil, fmt.Errorf("cannot squash up to %s: migration %s is not applied", upToVersion, version)
+		}
+
+		created, dropped, renamed, err := migrationTables(migration)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range dropped {
+			tables = removeString(tables, table)
+		}
+		for _, table := range created {
+			if !containsString(tables, table) {
+				tables = append(tables, table)
+			}
+		}
+		// 重命名在建表和加列之后执行，原名原地替换为新名
+		for _, pair := range renamed {
+			for i, table := range tables {
+				if table == pair[0] {
+					tables[i] = pair[1]
+				}
+			}
+		}
+		replaces = append(replaces, version)
+	}
+
+	squashed := NewRawSQLMigration(upToVersion, fmt.Sprintf("squashed migrations up to %s", upToVersion))
+	squashed.replaces = replaces
+
+	for _, table := range tables {
+		desc, err := r.repo.DescribeTable(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		squashed.AddUpSQL(buildCreateTableFromDescription(r.repo, desc))
+	}
+	for i := len(tables) - 1; i >= 0; i-- {
+		squashed.AddDownSQL(buildDropTableSQL(r.repo, tables[i]))
+	}
+
+	return squashed, nil
+}
+
+// migrationTables 返回迁移创建（或修改）和删除的表，以及按执行顺序的表重命名（原名, 新名）
+func migrationTables(migration MigrationInterface) (touched []string, dropped []string, renamed [][2]string, err error) {
+	switch m := migration.(type) {
+	case *SchemaMigration:
+		for _, schema := range m.createSchemas {
+			touched = append(touched, schema.TableName())
+		}
+		for _, schema := range m.dropSchemas {
+			dropped = append(dropped, schema.TableName())
+		}
+		for _, add := range m.addColumns {
+			touched = append(touched, add.table)
+		}
+		for _, op := range m.renames {
+			if op.table == "" {
+				renamed = append(renamed, [2]string{op.oldName, op.newName})
+			}
+		}
+	case *DataMigration:
+		// 数据迁移不改变表结构，压缩时只记录版本
+	case *RawSQLMigration:
+		for _, stmt := range m.upSQL {
+			for _, match := range createTablePattern.FindAllStringSubmatch(stmt, -1) {
+				touched = append(touched, match[1])
+			}
+			for _, match := range alterTablePattern.FindAllStringSubmatch(stmt, -1) {
+				touched = append(touched, match[1])
+			}
+			for _, match := range dropTablePattern.FindAllStringSubmatch(stmt, -1) {
+				dropped = append(dropped, match[1])
+			}
+		}
+	default:
+		return nil, nil, nil, fmt.Errorf("cannot squash migration %s: unsupported migration type %T", migration.Version(), migration)
+	}
+	return touched, dropped, renamed, nil
+}
+
+// buildCreateTableFromDescription 根据读取到的表结构生成建表语句
+func buildCreateTableFromDescription(repo *Repository, desc *TableDescription) string {
+	pk := desc.PrimaryKey()
+	inlinePK := len(pk) == 1
+
+	columns := make([]string, 0, len(desc.Columns)+1)
+	for _, col := range desc.Columns {
+		columns = append(columns, buildDescribedColumn(repo.GetAdapter(), col, inlinePK))
+	}
+	if len(pk) > 1 {
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pk, ", ")))
+	}
+
+	columnsSQL := strings.Join(columns, ", ")
+	switch repo.GetAdapter().(type) {
+	case *SQLServerAdapter:
+		return fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NULL CREATE TABLE %s (%s)", desc.Name, desc.Name, columnsSQL)
+	default:
+		return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", desc.Name, columnsSQL)
+	}
+}
+
+func buildDescribedColumn(adapter Adapter, col *ColumnDescription, inlinePK bool) string {
+	colType := col.Type
+	autoinc := ""
+	def := col.Default
+
+	if col.AutoIncrement {
+		switch adapter.(type) {
+		case *PostgreSQLAdapter:
+			colType = "SERIAL"
+			if strings.EqualFold(col.Type, "bigint") {
+				colType = "BIGSERIAL"
+			}
+			def = nil // nextval(...) 引用的序列由 SERIAL 自动创建
+		case *MySQLAdapter:
+			autoinc = " AUTO_INCREMENT"
+		case *SQLServerAdapter:
+			autoinc = " IDENTITY(1,1)"
+		}
+	}
+
+	column := fmt.Sprintf("%s %s", col.Name, colType)
+	if col.Primary && inlinePK {
+		column += " PRIMARY KEY"
+		if _, ok := adapter.(*SQLiteAdapter); ok && col.AutoIncrement {
+			column += " AUTOINCREMENT"
+		}
+	}
+	column += autoinc
+	if !col.Nullable && !(col.Primary && inlinePK) {
+		column += " NOT NULL"
+	}
+	if def != nil {
+		column += " DEFAULT " + formatDescribedDefault(adapter, *def)
+	}
+	return column
+}
+
+var mysqlUnquotedDefault = regexp.MustCompile(`(?i)^(-?\d+(\.\d+)?|CURRENT_\w+(\(\d*\))?|NULL|\(.*\))$`)
+
+// formatDescribedDefault 还原默认值表达式
+// MySQL 的 COLUMN_DEFAULT 对字符串默认值不带引号，需要补上
+func formatDescribedDefault(adapter Adapter, def string) string {
+	if _, ok := adapter.(*MySQLAdapter); ok && !mysqlUnquotedDefault.MatchString(def) {
+		return "'" + strings.ReplaceAll(def, "'", "''") + "'"
+	}
+	return def
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, target string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != target {
+			result = append(result, v)
+		}
+	}
+	return result
+}
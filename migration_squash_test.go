@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newSQLiteTestRepository(t *testing.T, name string) *Repository {
+	t.Helper()
+	repo, err := NewRepository(&Config{
+		Adapter:  "sqlite",
+		Database: filepath.Join(t.TempDir(), name),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create SQLite repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func squashTestMigrations() []MigrationInterface {
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("name", TypeString).Build())
+	users.AddField(NewField("email", TypeString).Null(true).Build())
+
+	return []MigrationInterface{
+		NewSchemaMigration("001", "create users").CreateTable(users),
+		NewRawSQLMigration("002", "create posts").
+			AddUpSQL("CREATE TABLE posts (id INTEGER NOT NULL, user_id INTEGER NOT NULL, title TEXT NOT NULL DEFAULT 'untitled', PRIMARY KEY (id, user_id))").
+			AddUpSQL("CREATE TABLE scratch (id INTEGER)"),
+		NewRawSQLMigration("003", "alter posts").
+			AddUpSQL("ALTER TABLE posts ADD COLUMN views INTEGER DEFAULT 0").
+			AddUpSQL("DROP TABLE scratch"),
+	}
+}
+
+func appliedVersions(t *testing.T, runner *MigrationRunner) []string {
+	t.Helper()
+	executed, err := runner.getExecutedMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read applied migrations: %v", err)
+	}
+	versions := make([]string, 0, len(executed))
+	for _, v := range []string{"001", "002", "003", "004"} {
+		if _, ok := executed[v]; ok {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// TestMigrationRunnerSquash 测试压缩后的迁移在新数据库上还原相同的表结构并记录被替换的版本
+func TestMigrationRunnerSquash(t *testing.T) {
+	ctx := context.Background()
+
+	source := newSQLiteTestRepository(t, "source.db")
+	runner := NewMigrationRunner(source)
+	for _, m := range squashTestMigrations() {
+		runner.Register(m)
+	}
+	if err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	squashed, err := runner.Squash(ctx, "003")
+	if err != nil {
+		t.Fatalf("Squash failed: %v", err)
+	}
+	if squashed.Version() != "003" {
+		t.Errorf("Expected squashed version 003, got %s", squashed.Version())
+	}
+	if !reflect.DeepEqual(squashed.Replaces(), []string{"001", "002", "003"}) {
+		t.Errorf("Unexpected replaced versions: %v", squashed.Replaces())
+	}
+	if len(squashed.upSQL) != 2 {
+		t.Fatalf("Expected 2 CREATE TABLE statements, got %v", squashed.upSQL)
+	}
+	for _, stmt := range squashed.upSQL {
+		if strings.Contains(stmt, "scratch") {
+			t.Errorf("Dropped table should not be squashed: %s", stmt)
+		}
+	}
+
+	fresh := newSQLiteTestRepository(t, "fresh.db")
+	freshRunner := NewMigrationRunner(fresh)
+	freshRunner.Register(squashed)
+	freshRunner.Register(NewRawSQLMigration("004", "add tags").AddUpSQL("CREATE TABLE tags (name TEXT)"))
+	if err := freshRunner.Up(ctx); err != nil {
+		t.Fatalf("Up with squashed migration failed: %v", err)
+	}
+
+	for _, table := range []string{"users", "posts"} {
+		want, err := source.DescribeTable(ctx, table)
+		if err != nil {
+			t.Fatalf("DescribeTable(%s) on source failed: %v", table, err)
+		}
+		got, err := fresh.DescribeTable(ctx, table)
+		if err != nil {
+			t.Fatalf("DescribeTable(%s) on fresh database failed: %v", table, err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("Table %s differs after squash:\nsource: %s\nfresh:  %s", table,
+				buildCreateTableFromDescription(source, want), buildCreateTableFromDescription(fresh, got))
+		}
+	}
+
+	if got := appliedVersions(t, freshRunner); !reflect.DeepEqual(got, []string{"001", "002", "003", "004"}) {
+		t.Errorf("Expected squashed range to be recorded as applied, got %v", got)
+	}
+
+	// 回滚压缩迁移时一并删除被替换版本的记录
+	if err := freshRunner.Down(ctx); err != nil {
+		t.Fatalf("Down 004 failed: %v", err)
+	}
+	if err := freshRunner.Down(ctx); err != nil {
+		t.Fatalf("Down squashed failed: %v", err)
+	}
+	if got := appliedVersions(t, freshRunner); len(got) != 0 {
+		t.Errorf("Expected no applied versions after rollback, got %v", got)
+	}
+}
+
+// TestMigrationRunnerSquashExistingDatabase 测试已迁移的数据库会跳过压缩迁移，部分迁移的数据库报错
+func TestMigrationRunnerSquashExistingDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	source := newSQLiteTestRepository(t, "source.db")
+	runner := NewMigrationRunner(source)
+	for _, m := range squashTestMigrations() {
+		runner.Register(m)
+	}
+	if err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	squashed, err := runner.Squash(ctx, "003")
+	if err != nil {
+		t.Fatalf("Squash failed: %v", err)
+	}
+
+	existing := NewMigrationRunner(source)
+	existing.Register(squashed)
+	if err := existing.Up(ctx); err != nil {
+		t.Fatalf("Expected squashed migration to be skipped on migrated database, got %v", err)
+	}
+
+	partial := newSQLiteTestRepository(t, "partial.db")
+	partialRunner := NewMigrationRunner(partial)
+	partialRunner.Register(squashTestMigrations()[0])
+	if err := partialRunner.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	partialRunner = NewMigrationRunner(partial)
+	partialRunner.Register(squashed)
+	if err := partialRunner.Up(ctx); err == nil {
+		t.Error("Expected error for partially applied squashed range")
+	}
+}
+
+// TestMigrationRunnerSquashRequiresAppliedRange 测试未执行到目标版本时无法压缩
+func TestMigrationRunnerSquashRequiresAppliedRange(t *testing.T) {
+	ctx := context.Background()
+
+	repo := newSQLiteTestRepository(t, "pending.db")
+	runner := NewMigrationRunner(repo)
+	for _, m := range squashTestMigrations() {
+		runner.Register(m)
+	}
+
+	if _, err := runner.Squash(ctx, "003"); err == nil {
+		t.Error("Expected error when migrations are not applied")
+	}
+	if _, err := runner.Squash(ctx, "999"); err == nil {
+		t.Error("Expected error for unknown version")
+	}
+}
+
+// TestMigrationRunnerTransactionalDDLWarning 测试事务中执行多语句 DDL 迁移时对 MySQL 发出警告
+func TestMigrationRunnerTransactionalDDLWarning(t *testing.T) {
+	ctx := context.Background()
+	newMigration := func() *RawSQLMigration {
+		return NewRawSQLMigration("001", "create tables").
+			AddUpSQL("CREATE TABLE a (id INT)").
+			AddUpSQL("CREATE TABLE b (id INT)")
+	}
+
+	run := func(dialect SQLDialect, strict bool) ([]string, *fakeDB, error) {
+		repo, fake := newFakeRepository(dialect)
+		warnings := make([]string, 0)
+		runner := NewMigrationRunner(repo).
+			UseTransactions(true).
+			Strict(strict).
+			OnWarning(func(message string) { warnings = append(warnings, message) })
+		runner.Register(newMigration())
+		return warnings, fake, runner.Up(ctx)
+	}
+
+	warnings, fake, err := run(NewMySQLDialect(), false)
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "mysql") {
+		t.Errorf("Expected one mysql warning, got %v", warnings)
+	}
+	stmts := strings.Join(fake.Statements(), "\n")
+	if !strings.Contains(stmts, "BEGIN") || !strings.Contains(stmts, "COMMIT") {
+		t.Errorf("Expected migration to run in a transaction, got:\n%s", stmts)
+	}
+
+	if warnings, _, err := run(NewPostgreSQLDialect(), false); err != nil || len(warnings) != 0 {
+		t.Errorf("Expected no warning for postgresql, got %v (%v)", warnings, err)
+	}
+
+	_, fake, err = run(NewMySQLDialect(), true)
+	if err == nil {
+		t.Fatal("Expected strict mode to refuse the migration")
+	}
+	for _, stmt := range fake.Statements() {
+		if strings.HasPrefix(stmt, "CREATE TABLE a") {
+			t.Errorf("Expected no DDL to run in strict mode, got %q", stmt)
+		}
+	}
+}
+
+// TestMigrationRunnerTransactionalRollback 测试支持事务性 DDL 时失败的迁移整体回滚
+func TestMigrationRunnerTransactionalRollback(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "tx_migration.db")
+
+	runner := NewMigrationRunner(repo).UseTransactions(true)
+	runner.Register(NewRawSQLMigration("001", "broken").
+		AddUpSQL("CREATE TABLE accounts (id INTEGER PRIMARY KEY)").
+		AddUpSQL("CREATE TABLE broken ("))
+	if err := runner.Up(ctx); err == nil {
+		t.Fatal("Expected migration to fail")
+	}
+
+	var count int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'accounts'").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Error("Expected accounts table to be rolled back")
+	}
+}
+
+// TestMigrationRunnerSquashRenamedTable 测试压缩时按新表名读取被重命名的表
+func TestMigrationRunnerSquashRenamedTable(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "renamed.db")
+
+	posts := NewBaseSchema("posts")
+	posts.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	runner := NewMigrationRunner(repo)
+	runner.Register(NewSchemaMigration("001", "create posts").CreateTable(posts))
+	runner.Register(NewSchemaMigration("002", "rename posts").RenameTable("posts", "articles"))
+	if err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	squashed, err := runner.Squash(ctx, "002")
+	if err != nil {
+		t.Fatalf("Squash failed: %v", err)
+	}
+	if len(squashed.upSQL) != 1 || !strings.Contains(squashed.upSQL[0], "articles") {
+		t.Errorf("Expected the renamed table to be recreated, got %v", squashed.upSQL)
+	}
+	if count := ddlStatementCount(repo, NewSchemaMigration("003", "renames").RenameTable("a", "b").RenameColumn("b", "x", "y"), true); count != 2 {
+		t.Errorf("Expected 2 rename statements, got %d", count)
+	}
+}
+
+// TestMigrationRunnerSquashDataMigration 测试包含数据迁移的版本范围可以压缩，数据迁移只记录版本
+func TestMigrationRunnerSquashDataMigration(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "data.db")
+
+	runner := NewMigrationRunner(repo)
+	for _, m := range squashTestMigrations() {
+		runner.Register(m)
+	}
+	runner.Register(NewDataMigration("004", "backfill names", "users", "id"))
+	if err := runner.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	squashed, err := runner.Squash(ctx, "004")
+	if err != nil {
+		t.Fatalf("Squash failed: %v", err)
+	}
+	if !reflect.DeepEqual(squashed.Replaces(), []string{"001", "002", "003", "004"}) {
+		t.Errorf("Unexpected replaced versions: %v", squashed.Replaces())
+	}
+	if len(squashed.upSQL) != 2 {
+		t.Errorf("Expected 2 CREATE TABLE statements, got %v", squashed.upSQL)
+	}
+}
@@ -3,7 +3,6 @@ package db
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -51,6 +50,23 @@ type SchemaMigration struct {
 	*BaseMigration
 	createSchemas []Schema
 	dropSchemas   []Schema
+	addColumns    []columnAddition
+	renames       []renameOperation
+}
+
+// renameOperation 重命名表（table 为空）或 table 中的列
+type renameOperation struct {
+	table   string
+	oldName string
+	newName string
+	field   *Field // 新列名下的列定义，MySQL 8.0 之前的 CHANGE COLUMN 需要
+}
+
+// columnAddition 要添加到已有表的列
+type columnAddition struct {
+	table    string
+	field    *Field
+	backfill interface{}
 }
 
 // NewSchemaMigration 创建基于 Schema 的迁移
@@ -74,20 +90,138 @@ func (m *SchemaMigration) DropTable(schema Schema) *SchemaMigration {
 	return m
 }
 
+// AddColumn 添加要向已有表添加的列
+// 没有默认值的 NOT NULL 列需要使用 AddColumnWithBackfill，否则 Up 返回错误
+func (m *SchemaMigration) AddColumn(table string, field *Field) *SchemaMigration {
+	return m.AddColumnWithBackfill(table, field, nil)
+}
+
+// AddColumnWithBackfill 添加列，并用 backfill 回填已有行后再设置 NOT NULL
+func (m *SchemaMigration) AddColumnWithBackfill(table string, field *Field, backfill interface{}) *SchemaMigration {
+	m.addColumns = append(m.addColumns, columnAddition{table: table, field: field, backfill: backfill})
+	return m
+}
+
+// RenameTable 添加重命名表操作，Down 时改回原名
+func (m *SchemaMigration) RenameTable(oldName, newName string) *SchemaMigration {
+	m.renames = append(m.renames, renameOperation{oldName: oldName, newName: newName})
+	return m
+}
+
+// RenameColumn 添加重命名列操作，Down 时改回原名
+// MySQL 8.0 之前不支持 RENAME COLUMN，需要改用 RenameColumnWithDefinition
+func (m *SchemaMigration) RenameColumn(table, oldName, newName string) *SchemaMigration {
+	m.renames = append(m.renames, renameOperation{table: table, oldName: oldName, newName: newName})
+	return m
+}
+
+// RenameColumnWithDefinition 把 oldName 列重命名为 field.Name，Down 时改回原名
+// 服务端为 MySQL 8.0 之前的版本时按 field 生成 CHANGE COLUMN，其他情况等同于 RenameColumn
+func (m *SchemaMigration) RenameColumnWithDefinition(table, oldName string, field *Field) *SchemaMigration {
+	m.renames = append(m.renames, renameOperation{table: table, oldName: oldName, newName: field.Name, field: field})
+	return m
+}
+
+// statement 生成重命名语句，reverse 为 true 时生成改回原名的语句
+func (op renameOperation) statement(builder *DDLBuilder, reverse bool) (string, error) {
+	from, to := op.oldName, op.newName
+	if reverse {
+		from, to = to, from
+	}
+	if op.table == "" {
+		return builder.RenameTable(from, to), nil
+	}
+	if op.field == nil {
+		return builder.RenameColumn(op.table, from, to)
+	}
+	field := *op.field
+	field.Name = to
+	return builder.RenameColumnWithDefinition(op.table, from, &field)
+}
+
+// renameBuilder 生成重命名语句的 DDL 构造器，MySQL 按服务端版本选择重命名列的语法
+func (m *SchemaMigration) renameBuilder(ctx context.Context, repo *Repository) *DDLBuilder {
+	builder := newRepositoryDDLBuilder(repo)
+	if len(m.renames) > 0 {
+		builder.ServerVersion(detectServerVersion(ctx, repo))
+	}
+	return builder
+}
+
 // Up 执行迁移
 func (m *SchemaMigration) Up(ctx context.Context, repo *Repository) error {
 	for _, schema := range m.createSchemas {
 		tableName := schema.TableName()
+		for _, field := range schema.Fields() {
+			if field.Collation != "" {
+				if err := validateCollation(field.Collation); err != nil {
+					return fmt.Errorf("failed to create table %s: column %s: %w", tableName, field.Name, err)
+				}
+			}
+		}
 		createSQL := buildCreateTableSQL(repo, schema)
 		if _, err := repo.Exec(ctx, createSQL); err != nil {
 			return fmt.Errorf("failed to create table %s: %w", tableName, err)
 		}
+		if err := createSchemaIndexes(ctx, repo, schema); err != nil {
+			return err
+		}
+	}
+
+	builder := newRepositoryDDLBuilder(repo)
+	for _, add := range m.addColumns {
+		if add.field.Collation != "" {
+			if err := validateCollation(add.field.Collation); err != nil {
+				return fmt.Errorf("failed to add column %s to %s: %w", add.field.Name, add.table, err)
+			}
+		}
+		stmts, err := builder.AddColumnStatements(add.table, add.field, add.backfill)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range stmts {
+			if _, err := repo.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("failed to add column %s to %s: %w", add.field.Name, add.table, err)
+			}
+		}
+	}
+
+	builder = m.renameBuilder(ctx, repo)
+	for _, op := range m.renames {
+		stmt, err := op.statement(builder, false)
+		if err == nil {
+			_, err = repo.Exec(ctx, stmt)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to rename %s to %s: %w", op.oldName, op.newName, err)
+		}
 	}
 	return nil
 }
 
 // Down 回滚迁移
 func (m *SchemaMigration) Down(ctx context.Context, repo *Repository) error {
+	// 先把 Up 中重命名的表和列改回原名
+	builder := m.renameBuilder(ctx, repo)
+	for i := len(m.renames) - 1; i >= 0; i-- {
+		op := m.renames[i]
+		stmt, err := op.statement(builder, true)
+		if err == nil {
+			_, err = repo.Exec(ctx, stmt)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to rename %s back to %s: %w", op.newName, op.oldName, err)
+		}
+	}
+
+	// 再删除 Up 中添加的列
+	for i := len(m.addColumns) - 1; i >= 0; i-- {
+		add := m.addColumns[i]
+		if _, err := repo.Exec(ctx, builder.DropColumn(add.table, add.field.Name)); err != nil {
+			return fmt.Errorf("failed to drop column %s from %s: %w", add.field.Name, add.table, err)
+		}
+	}
+
 	// 先删除 Up 中创建的表
 	for i := len(m.createSchemas) - 1; i >= 0; i-- {
 		schema := m.createSchemas[i]
@@ -109,191 +243,25 @@ func (m *SchemaMigration) Down(ctx context.Context, repo *Repository) error {
 }
 
 func buildCreateTableSQL(repo *Repository, schema Schema) string {
-	columns := make([]string, 0, len(schema.Fields()))
-	for _, field := range schema.Fields() {
-		columns = append(columns, buildColumnDefinition(repo.GetAdapter(), field))
-	}
-
-	columnsSQL := strings.Join(columns, ", ")
-	tableName := schema.TableName()
-
-	switch repo.GetAdapter().(type) {
-	case *SQLServerAdapter:
-		return fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NULL CREATE TABLE %s (%s)", tableName, tableName, columnsSQL)
-	default:
-		return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", tableName, columnsSQL)
-	}
-}
-
-func buildDropTableSQL(repo *Repository, tableName string) string {
-	switch repo.GetAdapter().(type) {
-	case *SQLServerAdapter:
-		return fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NOT NULL DROP TABLE %s", tableName, tableName)
-	default:
-		return fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
-	}
-}
-
-func buildColumnDefinition(adapter Adapter, field *Field) string {
-	switch adapter.(type) {
-	case *PostgreSQLAdapter:
-		return buildPostgresColumn(field)
-	case *MySQLAdapter:
-		return buildMySQLColumn(field)
-	case *SQLiteAdapter:
-		return buildSQLiteColumn(field)
-	case *SQLServerAdapter:
-		return buildSQLServerColumn(field)
-	default:
-		return buildGenericColumn(field)
-	}
-}
-
-func buildPostgresColumn(field *Field) string {
-	if field.Primary && field.Autoinc {
-		return fmt.Sprintf("%s SERIAL PRIMARY KEY", field.Name)
-	}
-	col := fmt.Sprintf("%s %s", field.Name, mapPostgresType(field.Type))
-	return applyColumnConstraints(col, field)
-}
-
-func buildMySQLColumn(field *Field) string {
-	if field.Primary && field.Autoinc {
-		return fmt.Sprintf("%s INT AUTO_INCREMENT PRIMARY KEY", field.Name)
-	}
-	col := fmt.Sprintf("%s %s", field.Name, mapMySQLType(field.Type))
-	return applyColumnConstraints(col, field)
-}
-
-func buildSQLiteColumn(field *Field) string {
-	if field.Primary && field.Autoinc {
-		return fmt.Sprintf("%s INTEGER PRIMARY KEY AUTOINCREMENT", field.Name)
-	}
-	col := fmt.Sprintf("%s %s", field.Name, mapSQLiteType(field.Type))
-	return applyColumnConstraints(col, field)
+	return newRepositoryDDLBuilder(repo).CreateTable(schema)
 }
 
-func buildSQLServerColumn(field *Field) string {
-	if field.Primary && field.Autoinc {
-		return fmt.Sprintf("%s INT IDENTITY(1,1) PRIMARY KEY", field.Name)
-	}
-	col := fmt.Sprintf("%s %s", field.Name, mapSQLServerType(field.Type))
-	return applyColumnConstraints(col, field)
-}
-
-func buildGenericColumn(field *Field) string {
-	col := fmt.Sprintf("%s %s", field.Name, "TEXT")
-	return applyColumnConstraints(col, field)
-}
-
-func applyColumnConstraints(column string, field *Field) string {
-	if !field.Null {
-		column += " NOT NULL"
+// createSchemaIndexes 创建 schema 声明的索引
+func createSchemaIndexes(ctx context.Context, repo *Repository, schema Schema) error {
+	stmts, err := newRepositoryDDLBuilder(repo).CreateIndexes(schema)
+	if err != nil {
+		return fmt.Errorf("failed to build indexes for %s: %w", schema.TableName(), err)
 	}
-	if field.Unique {
-		column += " UNIQUE"
+	for _, stmt := range stmts {
+		if _, err := repo.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create index on %s: %w", schema.TableName(), err)
+		}
 	}
-	return column
+	return nil
 }
 
-func mapPostgresType(fieldType FieldType) string {
-	switch fieldType {
-	case TypeString:
-		return "VARCHAR(255)"
-	case TypeInteger:
-		return "INTEGER"
-	case TypeFloat:
-		return "DOUBLE PRECISION"
-	case TypeBoolean:
-		return "BOOLEAN"
-	case TypeTime:
-		return "TIMESTAMP"
-	case TypeBinary:
-		return "BYTEA"
-	case TypeDecimal:
-		return "DECIMAL(18,2)"
-	case TypeJSON:
-		return "JSONB"
-	case TypeArray:
-		return "TEXT"
-	default:
-		return "TEXT"
-	}
-}
-
-func mapMySQLType(fieldType FieldType) string {
-	switch fieldType {
-	case TypeString:
-		return "VARCHAR(255)"
-	case TypeInteger:
-		return "INT"
-	case TypeFloat:
-		return "FLOAT"
-	case TypeBoolean:
-		return "TINYINT(1)"
-	case TypeTime:
-		return "DATETIME"
-	case TypeBinary:
-		return "LONGBLOB"
-	case TypeDecimal:
-		return "DECIMAL(18,2)"
-	case TypeJSON:
-		return "JSON"
-	case TypeArray:
-		return "TEXT"
-	default:
-		return "TEXT"
-	}
-}
-
-func mapSQLiteType(fieldType FieldType) string {
-	switch fieldType {
-	case TypeString:
-		return "TEXT"
-	case TypeInteger:
-		return "INTEGER"
-	case TypeFloat:
-		return "REAL"
-	case TypeBoolean:
-		return "INTEGER"
-	case TypeTime:
-		return "DATETIME"
-	case TypeBinary:
-		return "BLOB"
-	case TypeDecimal:
-		return "NUMERIC"
-	case TypeJSON:
-		return "TEXT"
-	case TypeArray:
-		return "TEXT"
-	default:
-		return "TEXT"
-	}
-}
-
-func mapSQLServerType(fieldType FieldType) string {
-	switch fieldType {
-	case TypeString:
-		return "NVARCHAR(255)"
-	case TypeInteger:
-		return "INT"
-	case TypeFloat:
-		return "FLOAT"
-	case TypeBoolean:
-		return "BIT"
-	case TypeTime:
-		return "DATETIME2"
-	case TypeBinary:
-		return "VARBINARY(MAX)"
-	case TypeDecimal:
-		return "DECIMAL(18,2)"
-	case TypeJSON:
-		return "NVARCHAR(MAX)"
-	case TypeArray:
-		return "NVARCHAR(MAX)"
-	default:
-		return "NVARCHAR(MAX)"
-	}
+func buildDropTableSQL(repo *Repository, tableName string) string {
+	return newRepositoryDDLBuilder(repo).DropTable(tableName)
 }
 
 // RawSQLMigration 原始 SQL 迁移
@@ -302,6 +270,7 @@ type RawSQLMigration struct {
 	upSQL    []string
 	downSQL  []string
 	adapter  string // 可选：指定特定的 adapter
+	replaces []string // Squash 生成时被替换的迁移版本
 }
 
 // NewRawSQLMigration 创建原始 SQL 迁移
@@ -331,6 +300,11 @@ func (m *RawSQLMigration) ForAdapter(adapter string) *RawSQLMigration {
 	return m
 }
 
+// Replaces 返回被该迁移替换的版本（仅 Squash 生成的迁移非空）
+func (m *RawSQLMigration) Replaces() []string {
+	return m.replaces
+}
+
 // Up 执行迁移
 func (m *RawSQLMigration) Up(ctx context.Context, repo *Repository) error {
 	for _, sql := range m.upSQL {
@@ -353,8 +327,11 @@ func (m *RawSQLMigration) Down(ctx context.Context, repo *Repository) error {
 
 // MigrationRunner 迁移运行器
 type MigrationRunner struct {
-	repo       *Repository
-	migrations []MigrationInterface
+	repo          *Repository
+	migrations    []MigrationInterface
+	transactional bool
+	strict        bool
+	warn          func(message string)
 }
 
 // NewMigrationRunner 创建迁移运行器
@@ -370,6 +347,26 @@ func (r *MigrationRunner) Register(migration MigrationInterface) {
 	r.migrations = append(r.migrations, migration)
 }
 
+// UseTransactions 每个迁移及其迁移记录在同一事务中执行
+// 方言不支持事务性 DDL（MySQL）时，多语句 DDL 迁移仍会逐条提交，运行器会发出警告；
+// DataMigration 由 Batch 逐批提交，不包在外层事务中
+func (r *MigrationRunner) UseTransactions(enabled bool) *MigrationRunner {
+	r.transactional = enabled
+	return r
+}
+
+// Strict 严格模式下，事务中执行多语句 DDL 迁移而方言不支持事务性 DDL 时直接返回错误
+func (r *MigrationRunner) Strict(enabled bool) *MigrationRunner {
+	r.strict = enabled
+	return r
+}
+
+// OnWarning 设置警告输出，默认打印到标准输出
+func (r *MigrationRunner) OnWarning(fn func(message string)) *MigrationRunner {
+	r.warn = fn
+	return r
+}
+
 // Up 执行所有待执行的迁移
 func (r *MigrationRunner) Up(ctx context.Context) error {
 	// 确保迁移日志表存在
@@ -387,15 +384,35 @@ func (r *MigrationRunner) Up(ctx context.Context) error {
 	for _, migration := range r.migrations {
 		version := migration.Version()
 		if _, exists := executed[version]; !exists {
-			fmt.Printf("Running migration %s: %s\n", version, migration.Description())
-			
-			if err := migration.Up(ctx, r.repo); err != nil {
-				return fmt.Errorf("migration %s failed: %w", version, err)
+			replaced, err := squashedVersions(migration, executed)
+			if err != nil {
+				return err
+			}
+
+			if err := r.checkTransactionalDDL(migration, true); err != nil {
+				return err
 			}
+
+			fmt.Printf("Running migration %s: %s\n", version, migration.Description())
 			
-			// 记录迁移
-			if err := r.recordMigration(ctx, version); err != nil {
-				return fmt.Errorf("failed to record migration %s: %w", version, err)
+			err = r.execute(ctx, migration, func(repo *Repository) error {
+				if err := migration.Up(ctx, repo); err != nil {
+					return fmt.Errorf("migration %s failed: %w", version, err)
+				}
+
+				// 记录迁移
+				if err := r.recordMigration(ctx, repo, version); err != nil {
+					return fmt.Errorf("failed to record migration %s: %w", version, err)
+				}
+				for _, v := range replaced {
+					if err := r.recordMigration(ctx, repo, v); err != nil {
+						return fmt.Errorf("failed to record migration %s: %w", v, err)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 			
 			fmt.Printf("✓ Migration %s completed\n", version)
@@ -430,16 +447,36 @@ func (r *MigrationRunner) Down(ctx context.Context) error {
 		return fmt.Errorf("migration %s not found in registered migrations", lastVersion)
 	}
 	
-	fmt.Printf("Rolling back migration %s: %s\n", lastVersion, targetMigration.Description())
-	
-	// 执行回滚
-	if err := targetMigration.Down(ctx, r.repo); err != nil {
-		return fmt.Errorf("rollback failed: %w", err)
+	if err := r.checkTransactionalDDL(targetMigration, false); err != nil {
+		return err
 	}
+
+	fmt.Printf("Rolling back migration %s: %s\n", lastVersion, targetMigration.Description())
 	
-	// 删除迁移记录
-	if err := r.removeMigrationRecord(ctx, lastVersion); err != nil {
-		return fmt.Errorf("failed to remove migration record: %w", err)
+	err = r.execute(ctx, targetMigration, func(repo *Repository) error {
+		// 执行回滚
+		if err := targetMigration.Down(ctx, repo); err != nil {
+			return fmt.Errorf("rollback failed: %w", err)
+		}
+
+		// 删除迁移记录
+		if err := r.removeMigrationRecord(ctx, repo, lastVersion); err != nil {
+			return fmt.Errorf("failed to remove migration record: %w", err)
+		}
+		if squashed, ok := targetMigration.(SquashedMigration); ok {
+			for _, v := range squashed.Replaces() {
+				if v == lastVersion {
+					continue
+				}
+				if err := r.removeMigrationRecord(ctx, repo, v); err != nil {
+					return fmt.Errorf("failed to remove migration record: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	
 	fmt.Printf("✓ Migration %s rolled back\n", lastVersion)
@@ -478,6 +515,29 @@ func (r *MigrationRunner) Status(ctx context.Context) ([]MigrationStatus, error)
 	return statuses, nil
 }
 
+// squashedVersions 检查压缩迁移替换的版本
+// 返回执行后需要一并记录的版本。压缩迁移沿用被替换范围的最后一个版本号，
+// 已迁移过该版本的数据库会自然跳过它；只执行过范围中一部分的数据库无法安全处理，返回错误
+func squashedVersions(migration MigrationInterface, executed map[string]time.Time) ([]string, error) {
+	squashed, ok := migration.(SquashedMigration)
+	if !ok {
+		return nil, nil
+	}
+
+	pending := make([]string, 0, len(squashed.Replaces()))
+	for _, v := range squashed.Replaces() {
+		if v == migration.Version() {
+			continue
+		}
+		if _, exists := executed[v]; exists {
+			return nil, fmt.Errorf("squashed migration %s: replaced migration %s is already applied; finish migrating to %s with the original migrations first",
+				migration.Version(), v, migration.Version())
+		}
+		pending = append(pending, v)
+	}
+	return pending, nil
+}
+
 // MigrationStatus 迁移状态
 type MigrationStatus struct {
 	Version     string
@@ -542,15 +602,92 @@ func (r *MigrationRunner) getLastExecutedVersion(ctx context.Context) (string, e
 }
 
 // recordMigration 记录迁移
-func (r *MigrationRunner) recordMigration(ctx context.Context, version string) error {
+func (r *MigrationRunner) recordMigration(ctx context.Context, repo *Repository, version string) error {
 	sql := "INSERT INTO schema_migrations (version) VALUES (?)"
-	_, err := r.repo.Exec(ctx, sql, version)
+	_, err := repo.Exec(ctx, sql, version)
 	return err
 }
 
 // removeMigrationRecord 删除迁移记录
-func (r *MigrationRunner) removeMigrationRecord(ctx context.Context, version string) error {
+func (r *MigrationRunner) removeMigrationRecord(ctx context.Context, repo *Repository, version string) error {
 	sql := "DELETE FROM schema_migrations WHERE version = ?"
-	_, err := r.repo.Exec(ctx, sql, version)
+	_, err := repo.Exec(ctx, sql, version)
 	return err
 }
+
+// execute 执行 fn，启用事务时迁移和迁移记录在同一事务中提交
+func (r *MigrationRunner) execute(ctx context.Context, migration MigrationInterface, fn func(repo *Repository) error) error {
+	// 数据迁移由 Batch 逐批提交事务，不能再包在外层事务中（不支持嵌套事务）
+	if _, ok := migration.(*DataMigration); !r.transactional || ok {
+		return fn(r.repo)
+	}
+	return r.repo.Transaction(ctx, nil, func(tx Tx) error {
+		return fn(r.repo.txRepository(tx))
+	})
+}
+
+// checkTransactionalDDL 在事务中执行多语句 DDL 迁移而方言会隐式提交 DDL 时发出警告，严格模式下返回错误
+func (r *MigrationRunner) checkTransactionalDDL(migration MigrationInterface, up bool) error {
+	if !r.transactional {
+		return nil
+	}
+	dialect := r.repo.sqlDialect()
+	if dialect == nil || dialect.SupportsTransactionalDDL() {
+		return nil
+	}
+	count := ddlStatementCount(r.repo, migration, up)
+	if count <= 1 {
+		return nil
+	}
+
+	message := fmt.Sprintf("migration %s runs %d DDL statements in a transaction, but %s commits DDL implicitly; a failure part-way leaves the earlier statements applied",
+		migration.Version(), count, dialect.Name())
+	if r.strict {
+		return fmt.Errorf("%s", message)
+	}
+	if r.warn != nil {
+		r.warn(message)
+	} else {
+		fmt.Printf("Warning: %s\n", message)
+	}
+	return nil
+}
+
+// ddlStatementCount 返回 SchemaMigration/RawSQLMigration 在该方向上执行的语句数，其他迁移返回 0
+func ddlStatementCount(repo *Repository, migration MigrationInterface, up bool) int {
+	switch m := migration.(type) {
+	case *RawSQLMigration:
+		if up {
+			return len(m.upSQL)
+		}
+		return len(m.downSQL)
+	case *SchemaMigration:
+		builder := newRepositoryDDLBuilder(repo)
+		count := 0
+		for _, schema := range m.createSchemas {
+			count++
+			if up {
+				stmts, _ := builder.CreateIndexes(schema)
+				count += len(stmts)
+			}
+		}
+		for _, add := range m.addColumns {
+			if !up {
+				count++
+				continue
+			}
+			stmts, err := builder.AddColumnStatements(add.table, add.field, add.backfill)
+			if err != nil {
+				count++
+				continue
+			}
+			count += len(stmts)
+		}
+		if !up {
+			count += len(m.dropSchemas)
+		}
+		return count + len(m.renames)
+	default:
+		return 0
+	}
+}
@@ -51,7 +51,7 @@ func (a *MongoAdapter) Connect(ctx context.Context, config *Config) error {
 
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
 	if err != nil {
-		return err
+		return redactError(err, "")
 	}
 
 	// 短连接测试
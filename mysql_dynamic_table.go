@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -140,6 +141,9 @@ func (h *MySQLDynamicTableHook) ListCreatedDynamicTables(ctx context.Context, co
 		ORDER BY TABLE_NAME
 	`
 
+	if !h.connected() {
+		return nil, ErrNotConnected
+	}
 	rows, err := h.adapter.Query(ctx, query, prefix)
 	if err != nil {
 		return nil, err
@@ -211,15 +215,8 @@ func (h *MySQLDynamicTableHook) handleAfterCreateCallback(db *gorm.DB, config *D
 
 // shouldCreateDynamicTable 判断是否应该创建动态表
 func (h *MySQLDynamicTableHook) shouldCreateDynamicTable(record interface{}, config *DynamicTableConfig) bool {
-	if config.TriggerCondition == "" {
-		// 如果没有条件，总是创建
-		return true
-	}
-
-	// 简单的条件判断：检查字段值
-	// 例如：TriggerCondition = "type = 'custom'"
-	// 这里只是示例，实际可能需要更复杂的条件评估
-	return true
+	// 没有条件时总是创建，否则按 TriggerCondition 对记录的字段值求值，例如 Eq("type", "custom")
+	return config.MatchesRecord(record)
 }
 
 // extractParamsFromRecord 从记录中提取参数
@@ -241,41 +238,13 @@ func (h *MySQLDynamicTableHook) extractParamsFromRecord(record interface{}, conf
 
 // createTable 创建动态表
 func (h *MySQLDynamicTableHook) createTable(ctx context.Context, config *DynamicTableConfig, tableName string) error {
-	var sql strings.Builder
-	sql.WriteString("CREATE TABLE IF NOT EXISTS ")
-	sql.WriteString(h.quoteIdentifier(tableName))
-	sql.WriteString(" (")
-
-	for i, field := range config.Fields {
-		if i > 0 {
-			sql.WriteString(", ")
-		}
-
-		sql.WriteString(h.quoteIdentifier(field.Name))
-		sql.WriteString(" ")
-		sql.WriteString(h.mapFieldType(field.Type))
-
-		if field.Autoinc && field.Primary {
-			sql.WriteString(" AUTO_INCREMENT")
-		}
-		if field.Primary {
-			sql.WriteString(" PRIMARY KEY")
-		}
-		if !field.Null {
-			sql.WriteString(" NOT NULL")
-		}
-		if field.Default != nil {
-			sql.WriteString(" DEFAULT ")
-			sql.WriteString(fmt.Sprint(field.Default))
-		}
-		if field.Unique {
-			sql.WriteString(" UNIQUE")
-		}
-	}
-
-	sql.WriteString(") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci")
+	sql := NewDDLBuilder(NewMySQLDialect()).CreateTable(config.ToSchema(tableName))
+	return h.executeSQL(ctx, sql+" ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci")
+}
 
-	return h.executeSQL(ctx, sql.String())
+// connected 适配器是否已连接
+func (h *MySQLDynamicTableHook) connected() bool {
+	return h.adapter != nil && h.adapter.sqlDB != nil
 }
 
 // tableExists 检查表是否存在
@@ -287,6 +256,9 @@ func (h *MySQLDynamicTableHook) tableExists(ctx context.Context, tableName strin
 		AND TABLE_NAME = ?
 	`
 
+	if !h.connected() {
+		return false, ErrNotConnected
+	}
 	var exists bool
 	row := h.adapter.QueryRow(ctx, query, tableName)
 	if err := row.Scan(&exists); err != nil {
@@ -300,9 +272,14 @@ func (h *MySQLDynamicTableHook) tableExists(ctx context.Context, tableName strin
 func (h *MySQLDynamicTableHook) generateTableName(config *DynamicTableConfig, params map[string]interface{}) string {
 	// 简单实现：使用 id 参数作为后缀
 	if id, ok := params["id"]; ok {
-		return fmt.Sprintf("%s_%v", config.TableName, id)
+		return h.shortenName(fmt.Sprintf("%s_%v", config.TableName, id))
 	}
-	return config.TableName
+	return h.shortenName(config.TableName)
+}
+
+// shortenName 按方言的标识符长度上限截断生成的名称
+func (h *MySQLDynamicTableHook) shortenName(name string) string {
+	return shortenIdentifier(name, NewMySQLDialect().MaxIdentifierLength())
 }
 
 // quoteIdentifier 引用标识符
@@ -310,41 +287,84 @@ func (h *MySQLDynamicTableHook) quoteIdentifier(name string) string {
 	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
 }
 
-// mapFieldType 将字段类型映射到 MySQL 类型
-func (h *MySQLDynamicTableHook) mapFieldType(fieldType FieldType) string {
-	switch fieldType {
-	case TypeString:
-		return "VARCHAR(255)"
-	case TypeInteger:
-		return "INT"
-	case TypeFloat:
-		return "FLOAT"
-	case TypeBoolean:
-		return "TINYINT(1)"
-	case TypeTime:
-		return "DATETIME"
-	case TypeBinary:
-		return "LONGBLOB"
-	case TypeDecimal:
-		return "DECIMAL(18,2)"
-	case TypeJSON:
-		return "JSON"
-	case TypeArray:
-		return "TEXT"
-	default:
-		return "TEXT"
-	}
-}
-
 // executeSQL 执行 SQL
 func (h *MySQLDynamicTableHook) executeSQL(ctx context.Context, sql string) error {
 	_, err := h.adapter.Exec(ctx, sql)
 	return err
 }
 
-// extractFieldValue 从结构体字段中提取值
+// extractFieldValue 从结构体（或结构体指针）的导出字段中提取值
+// 字段名大小写不敏感（ID、Id 都能匹配 "ID"）；查找 ID 时也会匹配带 gorm:"primaryKey" 或
+// db:",primary_key" 标签的字段，并会进入嵌入的结构体（例如 gorm.Model）。找不到时返回 nil
 func extractFieldValue(record interface{}, fieldName string) interface{} {
-	// 这是一个简化的实现，实际可能需要使用反射库
-	// 在这里仅作示例
-	return nil
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	field, ok := findStructField(v, fieldName)
+	if !ok {
+		return nil
+	}
+	return field.Interface()
+}
+
+// findStructField 按名称（大小写不敏感或 snake_case 列名）查找字段，外层字段优先于嵌入结构体中的字段
+func findStructField(v reflect.Value, fieldName string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.IsExported() && !sf.Anonymous && (strings.EqualFold(sf.Name, fieldName) || toSnakeCase(sf.Name) == fieldName) {
+			return v.Field(i), true
+		}
+	}
+
+	if strings.EqualFold(fieldName, "id") {
+		for i := 0; i < t.NumField(); i++ {
+			if sf := t.Field(i); sf.IsExported() && isPrimaryKeyTag(sf.Tag) {
+				return v.Field(i), true
+			}
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.Anonymous {
+			continue
+		}
+		embedded := v.Field(i)
+		if embedded.Kind() == reflect.Ptr {
+			if embedded.IsNil() {
+				continue
+			}
+			embedded = embedded.Elem()
+		}
+		if embedded.Kind() != reflect.Struct {
+			continue
+		}
+		if field, ok := findStructField(embedded, fieldName); ok {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// isPrimaryKeyTag 字段是否通过 gorm 或 db 标签声明为主键
+func isPrimaryKeyTag(tag reflect.StructTag) bool {
+	for _, opt := range strings.Split(tag.Get("gorm"), ";") {
+		key := strings.ToLower(strings.TrimSpace(strings.SplitN(opt, ":", 2)[0]))
+		if key == "primarykey" || key == "primary_key" {
+			return true
+		}
+	}
+	if dbTag := tag.Get("db"); dbTag != "" {
+		_, opts := parseDBTag(dbTag, "")
+		return opts.primaryKey
+	}
+	return false
 }
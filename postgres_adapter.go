@@ -90,7 +90,7 @@ func (a *PostgreSQLAdapter) Connect(ctx context.Context, config *Config) error {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return fmt.Errorf("failed to connect to PostgreSQL (host=%s, port=%d, user=%s, db=%s, ssl=%s): %w",
-			config.Host, config.Port, config.Username, config.Database, config.SSLMode, err)
+			config.Host, config.Port, config.Username, config.Database, config.SSLMode, redactError(err, password))
 	}
 
 	a.db = db
@@ -298,26 +298,33 @@ func (a *PostgreSQLAdapter) ListScheduledTasks(ctx context.Context) ([]*Schedule
 
 // PostgreSQLTx PostgreSQL 事务实现
 type PostgreSQLTx struct {
-	tx *sql.Tx
+	tx    *sql.Tx
+	state txState
 }
 
-// Commit 提交事务
+// Commit 提交事务，重复提交是空操作
 func (t *PostgreSQLTx) Commit(ctx context.Context) error {
-	return t.tx.Commit()
+	return t.state.finish(true, t.tx.Commit)
 }
 
-// Rollback 回滚事务
+// Rollback 回滚事务，事务已结束时是空操作
 func (t *PostgreSQLTx) Rollback(ctx context.Context) error {
-	return t.tx.Rollback()
+	return t.state.finish(false, t.tx.Rollback)
 }
 
 // Exec 在事务中执行
 func (t *PostgreSQLTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	return t.tx.ExecContext(ctx, query, args...)
 }
 
 // Query 在事务中查询
 func (t *PostgreSQLTx) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	return t.tx.QueryContext(ctx, query, args...)
 }
 
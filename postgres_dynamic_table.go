@@ -2,9 +2,13 @@ package db
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 // PostgreSQLDynamicTableHook PostgreSQL 动态表钩子实现
@@ -141,6 +145,9 @@ func (h *PostgreSQLDynamicTableHook) ListCreatedDynamicTables(ctx context.Contex
 		ORDER BY table_name
 	`
 
+	if !h.connected() {
+		return nil, ErrNotConnected
+	}
 	rows, err := h.adapter.Query(ctx, query, prefix+"%")
 	if err != nil {
 		return nil, err
@@ -172,6 +179,11 @@ func (h *PostgreSQLDynamicTableHook) createAutoTrigger(ctx context.Context, conf
 		return err
 	}
 
+	condition, err := h.buildTriggerCondition(config)
+	if err != nil {
+		return err
+	}
+
 	// 创建触发器
 	triggerSQL := fmt.Sprintf(`
 		CREATE TRIGGER %s
@@ -182,7 +194,7 @@ func (h *PostgreSQLDynamicTableHook) createAutoTrigger(ctx context.Context, conf
 	`,
 		h.quoteIdentifier(triggerName),
 		h.quoteIdentifier(config.ParentTable),
-		h.buildTriggerCondition(config),
+		condition,
 		h.quoteIdentifier(functionName),
 	)
 
@@ -192,7 +204,19 @@ func (h *PostgreSQLDynamicTableHook) createAutoTrigger(ctx context.Context, conf
 // generatePLPgSQLFunction 生成 PL/pgSQL 函数
 func (h *PostgreSQLDynamicTableHook) generatePLPgSQLFunction(config *DynamicTableConfig) string {
 	functionName := h.generateFunctionName(config)
-	tableTemplate := config.TableName + "_" + "NEW.id"
+	prefix := config.TableName + "_"
+	maxLen := NewPostgreSQLDialect().MaxIdentifierLength()
+
+	// 与 generateTableName 相同的截断规则：保留前 cut 字节，追加 "_" + md5 前 8 位。
+	// 截断点落在固定前缀内时直接在这里截好，否则落在 id 部分（假定 id 为 ASCII）
+	cut := maxLen - identifierHashLength - 1
+	head := h.quoteStringLiteral(prefix) + " || left(NEW.id::text, " + strconv.Itoa(cut-len(prefix)) + ")"
+	if len(prefix) > cut {
+		for cut > 0 && !utf8.RuneStart(prefix[cut]) {
+			cut--
+		}
+		head = h.quoteStringLiteral(prefix[:cut])
+	}
 
 	createTableSQL := h.generateCreateTableSQL(config, "v_table_name")
 
@@ -203,7 +227,10 @@ func (h *PostgreSQLDynamicTableHook) generatePLPgSQLFunction(config *DynamicTabl
 			v_table_name TEXT;
 		BEGIN
 			-- 生成表名
-			v_table_name := '%s_' || NEW.id;
+			v_table_name := %s || NEW.id;
+			IF octet_length(v_table_name) > %d THEN
+				v_table_name := %s || '_' || left(md5(v_table_name), %d);
+			END IF;
 
 			-- 检查表是否已存在
 			IF NOT EXISTS(
@@ -220,52 +247,41 @@ func (h *PostgreSQLDynamicTableHook) generatePLPgSQLFunction(config *DynamicTabl
 		$$ LANGUAGE plpgsql;
 	`,
 		h.quoteIdentifier(functionName),
-		strings.TrimSuffix(tableTemplate, "_NEW.id"),
+		h.quoteStringLiteral(prefix),
+		maxLen,
+		head,
+		identifierHashLength,
 		h.quoteStringLiteral(createTableSQL),
 	)
 }
 
 // generateCreateTableSQL 生成创建表的 SQL（用于函数中动态执行）
 func (h *PostgreSQLDynamicTableHook) generateCreateTableSQL(config *DynamicTableConfig, tableNameVar string) string {
-	var sql strings.Builder
-	sql.WriteString("CREATE TABLE ' || ")
-	sql.WriteString(tableNameVar)
-	sql.WriteString(" || ' (")
-
-	for i, field := range config.Fields {
-		if i > 0 {
-			sql.WriteString(", ")
-		}
-
-		sql.WriteString(h.quoteIdentifier(field.Name))
-		sql.WriteString(" ")
-		sql.WriteString(h.mapFieldType(field.Type))
+	columns := NewDDLBuilder(NewPostgreSQLDialect()).NativeArrays(true).ColumnDefinitions(config.ToSchema(config.TableName))
+	return "CREATE TABLE ' || " + tableNameVar + " || ' (" + strings.Join(columns, ", ") + ")"
+}
 
-		if field.Primary {
-			sql.WriteString(" PRIMARY KEY")
-		}
-		if field.Autoinc && field.Primary {
-			sql.WriteString(" SERIAL")
-		}
-		if !field.Null {
-			sql.WriteString(" NOT NULL")
-		}
-		if field.Default != nil {
-			sql.WriteString(" DEFAULT ")
-			sql.WriteString(fmt.Sprint(field.Default))
-		}
+// buildTriggerCondition 把 TriggerCondition 翻译为触发器的 WHEN 表达式
+// 触发器 WHEN 中不能使用绑定参数，值内联为字面量，列名加 NEW. 限定
+func (h *PostgreSQLDynamicTableHook) buildTriggerCondition(config *DynamicTableConfig) (string, error) {
+	if config.TriggerCondition == nil {
+		return "TRUE", nil
+	}
+	condition, err := translateConditionInline(triggerRowDialect{SQLDialect: NewPostgreSQLDialect(), row: "NEW"}, config.TriggerCondition)
+	if err != nil {
+		return "", fmt.Errorf("invalid trigger condition for %s: %w", config.TableName, err)
 	}
+	return condition, nil
+}
 
-	sql.WriteString(")")
-	return sql.String()
+// triggerRowDialect 用行变量（NEW / OLD）限定列名的方言包装
+type triggerRowDialect struct {
+	SQLDialect
+	row string
 }
 
-// buildTriggerCondition 构建触发器条件
-func (h *PostgreSQLDynamicTableHook) buildTriggerCondition(config *DynamicTableConfig) string {
-	if config.TriggerCondition != "" {
-		return "NEW." + config.TriggerCondition
-	}
-	return "TRUE"
+func (d triggerRowDialect) QuoteIdentifier(name string) string {
+	return d.row + "." + d.SQLDialect.QuoteIdentifier(name)
 }
 
 // dropTrigger 删除触发器
@@ -286,41 +302,12 @@ func (h *PostgreSQLDynamicTableHook) dropFunction(ctx context.Context, functionN
 
 // createTable 创建动态表
 func (h *PostgreSQLDynamicTableHook) createTable(ctx context.Context, config *DynamicTableConfig, tableName string) error {
-	var sql strings.Builder
-	sql.WriteString("CREATE TABLE ")
-	sql.WriteString(h.quoteIdentifier(tableName))
-	sql.WriteString(" (")
-
-	for i, field := range config.Fields {
-		if i > 0 {
-			sql.WriteString(", ")
-		}
-
-		sql.WriteString(h.quoteIdentifier(field.Name))
-		sql.WriteString(" ")
-		sql.WriteString(h.mapFieldType(field.Type))
-
-		if field.Autoinc {
-			sql.WriteString(" SERIAL")
-		}
-		if field.Primary {
-			sql.WriteString(" PRIMARY KEY")
-		}
-		if !field.Null {
-			sql.WriteString(" NOT NULL")
-		}
-		if field.Default != nil {
-			sql.WriteString(" DEFAULT ")
-			sql.WriteString(fmt.Sprint(field.Default))
-		}
-		if field.Unique {
-			sql.WriteString(" UNIQUE")
-		}
-	}
-
-	sql.WriteString(")")
+	return h.executeSQL(ctx, NewDDLBuilder(NewPostgreSQLDialect()).NativeArrays(true).CreateTable(config.ToSchema(tableName)))
+}
 
-	return h.executeSQL(ctx, sql.String())
+// connected 适配器是否已连接
+func (h *PostgreSQLDynamicTableHook) connected() bool {
+	return h.adapter != nil && h.adapter.sqlDB != nil
 }
 
 // tableExists 检查表是否存在
@@ -333,6 +320,9 @@ func (h *PostgreSQLDynamicTableHook) tableExists(ctx context.Context, tableName
 		)
 	`
 
+	if !h.connected() {
+		return false, ErrNotConnected
+	}
 	var exists bool
 	row := h.adapter.QueryRow(ctx, query, tableName)
 	if err := row.Scan(&exists); err != nil {
@@ -346,19 +336,35 @@ func (h *PostgreSQLDynamicTableHook) tableExists(ctx context.Context, tableName
 func (h *PostgreSQLDynamicTableHook) generateTableName(config *DynamicTableConfig, params map[string]interface{}) string {
 	// 简单实现：使用 id 参数作为后缀
 	if id, ok := params["id"]; ok {
-		return fmt.Sprintf("%s_%v", config.TableName, id)
+		return h.shortenTableName(fmt.Sprintf("%s_%v", config.TableName, id))
 	}
-	return config.TableName
+	return h.shortenTableName(config.TableName)
 }
 
 // generateFunctionName 生成函数名
 func (h *PostgreSQLDynamicTableHook) generateFunctionName(config *DynamicTableConfig) string {
-	return "fn_create_" + config.TableName + "_table"
+	return h.shortenName("fn_create_" + config.TableName + "_table")
 }
 
 // generateTriggerName 生成触发器名
 func (h *PostgreSQLDynamicTableHook) generateTriggerName(config *DynamicTableConfig) string {
-	return "trg_auto_" + config.TableName
+	return h.shortenName("trg_auto_" + config.TableName)
+}
+
+// shortenName 按方言的标识符长度上限截断生成的名称
+func (h *PostgreSQLDynamicTableHook) shortenName(name string) string {
+	return shortenIdentifier(name, NewPostgreSQLDialect().MaxIdentifierLength())
+}
+
+// shortenTableName 截断动态表名，哈希后缀使用 md5，
+// 触发器函数可以用 PostgreSQL 内置的 md5() 生成相同的表名
+func (h *PostgreSQLDynamicTableHook) shortenTableName(name string) string {
+	maxLen := NewPostgreSQLDialect().MaxIdentifierLength()
+	if len(name) <= maxLen {
+		return name
+	}
+	sum := md5.Sum([]byte(name))
+	return truncateWithSuffix(name, maxLen, hex.EncodeToString(sum[:])[:identifierHashLength])
 }
 
 // quoteIdentifier 引用标识符
@@ -371,32 +377,6 @@ func (h *PostgreSQLDynamicTableHook) quoteStringLiteral(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
 
-// mapFieldType 将字段类型映射到 PostgreSQL 类型
-func (h *PostgreSQLDynamicTableHook) mapFieldType(fieldType FieldType) string {
-	switch fieldType {
-	case TypeString:
-		return "VARCHAR(255)"
-	case TypeInteger:
-		return "INTEGER"
-	case TypeFloat:
-		return "FLOAT"
-	case TypeBoolean:
-		return "BOOLEAN"
-	case TypeTime:
-		return "TIMESTAMP"
-	case TypeBinary:
-		return "BYTEA"
-	case TypeDecimal:
-		return "DECIMAL(18,2)"
-	case TypeJSON:
-		return "JSONB"
-	case TypeArray:
-		return "TEXT[]"
-	default:
-		return "TEXT"
-	}
-}
-
 // executeSQL 执行 SQL
 func (h *PostgreSQLDynamicTableHook) executeSQL(ctx context.Context, sql string) error {
 	_, err := h.adapter.Exec(ctx, sql)
@@ -9,9 +9,10 @@ import (
 
 // QueryBuilder 查询构建器 (使用 Changeset 进行数据操作)
 type QueryBuilder struct {
-	schema  Schema
-	repo    *Repository
-	context context.Context
+	schema    Schema
+	repo      *Repository
+	context   context.Context
+	returning []string
 }
 
 // NewQueryBuilder 创建查询构建器
@@ -40,12 +41,17 @@ func (qb *QueryBuilder) Insert(cs *Changeset) (sql.Result, error) {
 	// 确保所有数据都标记为变更（用于插入）
 	cs.ForceChanges()
 
+	changes, err := serializeChanges(qb.schema, cs.Changes())
+	if err != nil {
+		return nil, err
+	}
+
 	// 构建 INSERT SQL
 	fields := make([]string, 0)
 	placeholders := make([]string, 0)
 	values := make([]interface{}, 0)
 
-	for fieldName, value := range cs.Changes() {
+	for fieldName, value := range changes {
 		fields = append(fields, fieldName)
 		placeholders = append(placeholders, "?")
 		values = append(values, value)
@@ -65,13 +71,25 @@ func (qb *QueryBuilder) Insert(cs *Changeset) (sql.Result, error) {
 
 // Update 更新数据
 func (qb *QueryBuilder) Update(cs *Changeset, whereClause string, whereArgs ...interface{}) (sql.Result, error) {
+	sql, values, err := qb.buildUpdate(cs, whereClause, whereArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return qb.repo.Exec(qb.context, sql, values...)
+}
+
+// buildUpdate 构建 UPDATE SQL
+func (qb *QueryBuilder) buildUpdate(cs *Changeset, whereClause string, whereArgs ...interface{}) (string, []interface{}, error) {
 	if !cs.IsValid() {
-		return nil, fmt.Errorf("changeset 验证失败: %v", cs.Errors())
+		return "", nil, fmt.Errorf("changeset 验证失败: %v", cs.Errors())
 	}
 
-	changes := cs.Changes()
+	changes, err := serializeChanges(qb.schema, cs.Changes())
+	if err != nil {
+		return "", nil, err
+	}
 	if len(changes) == 0 {
-		return nil, fmt.Errorf("没有要更新的字段")
+		return "", nil, fmt.Errorf("没有要更新的字段")
 	}
 
 	// 构建 UPDATE SQL
@@ -98,7 +116,7 @@ func (qb *QueryBuilder) Update(cs *Changeset, whereClause string, whereArgs ...i
 		sql += " WHERE " + whereClause
 	}
 
-	return qb.repo.Exec(qb.context, sql, values...)
+	return sql, values, nil
 }
 
 // UpdateByID 按 ID 更新数据
@@ -125,6 +143,74 @@ func (qb *QueryBuilder) DeleteByID(id interface{}) (sql.Result, error) {
 	return qb.Delete("id = ?", id)
 }
 
+// ==================== RETURNING ====================
+
+// ReturningResult 带 RETURNING 的 UPDATE/DELETE 结果
+type ReturningResult struct {
+	RowsAffected int64
+	Rows         []map[string]interface{} // 受影响行，不支持 RETURNING 的数据库为 nil
+}
+
+// Returning 设置 UpdateReturning/DeleteReturning 返回的列，默认 "*"
+func (qb *QueryBuilder) Returning(columns ...string) *QueryBuilder {
+	qb.returning = columns
+	return qb
+}
+
+// UpdateReturning 更新数据并返回受影响的行
+// PostgreSQL 使用 UPDATE ... RETURNING，其他数据库只返回受影响行数
+func (qb *QueryBuilder) UpdateReturning(cs *Changeset, whereClause string, whereArgs ...interface{}) (*ReturningResult, error) {
+	sql, values, err := qb.buildUpdate(cs, whereClause, whereArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return qb.execReturning(sql, values)
+}
+
+// DeleteReturning 删除数据并返回被删除的行
+// PostgreSQL/SQLite 使用 DELETE ... RETURNING，其他数据库只返回受影响行数
+func (qb *QueryBuilder) DeleteReturning(whereClause string, whereArgs ...interface{}) (*ReturningResult, error) {
+	sql := fmt.Sprintf("DELETE FROM %s", qb.schema.TableName())
+	if whereClause != "" {
+		sql += " WHERE " + whereClause
+	} else {
+		whereArgs = nil
+	}
+	return qb.execReturning(sql, whereArgs)
+}
+
+// execReturning 执行写语句，方言支持时追加 RETURNING 并把返回行扫描为 map
+func (qb *QueryBuilder) execReturning(sql string, args []interface{}) (*ReturningResult, error) {
+	dialect := qb.repo.sqlDialect()
+	if dialect == nil || !dialect.SupportsReturning() {
+		result, err := qb.repo.Exec(qb.context, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		return &ReturningResult{RowsAffected: affected}, nil
+	}
+
+	columns := "*"
+	if len(qb.returning) > 0 {
+		columns = strings.Join(qb.returning, ", ")
+	}
+	rows, err := qb.repo.Query(qb.context, sql+" RETURNING "+columns, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records, err := ScanMaps(rows, qb.schema)
+	if err != nil {
+		return nil, err
+	}
+	return &ReturningResult{RowsAffected: int64(len(records)), Rows: records}, nil
+}
+
 // SoftDelete 软删除数据 (仅适用于有 deleted_at 字段的表)
 func (qb *QueryBuilder) SoftDelete(whereClause string, whereArgs ...interface{}) (sql.Result, error) {
 	schema := NewBaseSchema(qb.schema.TableName())
@@ -167,7 +253,7 @@ func (qb *QueryBuilder) SelectAll() (*sql.Rows, error) {
 }
 
 // SelectByID 按 ID 查询单条数据
-func (qb *QueryBuilder) SelectByID(id interface{}) (*sql.Row, error) {
+func (qb *QueryBuilder) SelectByID(id interface{}) (*Row, error) {
 	sql := fmt.Sprintf(
 		"SELECT * FROM %s WHERE id = ? LIMIT 1",
 		qb.schema.TableName(),
@@ -176,7 +262,7 @@ func (qb *QueryBuilder) SelectByID(id interface{}) (*sql.Row, error) {
 }
 
 // SelectOne 查询单条数据
-func (qb *QueryBuilder) SelectOne(whereClause string, whereArgs ...interface{}) (*sql.Row, error) {
+func (qb *QueryBuilder) SelectOne(whereClause string, whereArgs ...interface{}) (*Row, error) {
 	sql := fmt.Sprintf(
 		"SELECT * FROM %s WHERE %s LIMIT 1",
 		qb.schema.TableName(),
@@ -210,7 +296,7 @@ func (qb *QueryBuilder) Transaction(fn func(*QueryBuilder) error) error {
 	// 创建事务内的查询构建器
 	txQB := &QueryBuilder{
 		schema:  qb.schema,
-		repo:    &Repository{adapter: &txAdapter{tx: tx}},
+		repo:    qb.repo.txRepository(tx),
 		context: qb.context,
 	}
 
@@ -226,7 +312,8 @@ func (qb *QueryBuilder) Transaction(fn func(*QueryBuilder) error) error {
 
 // txAdapter 事务适配器
 type txAdapter struct {
-	tx Tx
+	tx       Tx
+	provider QueryConstructorProvider // 外层适配器的查询构造器，为空时按 MySQL 处理
 }
 
 func (ta *txAdapter) Connect(ctx context.Context, config *Config) error {
@@ -274,6 +361,9 @@ func (ta *txAdapter) ListScheduledTasks(ctx context.Context) ([]*ScheduledTaskSt
 }
 
 func (ta *txAdapter) GetQueryBuilderProvider() QueryConstructorProvider {
+	if ta.provider != nil {
+		return ta.provider
+	}
 	return NewDefaultSQLQueryConstructorProvider(NewMySQLDialect())
 }
 
@@ -365,7 +455,7 @@ func (qc *QueryChain) OrderBy(orderBy string) *QueryChain {
 }
 
 // First 查询第一条
-func (qc *QueryChain) First() (*sql.Row, error) {
+func (qc *QueryChain) First() (*Row, error) {
 	sql := fmt.Sprintf("SELECT * FROM %s", qc.builder.schema.TableName())
 
 	if qc.whereSQL != "" {
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func returningTestSchema() *BaseSchema {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	return schema
+}
+
+// TestQueryBuilderReturningPostgreSQL 测试 PostgreSQL 下 UPDATE/DELETE ... RETURNING 的返回行被解码
+func TestQueryBuilderReturningPostgreSQL(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{
+			columns: []string{"id", "status"},
+			values:  [][]driver.Value{{int64(1), []byte("shipped")}, {int64(3), []byte("shipped")}},
+		}, nil
+	}
+
+	schema := returningTestSchema()
+	cs := NewChangeset(schema).Cast(map[string]interface{}{"status": "shipped"})
+	result, err := NewQueryBuilder(schema, repo).Returning("*").UpdateReturning(cs, "status = ?", "paid")
+	if err != nil {
+		t.Fatalf("UpdateReturning failed: %v", err)
+	}
+
+	stmts := fake.Statements()
+	if last := stmts[len(stmts)-1]; last != "UPDATE orders SET status = ? WHERE status = ? RETURNING *" {
+		t.Errorf("Unexpected statement: %s", last)
+	}
+	want := []map[string]interface{}{
+		{"id": int64(1), "status": "shipped"},
+		{"id": int64(3), "status": "shipped"},
+	}
+	if result.RowsAffected != 2 || !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	result, err = NewQueryBuilder(schema, repo).Returning("id").DeleteReturning("status = ?", "cancelled")
+	if err != nil {
+		t.Fatalf("DeleteReturning failed: %v", err)
+	}
+	stmts = fake.Statements()
+	if last := stmts[len(stmts)-1]; last != "DELETE FROM orders WHERE status = ? RETURNING id" {
+		t.Errorf("Unexpected statement: %s", last)
+	}
+	if len(result.Rows) != 2 {
+		t.Errorf("Expected deleted rows to be returned, got %+v", result)
+	}
+}
+
+// TestQueryBuilderReturningFallback 测试不支持 RETURNING 的数据库只返回受影响行数
+func TestQueryBuilderReturningFallback(t *testing.T) {
+	for _, dialect := range []SQLDialect{NewMySQLDialect(), NewSQLServerDialect()} {
+		repo, fake := newFakeRepository(dialect)
+		schema := returningTestSchema()
+
+		result, err := NewQueryBuilder(schema, repo).Returning("*").DeleteReturning("id = ?", 1)
+		if err != nil {
+			t.Fatalf("%s: DeleteReturning failed: %v", dialect.Name(), err)
+		}
+		if result.RowsAffected != 1 || result.Rows != nil {
+			t.Errorf("%s: expected rows-affected only, got %+v", dialect.Name(), result)
+		}
+		for _, stmt := range fake.Statements() {
+			if strings.Contains(stmt, "RETURNING") {
+				t.Errorf("%s: unexpected RETURNING in %s", dialect.Name(), stmt)
+			}
+		}
+		repo.Close()
+	}
+}
@@ -3,7 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // ==================== SQL Query Builder 实现 ====================
@@ -14,17 +16,60 @@ import (
 type SQLQueryConstructor struct {
 	schema       Schema
 	dialect      SQLDialect
-	selectedCols []string
+	selectedCols []selectItem
+	joins        []joinClause
 	conditions   []Condition
 	orderBys     []OrderBy
+	groupBys     []string
+	distinct     bool
+	distinctOn   []string
 	limitVal     *int
 	offsetVal    *int
+
+	strictGrouping  bool
+	strictColumns   bool
+	keywordCase     KeywordCase
+	complexityLimit *ComplexityLimit
+
+	// 键集分页游标，Build 时展开为 WHERE 条件
+	keyset         *keysetCursor
+	autoTiebreaker bool
+
+	// 链式调用中产生的错误，在 Build 时返回
+	err error
+}
+
+// joinClause JOIN 子句：普通表连接（table）或 LEFT JOIN LATERAL 子查询（sub + alias）
+type joinClause struct {
+	kind  string // INNER JOIN、LEFT JOIN、CROSS JOIN、LEFT JOIN LATERAL
+	table string
+	sub   *SQLQueryConstructor
+	alias string
+	on    Condition
+}
+
+// selectItem SELECT 列表中的一项：普通列（按方言转义）或原样输出的表达式
+type selectItem struct {
+	column string
+	expr   string
+	alias  string // 表达式的结果列名（未设置别名时为空）
+}
+
+// render 渲染选择项
+func (s selectItem) render(dialect SQLDialect) string {
+	if s.expr != "" {
+		return s.expr
+	}
+	return dialect.QuoteIdentifier(s.column)
 }
 
 // OrderBy 排序条件
 type OrderBy struct {
 	Field     string
 	Direction string // "ASC" | "DESC"
+
+	raw       bool   // OrderByRaw 添加的表达式，原样输出
+	collation string // OrderByCollate 指定的排序规则
 }
 
 // SQLDialect SQL 方言接口
@@ -47,6 +92,51 @@ type SQLDialect interface {
 	
 	// 转换条件为 SQL（可选的方言特定优化）
 	TranslateCondition(condition Condition, argIndex *int) (string, []interface{}, error)
+
+	// 当前时间函数（如 CURRENT_TIMESTAMP、NOW()）
+	CurrentTimestamp() string
+
+	// 是否支持原生数组（ARRAY[...]、ANY/ALL）
+	SupportsArrays() bool
+
+	// 是否支持空间函数（PostGIS 的 ST_Within、ST_MakeEnvelope 等）
+	SupportsGeo() bool
+
+	// 是否支持服务端游标（DECLARE ... CURSOR / FETCH）
+	SupportsServerCursors() bool
+
+	// 标识符最大字节数，超过部分会被数据库截断（<= 0 表示不限制）
+	MaxIdentifierLength() int
+
+	// 是否支持部分索引（CREATE INDEX ... WHERE ...）
+	SupportsPartialIndexes() bool
+
+	// 是否支持 INSERT/UPDATE/DELETE ... RETURNING
+	SupportsReturning() bool
+
+	// 是否支持行值比较（(a, b) IN ((?, ?), ...)）
+	SupportsRowValues() bool
+
+	// 单条语句允许的最大绑定参数个数（<= 0 表示不限制）
+	MaxParameters() int
+
+	// 生成大小写不敏感的模糊匹配表达式，column 已转义
+	ILikeExpr(column, placeholder string) string
+
+	// DDL 是否可以在事务中执行并随事务回滚（MySQL 执行 DDL 会隐式提交）
+	SupportsTransactionalDDL() bool
+
+	// 是否支持 SELECT DISTINCT ON (...)
+	SupportsDistinctOn() bool
+
+	// 是否支持 LATERAL 子查询连接
+	SupportsLateral() bool
+
+	// 提取 JSON 列中 path（$.a.b[0] 形式，已校验）对应的文本值，column 已转义
+	JSONExtractExpr(column, path string) string
+
+	// 生成 UPSERT 冲突处理子句（ON CONFLICT / ON DUPLICATE KEY UPDATE）
+	GenerateUpsert(conflictColumns []string, updateColumns []string) (string, error)
 }
 
 // DefaultSQLDialect 默认 SQL 方言（MySQL 兼容）
@@ -88,8 +178,97 @@ func (d *DefaultSQLDialect) GenerateLimitOffset(limit *int, offset *int) string
 	return strings.Join(parts, " ")
 }
 
+func (d *DefaultSQLDialect) CurrentTimestamp() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (d *DefaultSQLDialect) SupportsArrays() bool {
+	return false
+}
+
+func (d *DefaultSQLDialect) SupportsGeo() bool {
+	return false
+}
+
+func (d *DefaultSQLDialect) SupportsServerCursors() bool {
+	return false
+}
+
+// MaxIdentifierLength MySQL 标识符最长 64 字节
+func (d *DefaultSQLDialect) MaxIdentifierLength() int {
+	return 64
+}
+
+func (d *DefaultSQLDialect) SupportsPartialIndexes() bool {
+	return false
+}
+
+func (d *DefaultSQLDialect) SupportsReturning() bool {
+	return false
+}
+
+func (d *DefaultSQLDialect) SupportsRowValues() bool {
+	return true
+}
+
+// MaxParameters MySQL 预处理语句最多 65535 个参数
+func (d *DefaultSQLDialect) MaxParameters() int {
+	return 65535
+}
+
+// MySQL 的 DDL 会隐式提交当前事务
+func (d *DefaultSQLDialect) SupportsTransactionalDDL() bool {
+	return false
+}
+
+func (d *DefaultSQLDialect) SupportsDistinctOn() bool {
+	return false
+}
+
+// MySQL 8.0.14 之前不支持 LATERAL，按不支持处理
+func (d *DefaultSQLDialect) SupportsLateral() bool {
+	return false
+}
+
+// JSONExtractExpr MySQL 的 JSON_EXTRACT 返回 JSON 值，需要 JSON_UNQUOTE 才能与字符串比较
+func (d *DefaultSQLDialect) JSONExtractExpr(column, path string) string {
+	return "JSON_UNQUOTE(JSON_EXTRACT(" + column + ", " + d.QuoteValue(path) + "))"
+}
+
+// ILikeExpr 没有 ILIKE 的数据库两边都转为小写后比较
+func (d *DefaultSQLDialect) ILikeExpr(column, placeholder string) string {
+	return "LOWER(" + column + ") LIKE LOWER(" + placeholder + ")"
+}
+
+// GenerateUpsert MySQL 风格：ON DUPLICATE KEY UPDATE，冲突目标由数据库按任一唯一键判断
+func (d *DefaultSQLDialect) GenerateUpsert(conflictColumns []string, updateColumns []string) (string, error) {
+	if len(updateColumns) == 0 {
+		// 没有要更新的列时用无副作用的赋值实现 DO NOTHING
+		if len(conflictColumns) == 0 {
+			return "", fmt.Errorf("upsert without update columns requires at least one conflict column")
+		}
+		col := d.QuoteIdentifier(conflictColumns[0])
+		return "ON DUPLICATE KEY UPDATE " + col + " = " + col, nil
+	}
+	sets := make([]string, len(updateColumns))
+	for i, col := range updateColumns {
+		quoted := d.QuoteIdentifier(col)
+		sets[i] = quoted + " = VALUES(" + quoted + ")"
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), nil
+}
+
 func (d *DefaultSQLDialect) TranslateCondition(condition Condition, argIndex *int) (string, []interface{}, error) {
-	translator := &DefaultSQLTranslator{dialect: d}
+	return translateWithArgIndex(d, condition, argIndex)
+}
+
+// translateWithArgIndex 使用共享的参数序号翻译条件，argIndex 为 nil 时从 1 开始编号
+func translateWithArgIndex(dialect SQLDialect, condition Condition, argIndex *int) (string, []interface{}, error) {
+	if argIndex == nil {
+		start := 1
+		argIndex = &start
+	}
+	translator := &DefaultSQLTranslator{dialect: dialect, argIndex: argIndex}
 	return translator.TranslateCondition(condition)
 }
 
@@ -117,6 +296,70 @@ func (d *PostgreSQLDialect) GetPlaceholder(index int) string {
 	return fmt.Sprintf("$%d", index)
 }
 
+func (d *PostgreSQLDialect) CurrentTimestamp() string {
+	return "NOW()"
+}
+
+func (d *PostgreSQLDialect) SupportsArrays() bool {
+	return true
+}
+
+// SupportsGeo PostgreSQL 方言假定已安装 PostGIS 扩展
+func (d *PostgreSQLDialect) SupportsGeo() bool {
+	return true
+}
+
+func (d *PostgreSQLDialect) SupportsServerCursors() bool {
+	return true
+}
+
+// MaxIdentifierLength PostgreSQL 的 NAMEDATALEN 为 64，标识符最长 63 字节
+func (d *PostgreSQLDialect) MaxIdentifierLength() int {
+	return 63
+}
+
+func (d *PostgreSQLDialect) SupportsPartialIndexes() bool {
+	return true
+}
+
+func (d *PostgreSQLDialect) SupportsReturning() bool {
+	return true
+}
+
+func (d *PostgreSQLDialect) SupportsTransactionalDDL() bool {
+	return true
+}
+
+func (d *PostgreSQLDialect) SupportsDistinctOn() bool {
+	return true
+}
+
+func (d *PostgreSQLDialect) SupportsLateral() bool {
+	return true
+}
+
+// JSONExtractExpr 单个键使用 ->>，嵌套路径使用 #>> '{a,b}'，都返回 text
+func (d *PostgreSQLDialect) JSONExtractExpr(column, path string) string {
+	segments := jsonPathSegments(path)
+	if len(segments) == 1 && !isJSONIndex(path) {
+		return column + "->>" + d.QuoteValue(segments[0])
+	}
+	return column + "#>>" + d.QuoteValue("{"+strings.Join(segments, ",")+"}")
+}
+
+func (d *PostgreSQLDialect) ILikeExpr(column, placeholder string) string {
+	return column + " ILIKE " + placeholder
+}
+
+// TranslateCondition 需要覆写，否则嵌入的 DefaultSQLDialect 会使用 ? 占位符和反引号
+func (d *PostgreSQLDialect) TranslateCondition(condition Condition, argIndex *int) (string, []interface{}, error) {
+	return translateWithArgIndex(d, condition, argIndex)
+}
+
+func (d *PostgreSQLDialect) GenerateUpsert(conflictColumns []string, updateColumns []string) (string, error) {
+	return generateOnConflict(d, conflictColumns, updateColumns)
+}
+
 // MySQL 方言
 type MySQLDialect struct {
 	DefaultSQLDialect
@@ -145,6 +388,77 @@ func NewSQLiteDialect() *SQLiteDialect {
 	}
 }
 
+// SQLite 不限制标识符长度
+func (d *SQLiteDialect) MaxIdentifierLength() int {
+	return 0
+}
+
+// SQLite 3.8+ 支持部分索引
+func (d *SQLiteDialect) SupportsPartialIndexes() bool {
+	return true
+}
+
+// SQLite 按不支持行值 IN 处理，TupleIn 展开为 OR 连接的 AND 组
+func (d *SQLiteDialect) SupportsRowValues() bool {
+	return false
+}
+
+// SQLite 3.32+ 的 SQLITE_MAX_VARIABLE_NUMBER 默认为 32766
+func (d *SQLiteDialect) MaxParameters() int {
+	return 32766
+}
+
+// SQLite 3.35+ 支持 RETURNING
+func (d *SQLiteDialect) SupportsReturning() bool {
+	return true
+}
+
+func (d *SQLiteDialect) SupportsTransactionalDDL() bool {
+	return true
+}
+
+func (d *SQLiteDialect) JSONExtractExpr(column, path string) string {
+	return "json_extract(" + column + ", " + d.QuoteValue(path) + ")"
+}
+
+func (d *SQLiteDialect) TranslateCondition(condition Condition, argIndex *int) (string, []interface{}, error) {
+	return translateWithArgIndex(d, condition, argIndex)
+}
+
+// SQLite 3.24+ 支持 PostgreSQL 风格的 ON CONFLICT
+func (d *SQLiteDialect) GenerateUpsert(conflictColumns []string, updateColumns []string) (string, error) {
+	return generateOnConflict(d, conflictColumns, updateColumns)
+}
+
+// generateOnConflict 生成 ON CONFLICT (...) DO UPDATE SET ... / DO NOTHING
+func generateOnConflict(d SQLDialect, conflictColumns []string, updateColumns []string) (string, error) {
+	var clause strings.Builder
+	clause.WriteString("ON CONFLICT")
+	if len(conflictColumns) > 0 {
+		quoted := make([]string, len(conflictColumns))
+		for i, col := range conflictColumns {
+			quoted[i] = d.QuoteIdentifier(col)
+		}
+		clause.WriteString(" (" + strings.Join(quoted, ", ") + ")")
+	}
+
+	if len(updateColumns) == 0 {
+		clause.WriteString(" DO NOTHING")
+		return clause.String(), nil
+	}
+	if len(conflictColumns) == 0 {
+		return "", fmt.Errorf("ON CONFLICT DO UPDATE requires conflict columns on %s", d.Name())
+	}
+
+	sets := make([]string, len(updateColumns))
+	for i, col := range updateColumns {
+		quoted := d.QuoteIdentifier(col)
+		sets[i] = quoted + " = EXCLUDED." + quoted
+	}
+	clause.WriteString(" DO UPDATE SET " + strings.Join(sets, ", "))
+	return clause.String(), nil
+}
+
 // SQL Server 方言
 type SQLServerDialect struct {
 	nextParamIndex int
@@ -202,9 +516,78 @@ func (d *SQLServerDialect) GenerateLimitOffset(limit *int, offset *int) string {
 	return clause
 }
 
+func (d *SQLServerDialect) CurrentTimestamp() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (d *SQLServerDialect) SupportsArrays() bool {
+	return false
+}
+
+func (d *SQLServerDialect) SupportsGeo() bool {
+	return false
+}
+
+func (d *SQLServerDialect) SupportsServerCursors() bool {
+	return false
+}
+
+// MaxIdentifierLength SQL Server 标识符最长 128 个字符
+func (d *SQLServerDialect) MaxIdentifierLength() int {
+	return 128
+}
+
+// SQL Server 称为筛选索引（filtered index）
+func (d *SQLServerDialect) SupportsPartialIndexes() bool {
+	return true
+}
+
+// SQL Server 使用 OUTPUT 子句而不是 RETURNING
+func (d *SQLServerDialect) SupportsReturning() bool {
+	return false
+}
+
+// SQL Server 不支持行值比较
+func (d *SQLServerDialect) SupportsRowValues() bool {
+	return false
+}
+
+// SQL Server 单个请求最多 2100 个参数
+func (d *SQLServerDialect) MaxParameters() int {
+	return 2100
+}
+
+// SQL Server 的 CREATE/ALTER/DROP TABLE 可以随事务回滚
+func (d *SQLServerDialect) SupportsTransactionalDDL() bool {
+	return true
+}
+
+func (d *SQLServerDialect) SupportsDistinctOn() bool {
+	return false
+}
+
+// SQL Server 使用 OUTER APPLY 而不是 LATERAL
+func (d *SQLServerDialect) SupportsLateral() bool {
+	return false
+}
+
+// JSONExtractExpr JSON_VALUE 返回标量的文本值
+func (d *SQLServerDialect) JSONExtractExpr(column, path string) string {
+	return "JSON_VALUE(" + column + ", " + d.QuoteValue(path) + ")"
+}
+
+// SQL Server 的大小写敏感性取决于排序规则，统一转为小写后比较
+func (d *SQLServerDialect) ILikeExpr(column, placeholder string) string {
+	return "LOWER(" + column + ") LIKE LOWER(" + placeholder + ")"
+}
+
+// SQL Server 没有 INSERT 级别的冲突处理，需要使用 MERGE
+func (d *SQLServerDialect) GenerateUpsert(conflictColumns []string, updateColumns []string) (string, error) {
+	return "", fmt.Errorf("upsert is not supported by the sqlserver dialect, use MERGE instead")
+}
+
 func (d *SQLServerDialect) TranslateCondition(condition Condition, argIndex *int) (string, []interface{}, error) {
-	translator := &DefaultSQLTranslator{dialect: d}
-	return translator.TranslateCondition(condition)
+	return translateWithArgIndex(d, condition, argIndex)
 }
 
 // ==================== SQLQueryBuilder 实现 ====================
@@ -220,7 +603,7 @@ func NewSQLQueryConstructor(schema Schema, dialect SQLDialect) *SQLQueryConstruc
 	return &SQLQueryConstructor{
 		schema:       schema,
 		dialect:      dialect,
-		selectedCols: make([]string, 0),
+		selectedCols: make([]selectItem, 0),
 		conditions:   make([]Condition, 0),
 		orderBys:     make([]OrderBy, 0),
 	}
@@ -235,6 +618,7 @@ func (qb *SQLQueryConstructor) Where(condition Condition) QueryConstructor {
 }
 
 // WhereAll 添加多个 AND 条件
+// 没有条件时按空合取恒真处理，不添加任何谓词
 func (qb *SQLQueryConstructor) WhereAll(conditions ...Condition) QueryConstructor {
 	if len(conditions) > 0 {
 		qb.conditions = append(qb.conditions, And(conditions...))
@@ -243,24 +627,78 @@ func (qb *SQLQueryConstructor) WhereAll(conditions ...Condition) QueryConstructo
 }
 
 // WhereAny 添加多个 OR 条件
+// 没有条件时按空析取恒假处理，添加 1=0，查询不返回任何行
 func (qb *SQLQueryConstructor) WhereAny(conditions ...Condition) QueryConstructor {
-	if len(conditions) > 0 {
-		qb.conditions = append(qb.conditions, Or(conditions...))
+	if len(conditions) == 0 {
+		qb.conditions = append(qb.conditions, Raw("1=0"))
+		return qb
+	}
+	qb.conditions = append(qb.conditions, Or(conditions...))
+	return qb
+}
+
+// CombineExisting 把已添加的条件合并为一组，再用 connective（"AND" / "OR"）与 conds 组合
+// 例如已有 a、b 时 CombineExisting("OR", c) 生成 ((a AND b) OR c)；connective 不区分大小写，其他值在 Build 时报错
+func (qb *SQLQueryConstructor) CombineExisting(connective string, conds ...Condition) *SQLQueryConstructor {
+	connective = strings.ToUpper(strings.TrimSpace(connective))
+	if connective != "AND" && connective != "OR" {
+		qb.setErr(fmt.Errorf("invalid connective %q, expected AND or OR", connective))
+		return qb
+	}
+
+	combined := make([]Condition, 0, len(conds)+1)
+	switch len(qb.conditions) {
+	case 0:
+	case 1:
+		combined = append(combined, qb.conditions[0])
+	default:
+		combined = append(combined, And(qb.conditions...))
+	}
+	for _, cond := range conds {
+		if cond != nil {
+			combined = append(combined, cond)
+		}
+	}
+	if len(combined) == 0 {
+		return qb
+	}
+
+	if connective == "OR" {
+		qb.conditions = []Condition{Or(combined...)}
+	} else {
+		qb.conditions = []Condition{And(combined...)}
 	}
 	return qb
 }
 
 // Select 选择字段
 func (qb *SQLQueryConstructor) Select(fields ...string) QueryConstructor {
-	qb.selectedCols = append(qb.selectedCols, fields...)
+	for _, field := range fields {
+		qb.selectedCols = append(qb.selectedCols, selectItem{column: field})
+	}
+	return qb
+}
+
+// SelectAllPlus 选择 schema 的全部列，并追加计算表达式
+// 普通列按方言转义，computed 表达式原样输出，例如 SelectAllPlus("(price * qty) AS total")
+func (qb *SQLQueryConstructor) SelectAllPlus(computed ...string) *SQLQueryConstructor {
+	for _, field := range qb.schema.Fields() {
+		qb.selectedCols = append(qb.selectedCols, selectItem{column: field.Name})
+	}
+	for _, expr := range computed {
+		qb.selectedCols = append(qb.selectedCols, selectItem{expr: expr})
+	}
 	return qb
 }
 
 // OrderBy 排序
+// direction 只接受 ASC/DESC（大小写不敏感），字段必须是 schema 字段或选择列的别名，
+// 否则 Build 返回错误，避免把外部传入的排序参数拼进 SQL；表达式排序使用 OrderByRaw
 func (qb *SQLQueryConstructor) OrderBy(field string, direction string) QueryConstructor {
-	direction = strings.ToUpper(direction)
+	direction = strings.ToUpper(strings.TrimSpace(direction))
 	if direction != "ASC" && direction != "DESC" {
-		direction = "ASC"
+		qb.setErr(fmt.Errorf("invalid order direction %q for %s, expected ASC or DESC", direction, field))
+		return qb
 	}
 	qb.orderBys = append(qb.orderBys, OrderBy{
 		Field:     field,
@@ -269,100 +707,786 @@ func (qb *SQLQueryConstructor) OrderBy(field string, direction string) QueryCons
 	return qb
 }
 
-// Limit 限制行数
-func (qb *SQLQueryConstructor) Limit(count int) QueryConstructor {
-	qb.limitVal = &count
+// OrderByCollate 按指定排序规则排序：ORDER BY col COLLATE name DIR
+// 排序规则名必须是安全的标识符，方向和字段的校验与 OrderBy 相同
+func (qb *SQLQueryConstructor) OrderByCollate(field, collation, direction string) *SQLQueryConstructor {
+	if err := validateCollation(collation); err != nil {
+		qb.setErr(fmt.Errorf("order by %s: %w", field, err))
+		return qb
+	}
+	n := len(qb.orderBys)
+	qb.OrderBy(field, direction)
+	if len(qb.orderBys) > n {
+		qb.orderBys[n].collation = collation
+	}
 	return qb
 }
 
-// Offset 偏移行数
-func (qb *SQLQueryConstructor) Offset(count int) QueryConstructor {
-	qb.offsetVal = &count
+// OrderByRaw 添加原样输出的排序表达式（可包含方向），例如 "FIELD(status, 'new', 'done') DESC"
+// 表达式不做转义和校验，不要拼接不可信的输入
+func (qb *SQLQueryConstructor) OrderByRaw(expr string) *SQLQueryConstructor {
+	qb.orderBys = append(qb.orderBys, OrderBy{Field: expr, raw: true})
 	return qb
 }
 
-// Build 构建 SQL 查询
-func (qb *SQLQueryConstructor) Build(ctx context.Context) (string, []interface{}, error) {
-	var sql strings.Builder
-	var args []interface{}
-	var argIndex int = 1
-	
-	// SELECT 部分
-	sql.WriteString("SELECT ")
-	if len(qb.selectedCols) > 0 {
-		for i, col := range qb.selectedCols {
-			if i > 0 {
-				sql.WriteString(", ")
-			}
-			sql.WriteString(qb.dialect.QuoteIdentifier(col))
-		}
-	} else {
-		// 默认选择所有字段
-		sql.WriteString("*")
-	}
-	
-	// FROM 部分
-	sql.WriteString(" FROM ")
-	sql.WriteString(qb.dialect.QuoteIdentifier(qb.schema.TableName()))
-	
-	// WHERE 部分
-	if len(qb.conditions) > 0 {
-		sql.WriteString(" WHERE ")
-		translator := &DefaultSQLTranslator{
-			dialect:  qb.dialect,
-			argIndex: &argIndex,
+// validateOrderBy 校验排序字段是 schema 字段、选择列的别名，或以主表/连接表为前缀的限定列（如 orders.created_at）
+// 前缀为主表时按 schema 校验列名，连接表没有 schema 可查，只校验前缀
+func (qb *SQLQueryConstructor) validateOrderBy() error {
+	for _, order := range qb.orderBys {
+		if order.raw || qb.schema.GetField(order.Field) != nil {
+			continue
 		}
-		
-		for i, condition := range qb.conditions {
-			if i > 0 {
-				sql.WriteString(" AND ")
+		if table, column, ok := strings.Cut(order.Field, "."); ok && qb.knownTable(table) {
+			if table == qb.schema.TableName() && qb.schema.GetField(column) == nil {
+				return fmt.Errorf("order by unknown column %s on %s", order.Field, qb.schema.TableName())
 			}
-			condSQL, condArgs, err := condition.Translate(translator)
-			if err != nil {
-				return "", nil, fmt.Errorf("failed to translate condition: %w", err)
+			continue
+		}
+		known := false
+		for _, col := range qb.selectedCols {
+			if col.alias == order.Field {
+				known = true
+				break
 			}
-			sql.WriteString(condSQL)
-			args = append(args, condArgs...)
+		}
+		if !known {
+			return fmt.Errorf("order by unknown column %s on %s", order.Field, qb.schema.TableName())
 		}
 	}
-	
-	// ORDER BY 部分
-	if len(qb.orderBys) > 0 {
-		sql.WriteString(" ORDER BY ")
-		for i, order := range qb.orderBys {
-			if i > 0 {
-				sql.WriteString(", ")
+	return qb.validateDistinctOrder()
+}
+
+// knownTable 名称是否为主表、连接表或 LATERAL 子查询的别名
+func (qb *SQLQueryConstructor) knownTable(name string) bool {
+	if name == qb.schema.TableName() {
+		return true
+	}
+	for _, join := range qb.joins {
+		if join.table == name || join.alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDistinctOrder PostgreSQL 要求 SELECT DISTINCT 的排序列出现在选择列表中
+// 自动补充选择列会改变去重结果，因此直接返回错误；未指定 Select 时所有列都在列表中
+func (qb *SQLQueryConstructor) validateDistinctOrder() error {
+	if !qb.distinct || len(qb.distinctOn) > 0 || len(qb.selectedCols) == 0 || qb.dialect.Name() != "postgresql" {
+		return nil
+	}
+	for _, order := range qb.orderBys {
+		if order.raw {
+			continue
+		}
+		selected := false
+		for _, col := range qb.selectedCols {
+			if col.alias == order.Field || (col.expr == "" && col.column == order.Field) {
+				selected = true
+				break
 			}
-			sql.WriteString(qb.dialect.QuoteIdentifier(order.Field))
-			sql.WriteString(" ")
-			sql.WriteString(order.Direction)
+		}
+		if !selected {
+			return fmt.Errorf("order by %s must appear in the SELECT DISTINCT list on %s", order.Field, qb.dialect.Name())
 		}
 	}
-	
-	// LIMIT/OFFSET 部分
-	limitOffset := qb.dialect.GenerateLimitOffset(qb.limitVal, qb.offsetVal)
-	if limitOffset != "" {
-		sql.WriteString(" ")
-		sql.WriteString(limitOffset)
+	return nil
+}
+
+// Distinct 生成 SELECT DISTINCT
+func (qb *SQLQueryConstructor) Distinct() *SQLQueryConstructor {
+	qb.distinct = true
+	return qb
+}
+
+// DistinctOn 生成 SELECT DISTINCT ON (fields)，每组只保留第一行（由 ORDER BY 决定）
+// 仅 PostgreSQL 支持，其他方言在 Build 时返回错误
+func (qb *SQLQueryConstructor) DistinctOn(fields ...string) *SQLQueryConstructor {
+	if !qb.dialect.SupportsDistinctOn() {
+		qb.setErr(fmt.Errorf("DISTINCT ON is not supported by the %s dialect", qb.dialect.Name()))
+		return qb
 	}
-	
-	return sql.String(), args, nil
+	if len(fields) == 0 {
+		qb.setErr(fmt.Errorf("DISTINCT ON requires at least one field"))
+		return qb
+	}
+	qb.distinctOn = append(qb.distinctOn, fields...)
+	return qb
 }
 
-// GetNativeBuilder 获取底层查询构造器（返回自身）
-func (qb *SQLQueryConstructor) GetNativeBuilder() interface{} {
+// LeftJoinLateral 生成 LEFT JOIN LATERAL (sub) alias ON on，sub 可以引用外层表的列（例如每组取前 N 行）
+// on 为空时生成 ON TRUE。子查询的占位符接着外层编号。仅 PostgreSQL 支持，其他方言在 Build 时返回错误
+func (qb *SQLQueryConstructor) LeftJoinLateral(sub *SQLQueryConstructor, alias string, on Condition) *SQLQueryConstructor {
+	if !qb.dialect.SupportsLateral() {
+		qb.setErr(fmt.Errorf("LATERAL joins are not supported by the %s dialect", qb.dialect.Name()))
+		return qb
+	}
+	if sub == nil || alias == "" {
+		qb.setErr(fmt.Errorf("LeftJoinLateral requires a subquery and an alias"))
+		return qb
+	}
+	qb.joins = append(qb.joins, joinClause{kind: "LEFT JOIN LATERAL", sub: sub, alias: alias, on: on})
 	return qb
 }
 
-// ==================== Default SQL Translator ====================
+// Join 生成 INNER JOIN table ON on，列对列条件使用 EqCol("posts.user_id", "users.id")
+// on 不能为空，以免漏写条件得到笛卡尔积；确实需要笛卡尔积时使用 CrossJoin
+func (qb *SQLQueryConstructor) Join(table string, on Condition) *SQLQueryConstructor {
+	return qb.addJoin("INNER JOIN", table, on)
+}
 
-// DefaultSQLTranslator 默认 SQL 转义器
-type DefaultSQLTranslator struct {
-	dialect  SQLDialect
-	argIndex *int
+// LeftJoin 生成 LEFT JOIN table ON on，on 不能为空
+func (qb *SQLQueryConstructor) LeftJoin(table string, on Condition) *SQLQueryConstructor {
+	return qb.addJoin("LEFT JOIN", table, on)
 }
 
-// TranslateCondition 转义单个条件
+// CrossJoin 生成 CROSS JOIN table，显式声明需要笛卡尔积
+func (qb *SQLQueryConstructor) CrossJoin(table string) *SQLQueryConstructor {
+	if table == "" {
+		qb.setErr(fmt.Errorf("CrossJoin requires a table"))
+		return qb
+	}
+	qb.joins = append(qb.joins, joinClause{kind: "CROSS JOIN", table: table})
+	return qb
+}
+
+// addJoin 添加带 ON 条件的表连接
+func (qb *SQLQueryConstructor) addJoin(kind, table string, on Condition) *SQLQueryConstructor {
+	if table == "" {
+		qb.setErr(fmt.Errorf("%s requires a table", kind))
+		return qb
+	}
+	if on == nil {
+		qb.setErr(fmt.Errorf("%s %s requires an ON condition, use CrossJoin for an intentional cartesian product", kind, table))
+		return qb
+	}
+	qb.joins = append(qb.joins, joinClause{kind: kind, table: table, on: on})
+	return qb
+}
+
+// GroupBy 分组
+func (qb *SQLQueryConstructor) GroupBy(fields ...string) *SQLQueryConstructor {
+	qb.groupBys = append(qb.groupBys, fields...)
+	return qb
+}
+
+// aggregateFunctions SelectAggregate 支持的聚合函数
+var aggregateFunctions = map[string]bool{
+	"COUNT": true,
+	"SUM":   true,
+	"AVG":   true,
+	"MIN":   true,
+	"MAX":   true,
+}
+
+// SelectAggregate 选择聚合表达式，例如 SelectAggregate("count", "id", "total") 生成 COUNT(`id`) AS `total`
+// field 为空时只允许 COUNT，生成 COUNT(*)。alias 为空时自动生成确定的别名：COUNT(*) 为 count，
+// 其他为 函数_字段（如 sum_amount），保证扫描为 map 时的键可预测。不支持的函数在 Build 时返回错误
+func (qb *SQLQueryConstructor) SelectAggregate(fn, field, alias string) *SQLQueryConstructor {
+	fn = strings.ToUpper(strings.TrimSpace(fn))
+	if !aggregateFunctions[fn] {
+		qb.setErr(fmt.Errorf("unsupported aggregate function %q", fn))
+		return qb
+	}
+
+	arg := "*"
+	if field != "" {
+		arg = quoteQualified(qb.dialect, field)
+	} else if fn != "COUNT" {
+		qb.setErr(fmt.Errorf("aggregate function %s requires a field", fn))
+		return qb
+	}
+
+	if alias == "" {
+		alias = strings.ToLower(fn)
+		if field != "" {
+			alias += "_" + strings.ReplaceAll(field, ".", "_")
+		}
+	}
+	expr := fn + "(" + arg + ") AS " + qb.dialect.QuoteIdentifier(alias)
+	qb.selectedCols = append(qb.selectedCols, selectItem{expr: expr, alias: alias})
+	return qb
+}
+
+// setErr 记录链式调用中的第一个错误
+func (qb *SQLQueryConstructor) setErr(err error) {
+	if qb.err == nil {
+		qb.err = err
+	}
+}
+
+// Facet 生成分面统计查询：SELECT field, COUNT(*) AS count ... GROUP BY field ORDER BY count DESC
+// 会替换已有的选择列，WHERE 条件照常生效
+func (qb *SQLQueryConstructor) Facet(groupField string) *SQLQueryConstructor {
+	qb.selectedCols = []selectItem{
+		{column: groupField},
+		{expr: "COUNT(*) AS " + qb.dialect.QuoteIdentifier("count"), alias: "count"},
+	}
+	qb.GroupBy(groupField)
+	qb.OrderBy("count", "DESC")
+	return qb
+}
+
+// StrictGrouping 开启严格分组校验
+// 开启后 Build 会在数据库报错之前拒绝经典的分组错误：
+// 既不在 GROUP BY 中、也不是聚合的选择列，以及键集分页中未分组的排序列
+func (qb *SQLQueryConstructor) StrictGrouping(enabled bool) *SQLQueryConstructor {
+	qb.strictGrouping = enabled
+	return qb
+}
+
+// StrictColumns 开启严格列校验
+// 开启后 Build 检查每个条件引用的列都在 schema 中声明，拼写错误在执行前就会报错。
+// 原样输出的条件（Raw、Exists）不做检查；带表名前缀的列只检查属于本表的列
+func (qb *SQLQueryConstructor) StrictColumns(enabled bool) *SQLQueryConstructor {
+	qb.strictColumns = enabled
+	return qb
+}
+
+// validateConditionColumns 严格列校验，逐个检查 WHERE 条件引用的列
+func (qb *SQLQueryConstructor) validateConditionColumns() error {
+	if !qb.strictColumns {
+		return nil
+	}
+	for _, condition := range qb.conditions {
+		for _, field := range conditionColumns(condition) {
+			if !qb.knownColumn(field) {
+				return fmt.Errorf("condition references unknown column %s on %s", field, qb.schema.TableName())
+			}
+		}
+	}
+	return nil
+}
+
+// conditionColumns 收集条件引用的列，JSON 路径只取列名；raw 和 EXISTS 条件无法静态分析，跳过
+func conditionColumns(condition Condition) []string {
+	switch c := condition.(type) {
+	case *CompositeCondition:
+		var fields []string
+		for _, child := range c.Conditions {
+			fields = append(fields, conditionColumns(child)...)
+		}
+		return fields
+	case *NotCondition:
+		return conditionColumns(c.Condition)
+	case *SimpleCondition:
+		switch c.Operator {
+		case "raw", "exists", "not_exists":
+			return nil
+		}
+		fields := []string{c.Field}
+		switch v := c.Value.(type) {
+		case tupleInValue:
+			fields = v.fields
+		case exprComparison:
+			fields = exprColumns(v.expr)
+			if ref, ok := v.value.(columnRef); ok {
+				fields = append(fields, string(ref))
+			}
+		case columnRef:
+			fields = append(fields, string(v))
+		}
+		columns := make([]string, len(fields))
+		for i, field := range fields {
+			if strings.HasPrefix(field, jsonExtractMarker) {
+				field, _, _ = strings.Cut(strings.TrimPrefix(field, jsonExtractMarker), jsonExtractMarker)
+			}
+			columns[i] = field
+		}
+		return columns
+	}
+	return nil
+}
+
+// knownColumn 列是否在 schema 中声明；其他表前缀的列（连接表）无法校验，视为已知
+func (qb *SQLQueryConstructor) knownColumn(field string) bool {
+	if table, column, ok := strings.Cut(field, "."); ok {
+		if table != qb.schema.TableName() {
+			return true
+		}
+		field = column
+	}
+	return qb.schema.GetField(field) != nil
+}
+
+// Limit 限制行数
+// count 必须 >= 1：负数和 0 都会让 Build 返回错误（SQL Server 不接受 FETCH NEXT 0 ROWS，
+// 为保证各方言行为一致不生成 LIMIT 0）；不需要限制时不要调用 Limit，或使用 ResetLimit
+func (qb *SQLQueryConstructor) Limit(count int) QueryConstructor {
+	if count < 1 {
+		qb.setErr(fmt.Errorf("limit must be >= 1, got %d", count))
+		return qb
+	}
+	qb.limitVal = &count
+	return qb
+}
+
+// Offset 偏移行数，count 必须 >= 0，负数会让 Build 返回错误
+func (qb *SQLQueryConstructor) Offset(count int) QueryConstructor {
+	if count < 0 {
+		qb.setErr(fmt.Errorf("offset must be >= 0, got %d", count))
+		return qb
+	}
+	qb.offsetVal = &count
+	return qb
+}
+
+// Clone 复制构造器，条件、选择列、JOIN、排序、分组和 LIMIT/OFFSET 都是独立副本，
+// 之后对任一副本的修改不会影响另一个；schema 和方言不可变，由两者共享
+func (qb *SQLQueryConstructor) Clone() QueryConstructor {
+	clone := *qb
+	clone.selectedCols = append([]selectItem{}, qb.selectedCols...)
+	clone.conditions = append([]Condition{}, qb.conditions...)
+	clone.orderBys = append([]OrderBy{}, qb.orderBys...)
+	clone.groupBys = append([]string(nil), qb.groupBys...)
+	clone.distinctOn = append([]string(nil), qb.distinctOn...)
+	clone.joins = make([]joinClause, len(qb.joins))
+	for i, join := range qb.joins {
+		if join.sub != nil {
+			join.sub = join.sub.Clone().(*SQLQueryConstructor)
+		}
+		clone.joins[i] = join
+	}
+	if qb.limitVal != nil {
+		limit := *qb.limitVal
+		clone.limitVal = &limit
+	}
+	if qb.offsetVal != nil {
+		offset := *qb.offsetVal
+		clone.offsetVal = &offset
+	}
+	return &clone
+}
+
+// ResetWhere 清除已添加的 WHERE 条件
+func (qb *SQLQueryConstructor) ResetWhere() *SQLQueryConstructor {
+	qb.conditions = make([]Condition, 0)
+	return qb
+}
+
+// ResetOrderBy 清除已添加的排序
+func (qb *SQLQueryConstructor) ResetOrderBy() *SQLQueryConstructor {
+	qb.orderBys = make([]OrderBy, 0)
+	return qb
+}
+
+// ResetSelect 清除选择列，恢复为 SELECT *
+func (qb *SQLQueryConstructor) ResetSelect() *SQLQueryConstructor {
+	qb.selectedCols = make([]selectItem, 0)
+	return qb
+}
+
+// ResetLimit 清除 LIMIT 和 OFFSET
+func (qb *SQLQueryConstructor) ResetLimit() *SQLQueryConstructor {
+	qb.limitVal = nil
+	qb.offsetVal = nil
+	return qb
+}
+
+// Paginate 按页码（从 1 开始）和每页行数设置 LIMIT/OFFSET
+func (qb *SQLQueryConstructor) Paginate(page, perPage int) *SQLQueryConstructor {
+	if page < 1 {
+		qb.setErr(fmt.Errorf("paginate: page must be >= 1, got %d", page))
+		return qb
+	}
+	if perPage < 1 {
+		qb.setErr(fmt.Errorf("paginate: perPage must be >= 1, got %d", perPage))
+		return qb
+	}
+	offset := (page - 1) * perPage
+	qb.limitVal = &perPage
+	qb.offsetVal = &offset
+	return qb
+}
+
+// BuildCount 构建统计总行数的 SELECT COUNT(*) 查询，保留 JOIN/WHERE，去掉 ORDER BY、LIMIT/OFFSET 和键集游标
+// 设置了 DISTINCT 或 GROUP BY 时把原查询包成子查询再计数，保证与分页结果的行数一致
+func (qb *SQLQueryConstructor) BuildCount(ctx context.Context) (string, []interface{}, error) {
+	count := *qb
+	count.keyset = nil
+	count.orderBys = nil
+	count.limitVal = nil
+	count.offsetVal = nil
+
+	wrap := count.distinct || len(count.distinctOn) > 0 || len(count.groupBys) > 0
+	if !wrap {
+		count.selectedCols = []selectItem{{expr: "COUNT(*)"}}
+	}
+
+	argIndex := 1
+	sql, args, err := count.build(ctx, &argIndex)
+	if err != nil {
+		return "", nil, err
+	}
+	if wrap {
+		sql = "SELECT COUNT(*) FROM (" + sql + ") " + qb.dialect.QuoteIdentifier("count_query")
+	}
+	if max := qb.dialect.MaxParameters(); max > 0 && len(args) > max {
+		return "", nil, &ParameterLimitError{Count: len(args), Max: max}
+	}
+	sql = applyKeywordCase(sql, qb.keywordCase)
+	recordSQL(sql, args)
+	return sql, args, nil
+}
+
+// ParameterLimitError 绑定参数个数超过方言上限时 Build 返回的错误
+type ParameterLimitError struct {
+	Count int
+	Max   int
+}
+
+func (e *ParameterLimitError) Error() string {
+	return fmt.Sprintf("query has %d bind parameters, exceeding the dialect limit of %d", e.Count, e.Max)
+}
+
+// ArgCount 返回 Build 将生成的绑定参数个数，用于执行前校验或记录日志
+// 内置条件直接按操作符计数，无法静态计数的条件才单独翻译
+func (qb *SQLQueryConstructor) ArgCount() int {
+	count := 0
+	for _, join := range qb.joins {
+		if join.sub != nil {
+			count += join.sub.ArgCount()
+		}
+		if join.on != nil {
+			count += qb.countConditionArgs(join.on)
+		}
+	}
+	for _, condition := range qb.conditions {
+		count += qb.countConditionArgs(condition)
+	}
+	if qb.keyset != nil {
+		if orderBys, err := qb.keysetOrder(); err == nil {
+			if cond, err := keysetCondition(orderBys, qb.keyset); err == nil {
+				count += qb.countConditionArgs(cond)
+			}
+		}
+	}
+	return count
+}
+
+func (qb *SQLQueryConstructor) countConditionArgs(condition Condition) int {
+	switch c := condition.(type) {
+	case *CompositeCondition:
+		count := 0
+		for _, child := range c.Conditions {
+			count += qb.countConditionArgs(child)
+		}
+		return count
+	case *NotCondition:
+		return qb.countConditionArgs(c.Condition)
+	case *SimpleCondition:
+		switch c.Operator {
+		case "eq", "ne", "gt", "lt", "gte", "lte":
+			return boundValueCount(c.Value)
+		case "like", "ilike":
+			return 1
+		case "isnull", "notnull":
+			return 0
+		case "between":
+			minMax := c.Value.([]interface{})
+			return boundValueCount(minMax[0]) + boundValueCount(minMax[1])
+		case "in", "notin", "like_any", "not_like_all", "array_contains", "array_overlaps":
+			return len(c.Value.([]interface{}))
+		case "geom_within":
+			return 4
+		case "tuple_in":
+			tuple := c.Value.(tupleInValue)
+			return len(tuple.rows) * len(tuple.fields)
+		case "raw":
+			return len(c.Value.(rawValue).args)
+		case "in_subquery", "exists", "not_exists":
+			if sub, ok := c.Value.(QueryConstructor); ok && sub != nil {
+				if native, ok := sub.GetNativeBuilder().(*SQLQueryConstructor); ok {
+					return native.ArgCount()
+				}
+			}
+		}
+	}
+
+	argIndex := 1
+	_, args, err := qb.dialect.TranslateCondition(condition, &argIndex)
+	if err != nil {
+		return 0
+	}
+	return len(args)
+}
+
+// boundValueCount Now() 渲染为数据库时间函数、列对列比较渲染为列名，都不占用参数
+func boundValueCount(value interface{}) int {
+	switch value.(type) {
+	case NowValue, columnRef:
+		return 0
+	}
+	return 1
+}
+
+// BuildExists 构建判断是否存在匹配行的查询：SELECT EXISTS(SELECT 1 ... LIMIT 1)
+// 保留 JOIN/WHERE，去掉选择列、ORDER BY、分页和键集游标；SQL Server 不支持把 EXISTS 作为选择列，
+// 改为 SELECT CASE WHEN EXISTS(...) THEN 1 ELSE 0 END
+func (qb *SQLQueryConstructor) BuildExists(ctx context.Context) (string, []interface{}, error) {
+	exists := *qb
+	exists.keyset = nil
+	exists.selectedCols = []selectItem{{expr: "1"}}
+	exists.orderBys = nil
+	exists.offsetVal = nil
+	one := 1
+	exists.limitVal = &one
+
+	argIndex := 1
+	sql, args, err := exists.build(ctx, &argIndex)
+	if err != nil {
+		return "", nil, err
+	}
+	if qb.dialect.Name() == "sqlserver" {
+		sql = "SELECT CASE WHEN EXISTS(" + sql + ") THEN 1 ELSE 0 END"
+	} else {
+		sql = "SELECT EXISTS(" + sql + ")"
+	}
+	if max := qb.dialect.MaxParameters(); max > 0 && len(args) > max {
+		return "", nil, &ParameterLimitError{Count: len(args), Max: max}
+	}
+	sql = applyKeywordCase(sql, qb.keywordCase)
+	recordSQL(sql, args)
+	return sql, args, nil
+}
+
+// Build 构建 SQL 查询
+// 绑定参数个数超过方言的 MaxParameters 时返回 *ParameterLimitError
+func (qb *SQLQueryConstructor) Build(ctx context.Context) (string, []interface{}, error) {
+	query, err := qb.BuildQuery(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return query.SQL, query.Args, nil
+}
+
+// build 从 *argIndex 开始编号占位符构建查询，作为子查询嵌入时由外层传入当前编号
+func (qb *SQLQueryConstructor) build(ctx context.Context, argIndex *int) (string, []interface{}, error) {
+	if qb.err != nil {
+		return "", nil, qb.err
+	}
+	if qb.keyset != nil {
+		return qb.buildKeyset(ctx, argIndex)
+	}
+	if err := qb.checkComplexity(); err != nil {
+		return "", nil, err
+	}
+	if err := qb.validateGrouping(); err != nil {
+		return "", nil, err
+	}
+	if err := qb.validateSelectNames(); err != nil {
+		return "", nil, err
+	}
+	if err := qb.validateOrderBy(); err != nil {
+		return "", nil, err
+	}
+	if err := qb.validateConditionColumns(); err != nil {
+		return "", nil, err
+	}
+
+	var sql strings.Builder
+	var args []interface{}
+	
+	// SELECT 部分
+	sql.WriteString("SELECT ")
+	if len(qb.distinctOn) > 0 {
+		quoted := make([]string, len(qb.distinctOn))
+		for i, field := range qb.distinctOn {
+			quoted[i] = quoteQualified(qb.dialect, field)
+		}
+		sql.WriteString("DISTINCT ON (" + strings.Join(quoted, ", ") + ") ")
+	} else if qb.distinct {
+		sql.WriteString("DISTINCT ")
+	}
+	if len(qb.selectedCols) > 0 {
+		for i, col := range qb.selectedCols {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(col.render(qb.dialect))
+		}
+	} else if columns := selectableColumns(qb.schema); len(columns) > 0 {
+		// 默认展开为 schema 的显式列表（不含只写字段）；有 JOIN 时加主表前缀，避免同名列歧义
+		for i, col := range columns {
+			if len(qb.joins) > 0 {
+				col = qb.schema.TableName() + "." + col
+			}
+			columns[i] = quoteQualified(qb.dialect, col)
+		}
+		sql.WriteString(strings.Join(columns, ", "))
+	} else {
+		// schema 没有声明字段时选择所有列
+		sql.WriteString("*")
+	}
+	
+	// FROM 部分
+	sql.WriteString(" FROM ")
+	sql.WriteString(qb.dialect.QuoteIdentifier(qb.schema.TableName()))
+
+	translator := &DefaultSQLTranslator{
+		dialect:  qb.dialect,
+		argIndex: argIndex,
+		cache:    make(map[Condition]cachedTranslation),
+	}
+
+	// JOIN 部分
+	for _, join := range qb.joins {
+		if join.sub == nil {
+			sql.WriteString(" " + join.kind + " " + qb.dialect.QuoteIdentifier(join.table))
+			if join.on == nil {
+				continue
+			}
+			sql.WriteString(" ON ")
+		} else {
+			subSQL, subArgs, err := join.sub.build(ctx, argIndex)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to build lateral subquery %s: %w", join.alias, err)
+			}
+			sql.WriteString(" " + join.kind + " (" + subSQL + ") " + qb.dialect.QuoteIdentifier(join.alias) + " ON ")
+			args = append(args, subArgs...)
+		}
+		if join.on == nil {
+			sql.WriteString("TRUE")
+			continue
+		}
+		onSQL, onArgs, err := translator.translate(join.on)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to translate join condition: %w", err)
+		}
+		sql.WriteString(onSQL)
+		args = append(args, onArgs...)
+	}
+	
+	// WHERE 部分
+	if len(qb.conditions) > 0 {
+		sql.WriteString(" WHERE ")
+		
+		for i, condition := range qb.conditions {
+			if i > 0 {
+				sql.WriteString(" AND ")
+			}
+			condSQL, condArgs, err := translator.translate(condition)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to translate condition: %w", err)
+			}
+			sql.WriteString(condSQL)
+			args = append(args, condArgs...)
+		}
+	}
+	
+	// GROUP BY 部分
+	if len(qb.groupBys) > 0 {
+		sql.WriteString(" GROUP BY ")
+		for i, field := range qb.groupBys {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(quoteQualified(qb.dialect, field))
+		}
+	}
+	
+	// ORDER BY 部分
+	if len(qb.orderBys) > 0 {
+		sql.WriteString(" ORDER BY ")
+		for i, order := range qb.orderBys {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			if order.raw {
+				sql.WriteString(order.Field)
+				continue
+			}
+			sql.WriteString(quoteQualified(qb.dialect, order.Field))
+			if order.collation != "" {
+				sql.WriteString(collateClause(qb.dialect, order.collation))
+			}
+			sql.WriteString(" ")
+			sql.WriteString(order.Direction)
+		}
+	}
+	
+	// LIMIT/OFFSET 部分
+	limitOffset := qb.dialect.GenerateLimitOffset(qb.limitVal, qb.offsetVal)
+	if limitOffset != "" {
+		// OFFSET ... FETCH（SQL Server）必须跟在 ORDER BY 之后，没有排序时使用不改变顺序的占位排序
+		if len(qb.orderBys) == 0 && strings.HasPrefix(limitOffset, "OFFSET") {
+			sql.WriteString(" ORDER BY (SELECT NULL)")
+		}
+		sql.WriteString(" ")
+		sql.WriteString(limitOffset)
+	}
+	
+	return sql.String(), args, nil
+}
+
+// validateSelectNames 校验选择列的结果列名不重复（原样输出且没有别名的表达式无法判断，跳过）
+func (qb *SQLQueryConstructor) validateSelectNames() error {
+	seen := make(map[string]bool, len(qb.selectedCols))
+	for _, col := range qb.selectedCols {
+		name := col.alias
+		if name == "" && col.expr == "" {
+			name = col.column[strings.LastIndex(col.column, ".")+1:]
+		}
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate result column %s in select list, use distinct aliases", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// validateGrouping 严格模式下校验选择列与 GROUP BY 是否一致
+func (qb *SQLQueryConstructor) validateGrouping() error {
+	if !qb.strictGrouping || len(qb.groupBys) == 0 {
+		return nil
+	}
+
+	if len(qb.selectedCols) == 0 {
+		return fmt.Errorf("strict grouping: SELECT * cannot be combined with GROUP BY, select the grouped columns explicitly")
+	}
+
+	grouped := make(map[string]bool, len(qb.groupBys))
+	for _, field := range qb.groupBys {
+		grouped[field] = true
+	}
+	for _, col := range qb.selectedCols {
+		// 原样输出的表达式无法静态分析，交给数据库校验
+		if col.expr != "" {
+			continue
+		}
+		if !grouped[col.column] {
+			return fmt.Errorf("strict grouping: column %s must appear in GROUP BY or be used in an aggregate", col.column)
+		}
+	}
+	return nil
+}
+
+// GetNativeBuilder 获取底层查询构造器（返回自身）
+func (qb *SQLQueryConstructor) GetNativeBuilder() interface{} {
+	return qb
+}
+
+// ==================== Default SQL Translator ====================
+
+// DefaultSQLTranslator 默认 SQL 转义器
+type DefaultSQLTranslator struct {
+	dialect  SQLDialect
+	argIndex *int
+
+	// 单次 Build 内已翻译的复合条件（按指针），为 nil 时不缓存
+	cache map[Condition]cachedTranslation
+}
+
+// cachedTranslation 缓存的条件翻译结果，parts 之间是占位符的位置
+type cachedTranslation struct {
+	parts []string
+	args  []interface{}
+}
+
+// TranslateCondition 转义单个条件
 func (t *DefaultSQLTranslator) TranslateCondition(condition Condition) (string, []interface{}, error) {
 	switch c := condition.(type) {
 	case *SimpleCondition:
@@ -376,38 +1500,105 @@ func (t *DefaultSQLTranslator) TranslateCondition(condition Condition) (string,
 	}
 }
 
+// translate 翻译条件
+// 同一次 Build 中重复出现的复合条件（例如作用域和 Where 共用的租户过滤）只翻译一次，
+// 之后按当前参数编号重新填充占位符
+func (t *DefaultSQLTranslator) translate(condition Condition) (string, []interface{}, error) {
+	if t.cache == nil || !cacheableCondition(condition) {
+		return condition.Translate(t)
+	}
+
+	entry, ok := t.cache[condition]
+	if !ok {
+		argIndex := 1
+		marker := &DefaultSQLTranslator{dialect: inlineLiteralDialect{t.dialect}, argIndex: &argIndex, cache: t.cache}
+		sql, args, err := condition.Translate(marker)
+		if err != nil {
+			return "", nil, err
+		}
+		entry = cachedTranslation{parts: strings.Split(sql, inlinePlaceholder), args: args}
+		if len(entry.parts) != len(args)+1 {
+			return condition.Translate(t)
+		}
+		t.cache[condition] = entry
+	}
+
+	var sql strings.Builder
+	sql.WriteString(entry.parts[0])
+	for _, part := range entry.parts[1:] {
+		sql.WriteString(t.dialect.GetPlaceholder(*t.argIndex))
+		*t.argIndex++
+		sql.WriteString(part)
+	}
+	return sql.String(), append([]interface{}(nil), entry.args...), nil
+}
+
+// cacheableCondition 只缓存复合条件和 NOT 条件；包含子查询的条件按自身方言编号，不能缓存
+func cacheableCondition(condition Condition) bool {
+	switch c := condition.(type) {
+	case *CompositeCondition:
+		for _, child := range c.Conditions {
+			if !cacheableChild(child) {
+				return false
+			}
+		}
+		return true
+	case *NotCondition:
+		return cacheableChild(c.Condition)
+	}
+	return false
+}
+
+func cacheableChild(condition Condition) bool {
+	switch c := condition.(type) {
+	case *SimpleCondition:
+		switch c.Operator {
+		case "in_subquery", "exists", "not_exists":
+			return false
+		}
+		return true
+	case *CompositeCondition, *NotCondition:
+		return cacheableCondition(c)
+	}
+	return false
+}
+
 func (t *DefaultSQLTranslator) translateSimpleCondition(cond *SimpleCondition) (string, []interface{}, error) {
+	switch cond.Operator {
+	case "raw":
+		return t.translateRaw(cond)
+	case "expr":
+		return t.translateExpr(cond)
+	case "exists", "not_exists":
+		return t.translateExists(cond)
+	case "in", "notin":
+		// 空列表：IN () 是语法错误，IN 永远不成立、NOT IN 永远成立
+		if values, ok := cond.Value.([]interface{}); ok && len(values) == 0 {
+			if cond.Operator == "in" {
+				return "1=0", nil, nil
+			}
+			return "1=1", nil, nil
+		}
+	}
+
 	var sql strings.Builder
 	var args []interface{}
 	
-	sql.WriteString(t.dialect.QuoteIdentifier(cond.Field))
+	if _, ok := cond.Value.(columnRef); ok {
+		sql.WriteString(quoteQualified(t.dialect, cond.Field))
+	} else {
+		column, err := t.column(cond.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		sql.WriteString(column)
+	}
 	sql.WriteString(" ")
 	
 	switch cond.Operator {
-	case "eq":
-		sql.WriteString("= " + t.dialect.GetPlaceholder(*t.argIndex))
-		args = append(args, cond.Value)
-		*t.argIndex++
-	case "ne":
-		sql.WriteString("!= " + t.dialect.GetPlaceholder(*t.argIndex))
-		args = append(args, cond.Value)
-		*t.argIndex++
-	case "gt":
-		sql.WriteString("> " + t.dialect.GetPlaceholder(*t.argIndex))
-		args = append(args, cond.Value)
-		*t.argIndex++
-	case "lt":
-		sql.WriteString("< " + t.dialect.GetPlaceholder(*t.argIndex))
-		args = append(args, cond.Value)
-		*t.argIndex++
-	case "gte":
-		sql.WriteString(">= " + t.dialect.GetPlaceholder(*t.argIndex))
-		args = append(args, cond.Value)
-		*t.argIndex++
-	case "lte":
-		sql.WriteString("<= " + t.dialect.GetPlaceholder(*t.argIndex))
-		args = append(args, cond.Value)
-		*t.argIndex++
+	case "eq", "ne", "gt", "lt", "gte", "lte":
+		sql.WriteString(comparisonOperators[cond.Operator] + " ")
+		args = t.writeValue(&sql, args, cond.Value)
 	case "in":
 		values := cond.Value.([]interface{})
 		sql.WriteString("IN (")
@@ -420,17 +1611,50 @@ func (t *DefaultSQLTranslator) translateSimpleCondition(cond *SimpleCondition) (
 		}
 		sql.WriteString(")")
 		args = append(args, values...)
+	case "notin":
+		values := cond.Value.([]interface{})
+		sql.WriteString("NOT IN (")
+		for i := range values {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(t.dialect.GetPlaceholder(*t.argIndex))
+			*t.argIndex++
+		}
+		sql.WriteString(")")
+		args = append(args, values...)
+	case "isnull":
+		sql.WriteString("IS NULL")
+	case "notnull":
+		sql.WriteString("IS NOT NULL")
 	case "like":
 		sql.WriteString("LIKE " + t.dialect.GetPlaceholder(*t.argIndex))
 		args = append(args, cond.Value)
 		*t.argIndex++
+	case "ilike":
+		column, err := t.column(cond.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		expr := t.dialect.ILikeExpr(column, t.dialect.GetPlaceholder(*t.argIndex))
+		*t.argIndex++
+		return expr, []interface{}{cond.Value}, nil
+	case "like_any", "not_like_all":
+		return t.translatePatternList(cond)
+	case "array_contains", "array_overlaps":
+		return t.translateArrayOperator(cond)
+	case "geom_within":
+		return t.translateGeomWithin(cond)
+	case "tuple_in":
+		return t.translateTupleIn(cond)
+	case "in_subquery":
+		return t.translateInSubquery(cond)
 	case "between":
 		minMax := cond.Value.([]interface{})
-		sql.WriteString("BETWEEN " + t.dialect.GetPlaceholder(*t.argIndex))
-		*t.argIndex++
-		sql.WriteString(" AND " + t.dialect.GetPlaceholder(*t.argIndex))
-		*t.argIndex++
-		args = append(args, minMax...)
+		sql.WriteString("BETWEEN ")
+		args = t.writeValue(&sql, args, minMax[0])
+		sql.WriteString(" AND ")
+		args = t.writeValue(&sql, args, minMax[1])
 	default:
 		return "", nil, fmt.Errorf("unsupported operator: %s", cond.Operator)
 	}
@@ -438,12 +1662,391 @@ func (t *DefaultSQLTranslator) translateSimpleCondition(cond *SimpleCondition) (
 	return sql.String(), args, nil
 }
 
+// translatePatternList 转义 LikeAny / NotLikeAll
+// 支持数组的方言使用 LIKE ANY (ARRAY[...]) / NOT LIKE ALL (ARRAY[...])，
+// 其他方言展开为 OR 连接的 LIKE 或 AND 连接的 NOT LIKE
+func (t *DefaultSQLTranslator) translatePatternList(cond *SimpleCondition) (string, []interface{}, error) {
+	patterns := cond.Value.([]interface{})
+	if len(patterns) == 0 {
+		return "", nil, fmt.Errorf("%s on field %s requires at least one pattern", cond.Operator, cond.Field)
+	}
+
+	field, err := t.column(cond.Field)
+	if err != nil {
+		return "", nil, err
+	}
+	placeholders := make([]string, len(patterns))
+	for i := range patterns {
+		placeholders[i] = t.dialect.GetPlaceholder(*t.argIndex)
+		*t.argIndex++
+	}
+
+	if t.dialect.SupportsArrays() {
+		op := "LIKE ANY"
+		if cond.Operator == "not_like_all" {
+			op = "NOT LIKE ALL"
+		}
+		return fmt.Sprintf("%s %s (ARRAY[%s])", field, op, strings.Join(placeholders, ", ")), patterns, nil
+	}
+
+	op, joiner := "LIKE", " OR "
+	if cond.Operator == "not_like_all" {
+		op, joiner = "NOT LIKE", " AND "
+	}
+	parts := make([]string, len(placeholders))
+	for i, ph := range placeholders {
+		parts[i] = field + " " + op + " " + ph
+	}
+	return "(" + strings.Join(parts, joiner) + ")", patterns, nil
+}
+
+// translateGeomWithin 转义 GeomWithin 为 ST_Within(field, ST_MakeEnvelope(...))
+func (t *DefaultSQLTranslator) translateGeomWithin(cond *SimpleCondition) (string, []interface{}, error) {
+	if !t.dialect.SupportsGeo() {
+		return "", nil, fmt.Errorf("GeomWithin is not supported by the %s dialect", t.dialect.Name())
+	}
+	bbox := cond.Value.(BBox)
+	srid := bbox.SRID
+	if srid == 0 {
+		srid = DefaultSRID
+	}
+
+	// ST_MakeEnvelope(xmin, ymin, xmax, ymax, srid)：x 为经度，y 为纬度
+	args := []interface{}{bbox.MinLng, bbox.MinLat, bbox.MaxLng, bbox.MaxLat}
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = t.dialect.GetPlaceholder(*t.argIndex)
+		*t.argIndex++
+	}
+	sql := fmt.Sprintf("ST_Within(%s, ST_MakeEnvelope(%s, %d))",
+		t.dialect.QuoteIdentifier(cond.Field), strings.Join(placeholders, ", "), srid)
+	return sql, args, nil
+}
+
+// translateRaw 转义 Raw：把片段中的 ? 按顺序改写为方言占位符
+// 单引号字符串和双引号标识符中的 ? 不是占位符，保持原样
+func (t *DefaultSQLTranslator) translateRaw(cond *SimpleCondition) (string, []interface{}, error) {
+	raw := cond.Value.(rawValue)
+	sql, err := t.bindFragment(raw.sql, raw.args)
+	if err != nil {
+		return "", nil, fmt.Errorf("raw condition %w", err)
+	}
+	// 片段可能包含 OR，加括号避免与相邻的 AND 条件结合错误
+	return "(" + sql + ")", raw.args, nil
+}
+
+// translateExpr 转义表达式比较：展开 {col} 并绑定表达式参数，再写入运算符和比较值
+func (t *DefaultSQLTranslator) translateExpr(cond *SimpleCondition) (string, []interface{}, error) {
+	cmp := cond.Value.(exprComparison)
+	expanded := exprColumnPattern.ReplaceAllStringFunc(cmp.expr.sql, func(token string) string {
+		return quoteQualified(t.dialect, token[1:len(token)-1])
+	})
+	fragment, err := t.bindFragment(expanded, cmp.expr.args)
+	if err != nil {
+		return "", nil, fmt.Errorf("expression %w", err)
+	}
+
+	var sql strings.Builder
+	sql.WriteString(fragment + " " + comparisonOperators[cmp.op] + " ")
+	args := t.writeValue(&sql, append([]interface{}(nil), cmp.expr.args...), cmp.value)
+	return sql.String(), args, nil
+}
+
+// exprColumnPattern Expr 片段中的 {col} 列引用
+var exprColumnPattern = regexp.MustCompile(`\{[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?\}`)
+
+// exprColumns 返回 Expr 片段引用的列名
+func exprColumns(expr Expression) []string {
+	tokens := exprColumnPattern.FindAllString(expr.sql, -1)
+	columns := make([]string, len(tokens))
+	for i, token := range tokens {
+		columns[i] = token[1 : len(token)-1]
+	}
+	return columns
+}
+
+// bindFragment 把片段中引号外的 ? 按方言改写为占位符，个数必须与 args 一致
+func (t *DefaultSQLTranslator) bindFragment(fragment string, args []interface{}) (string, error) {
+	var sql strings.Builder
+	count := 0
+	var quote rune
+	for _, r := range fragment {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			sql.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			sql.WriteRune(r)
+		case r == '?':
+			count++
+			if count > len(args) {
+				continue
+			}
+			sql.WriteString(t.dialect.GetPlaceholder(*t.argIndex))
+			*t.argIndex++
+		default:
+			sql.WriteRune(r)
+		}
+	}
+	if count != len(args) {
+		return "", fmt.Errorf("%q has %d placeholders but %d args", fragment, count, len(args))
+	}
+	return sql.String(), nil
+}
+
+// translateInSubquery 转义 InSubquery：子查询从当前参数编号继续编号，参数按位置合并到外层
+func (t *DefaultSQLTranslator) translateInSubquery(cond *SimpleCondition) (string, []interface{}, error) {
+	sub, ok := cond.Value.(QueryConstructor)
+	if !ok || sub == nil {
+		return "", nil, fmt.Errorf("InSubquery on field %s requires a subquery", cond.Field)
+	}
+	if native, ok := sub.GetNativeBuilder().(*SQLQueryConstructor); ok && len(native.selectedCols) != 1 {
+		return "", nil, fmt.Errorf("InSubquery on field %s requires a subquery selecting exactly one column, got %s", cond.Field, selectedColumnCount(native))
+	}
+
+	subSQL, subArgs, err := t.buildSubquery(sub)
+	if err != nil {
+		return "", nil, fmt.Errorf("InSubquery on field %s: %w", cond.Field, err)
+	}
+	return t.dialect.QuoteIdentifier(cond.Field) + " IN (" + subSQL + ")", subArgs, nil
+}
+
+// translateExists 转义 Exists / NotExists，没有左侧列
+func (t *DefaultSQLTranslator) translateExists(cond *SimpleCondition) (string, []interface{}, error) {
+	sub, ok := cond.Value.(QueryConstructor)
+	if !ok || sub == nil {
+		return "", nil, fmt.Errorf("Exists requires a subquery")
+	}
+	subSQL, subArgs, err := t.buildSubquery(sub)
+	if err != nil {
+		return "", nil, fmt.Errorf("Exists: %w", err)
+	}
+	keyword := "EXISTS ("
+	if cond.Operator == "not_exists" {
+		keyword = "NOT EXISTS ("
+	}
+	return keyword + subSQL + ")", subArgs, nil
+}
+
+// buildSubquery 构建嵌入的子查询，占位符接着当前编号继续
+func (t *DefaultSQLTranslator) buildSubquery(sub QueryConstructor) (string, []interface{}, error) {
+	if native, ok := sub.GetNativeBuilder().(*SQLQueryConstructor); ok {
+		subSQL, subArgs, err := native.build(context.Background(), t.argIndex)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to build subquery: %w", err)
+		}
+		return subSQL, subArgs, nil
+	}
+
+	// 其他构造器只能从 1 开始编号，编号占位符的方言无法安全地重新编号
+	subSQL, subArgs, err := sub.Build(context.Background())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build subquery: %w", err)
+	}
+	if len(subArgs) > 0 && t.dialect.GetPlaceholder(1) != t.dialect.GetPlaceholder(2) {
+		return "", nil, fmt.Errorf("cannot renumber placeholders of %T for the %s dialect", sub, t.dialect.Name())
+	}
+	*t.argIndex += len(subArgs)
+	return subSQL, subArgs, nil
+}
+
+// selectedColumnCount 描述子查询的选择列个数，未选择列时为 SELECT *
+func selectedColumnCount(qb *SQLQueryConstructor) string {
+	if len(qb.selectedCols) == 0 {
+		return "*"
+	}
+	return fmt.Sprint(len(qb.selectedCols))
+}
+
+// translateArrayOperator 转义 ArrayContains / ArrayOverlaps，不支持数组的方言返回错误
+func (t *DefaultSQLTranslator) translateArrayOperator(cond *SimpleCondition) (string, []interface{}, error) {
+	name, op := "ArrayContains", "@>"
+	if cond.Operator == "array_overlaps" {
+		name, op = "ArrayOverlaps", "&&"
+	}
+	if !t.dialect.SupportsArrays() {
+		return "", nil, fmt.Errorf("%s on field %s requires array support, which the %s dialect does not provide (PostgreSQL only)", name, cond.Field, t.dialect.Name())
+	}
+	values := cond.Value.([]interface{})
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("%s on field %s requires at least one element", name, cond.Field)
+	}
+
+	column, err := t.column(cond.Field)
+	if err != nil {
+		return "", nil, err
+	}
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = t.dialect.GetPlaceholder(*t.argIndex)
+		*t.argIndex++
+	}
+	return fmt.Sprintf("%s %s ARRAY[%s]", column, op, strings.Join(placeholders, ", ")), values, nil
+}
+
+// translateTupleIn 转义 TupleIn
+// 支持行值的方言生成 (a, b) IN ((?, ?), (?, ?))，其他方言展开为 ((a = ? AND b = ?) OR ...)，
+// 两种形式的参数都按行优先顺序绑定
+func (t *DefaultSQLTranslator) translateTupleIn(cond *SimpleCondition) (string, []interface{}, error) {
+	tuple := cond.Value.(tupleInValue)
+	if len(tuple.fields) == 0 {
+		return "", nil, fmt.Errorf("TupleIn requires at least one field")
+	}
+	if len(tuple.rows) == 0 {
+		return "", nil, fmt.Errorf("TupleIn on (%s) requires at least one row", strings.Join(tuple.fields, ", "))
+	}
+
+	fields := make([]string, len(tuple.fields))
+	for i, field := range tuple.fields {
+		fields[i] = t.dialect.QuoteIdentifier(field)
+	}
+
+	args := make([]interface{}, 0, len(tuple.rows)*len(fields))
+	groups := make([]string, len(tuple.rows))
+	rowValues := t.dialect.SupportsRowValues()
+	for i, row := range tuple.rows {
+		if len(row) != len(fields) {
+			return "", nil, fmt.Errorf("TupleIn row %d has %d values, expected %d", i, len(row), len(fields))
+		}
+		parts := make([]string, len(row))
+		for j, value := range row {
+			placeholder := t.dialect.GetPlaceholder(*t.argIndex)
+			*t.argIndex++
+			if rowValues {
+				parts[j] = placeholder
+			} else {
+				parts[j] = fields[j] + " = " + placeholder
+			}
+			args = append(args, value)
+		}
+		if rowValues {
+			groups[i] = "(" + strings.Join(parts, ", ") + ")"
+		} else {
+			groups[i] = "(" + strings.Join(parts, " AND ") + ")"
+		}
+	}
+
+	if rowValues {
+		return "(" + strings.Join(fields, ", ") + ") IN (" + strings.Join(groups, ", ") + ")", args, nil
+	}
+	return "(" + strings.Join(groups, " OR ") + ")", args, nil
+}
+
+// comparisonOperators 比较操作符到 SQL 的映射
+var comparisonOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "!=",
+	"gt":  ">",
+	"lt":  "<",
+	"gte": ">=",
+	"lte": "<=",
+}
+
+// writeValue 写入一个比较操作数：普通值绑定为参数，Now() 渲染为数据库时间函数
+func (t *DefaultSQLTranslator) writeValue(sql *strings.Builder, args []interface{}, value interface{}) []interface{} {
+	if _, ok := value.(NowValue); ok {
+		sql.WriteString(t.dialect.CurrentTimestamp())
+		return args
+	}
+	if column, ok := value.(columnRef); ok {
+		sql.WriteString(quoteQualified(t.dialect, string(column)))
+		return args
+	}
+	sql.WriteString(t.dialect.GetPlaceholder(*t.argIndex))
+	*t.argIndex++
+	return append(args, value)
+}
+
+// column 转义条件左侧的字段，JSONExtract 字段展开为方言的 JSON 提取表达式
+func (t *DefaultSQLTranslator) column(field string) (string, error) {
+	if !strings.HasPrefix(field, jsonExtractMarker) {
+		return t.dialect.QuoteIdentifier(field), nil
+	}
+	column, path, _ := strings.Cut(strings.TrimPrefix(field, jsonExtractMarker), jsonExtractMarker)
+	if !jsonPathPattern.MatchString(path) {
+		return "", fmt.Errorf("JSONExtract on %s: invalid path %q, expected $.key.sub[0] form", column, path)
+	}
+	return t.dialect.JSONExtractExpr(t.dialect.QuoteIdentifier(column), path), nil
+}
+
+// jsonPathPattern JSONExtract 接受的路径：$ 后跟 .key 或 [n]
+var jsonPathPattern = regexp.MustCompile(`^\$(\.[A-Za-z_][A-Za-z0-9_]*|\[[0-9]+\])+$`)
+
+// jsonPathSegments 把 $.a.b[0] 拆分为 a、b、0
+func jsonPathSegments(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(strings.TrimPrefix(path, "$"))
+	return strings.Split(strings.TrimPrefix(path, "."), ".")
+}
+
+// isJSONIndex 路径是否只有一个数组下标，例如 $[0]
+func isJSONIndex(path string) bool {
+	return strings.HasPrefix(path, "$[")
+}
+
+// quoteQualified 转义可能带表名前缀的列名，a.price 转义为 `a`.`price`
+func quoteQualified(dialect SQLDialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = dialect.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// inlinePlaceholder 内联翻译时占位符的临时标记
+const inlinePlaceholder = "\x00"
+
+// inlineLiteralDialect 把占位符替换为临时标记的方言包装，其余行为与被包装的方言一致
+type inlineLiteralDialect struct {
+	SQLDialect
+}
+
+func (d inlineLiteralDialect) GetPlaceholder(index int) string {
+	return inlinePlaceholder
+}
+
+// translateConditionInline 把条件翻译为不含绑定参数的 SQL，参数按方言转义为字面量
+// 用于 DDL（部分索引谓词等）这类不能使用绑定参数的场景
+func translateConditionInline(dialect SQLDialect, condition Condition) (string, error) {
+	argIndex := 1
+	translator := &DefaultSQLTranslator{dialect: inlineLiteralDialect{dialect}, argIndex: &argIndex}
+	sql, args, err := condition.Translate(translator)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(sql, inlinePlaceholder)
+	if len(parts) != len(args)+1 {
+		return "", fmt.Errorf("cannot inline condition: %d placeholders for %d arguments", len(parts)-1, len(args))
+	}
+	var result strings.Builder
+	result.WriteString(parts[0])
+	for i, arg := range args {
+		result.WriteString(inlineLiteral(dialect, arg))
+		result.WriteString(parts[i+1])
+	}
+	return result.String(), nil
+}
+
+// inlineLiteral 把参数值转义为 SQL 字面量
+func inlineLiteral(dialect SQLDialect, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return dialect.QuoteValue(v.Format("2006-01-02 15:04:05.999999999"))
+	case []byte:
+		return dialect.QuoteValue(string(v))
+	}
+	return dialect.QuoteValue(value)
+}
+
 func (t *DefaultSQLTranslator) translateCompositeCondition(cond *CompositeCondition) (string, []interface{}, error) {
 	return t.TranslateComposite(cond.Operator, cond.Conditions)
 }
 
 func (t *DefaultSQLTranslator) translateNotCondition(cond *NotCondition) (string, []interface{}, error) {
-	innerSQL, args, err := cond.Condition.Translate(t)
+	innerSQL, args, err := t.translate(cond.Condition)
 	if err != nil {
 		return "", nil, err
 	}
@@ -469,7 +2072,7 @@ func (t *DefaultSQLTranslator) TranslateComposite(operator string, conditions []
 		if i > 0 {
 			sql.WriteString(" " + sqlOperator + " ")
 		}
-		condSQL, condArgs, err := cond.Translate(t)
+		condSQL, condArgs, err := t.translate(cond)
 		if err != nil {
 			return "", nil, err
 		}
@@ -491,9 +2094,13 @@ type DefaultSQLQueryConstructorProvider struct {
 
 // NewDefaultSQLQueryConstructorProvider 创建默认 SQL 查询构造器提供者
 func NewDefaultSQLQueryConstructorProvider(dialect SQLDialect) *DefaultSQLQueryConstructorProvider {
+	capabilities := DefaultQueryBuilderCapabilities()
+	if dialect != nil {
+		capabilities.SupportsReturning = dialect.SupportsReturning()
+	}
 	return &DefaultSQLQueryConstructorProvider{
 		dialect:      dialect,
-		capabilities: DefaultQueryBuilderCapabilities(),
+		capabilities: capabilities,
 	}
 }
 
@@ -502,6 +2109,11 @@ func (p *DefaultSQLQueryConstructorProvider) NewQueryConstructor(schema Schema)
 	return NewSQLQueryConstructor(schema, p.dialect)
 }
 
+// Dialect 获取 SQL 方言
+func (p *DefaultSQLQueryConstructorProvider) Dialect() SQLDialect {
+	return p.dialect
+}
+
 // GetCapabilities 获取查询能力声明
 func (p *DefaultSQLQueryConstructorProvider) GetCapabilities() *QueryBuilderCapabilities {
 	return p.capabilities
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -169,6 +171,47 @@ func TestSQLQueryConstructorInCondition(t *testing.T) {
 	t.Logf("✓ IN condition: %s with args %v", sql, args)
 }
 
+// TestPostgreSQLInPlaceholderNumbering 测试 PostgreSQL 下 IN 列表的占位符按顺序编号并延续前面的条件
+func TestPostgreSQLInPlaceholderNumbering(t *testing.T) {
+	schema := NewBaseSchema("items")
+	schema.AddField(NewField("a", TypeInteger).Build())
+	schema.AddField(NewField("b", TypeInteger).Build())
+	schema.AddField(NewField("c", TypeInteger).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.WhereAll(Eq("a", 1), In("b", 2, 3, 4))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "a", "b", "c" FROM "items" WHERE ("a" = $1 AND "b" IN ($2, $3, $4))`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if len(args) != 4 {
+		t.Fatalf("Expected 4 arguments, got %v", args)
+	}
+	for i, want := range []interface{}{1, 2, 3, 4} {
+		if args[i] != want {
+			t.Errorf("Expected argument %v at index %d, got %v", want, i, args[i])
+		}
+	}
+
+	// 方言的 TranslateCondition 延续调用方传入的序号，未传入时从 $1 开始
+	argIndex := 3
+	condSQL, _, err := NewPostgreSQLDialect().TranslateCondition(In("c", 5, 6), &argIndex)
+	if err != nil {
+		t.Fatalf("TranslateCondition failed: %v", err)
+	}
+	if condSQL != `"c" IN ($3, $4)` || argIndex != 5 {
+		t.Errorf("Expected continued numbering, got %s (next index %d)", condSQL, argIndex)
+	}
+	if condSQL, _, err = NewSQLServerDialect().TranslateCondition(In("c", 5, 6), nil); err != nil || condSQL != "[c] IN (@p1, @p2)" {
+		t.Errorf("Expected numbering from @p1 without argIndex, got %s (%v)", condSQL, err)
+	}
+}
+
 // TestSQLQueryConstructorBetweenCondition 测试 BETWEEN 条件
 func TestSQLQueryConstructorBetweenCondition(t *testing.T) {
 	schema := NewBaseSchema("users")
@@ -625,7 +668,7 @@ func TestSQLServerDialect(t *testing.T) {
 		{
 			"Basic SELECT with SQL Server brackets",
 			func(qc *SQLQueryConstructor) {},
-			"SELECT * FROM [users]",
+			"SELECT [id], [name], [age] FROM [users]",
 			"[users]",
 		},
 		{
@@ -823,3 +866,1558 @@ func TestSQLServerDialectQuotingComparison(t *testing.T) {
 func intPtr(v int) *int {
 	return &v
 }
+
+// TestSQLQueryConstructorStrictGrouping 测试严格分组校验
+func TestSQLQueryConstructorStrictGrouping(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("customer", TypeString).Build())
+
+	ctx := context.Background()
+
+	valid := NewSQLQueryConstructor(schema, NewMySQLDialect()).StrictGrouping(true)
+	valid.Select("status").Where(Ne("status", "draft"))
+	valid.GroupBy("status")
+	sql, _, err := valid.Build(ctx)
+	if err != nil {
+		t.Fatalf("Expected valid grouped query to build, got: %v", err)
+	}
+	if !strings.Contains(sql, "WHERE `status` != ? GROUP BY `status`") {
+		t.Errorf("Unexpected grouped SQL: %s", sql)
+	}
+
+	mixed := NewSQLQueryConstructor(schema, NewMySQLDialect()).StrictGrouping(true)
+	mixed.Select("status", "customer")
+	mixed.GroupBy("status")
+	if _, _, err := mixed.Build(ctx); err == nil || !strings.Contains(err.Error(), "customer") {
+		t.Errorf("Expected grouping error for non-grouped column, got: %v", err)
+	}
+
+	// 非严格模式下交给数据库处理
+	lenient := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	lenient.Select("status", "customer")
+	lenient.GroupBy("status")
+	if _, _, err := lenient.Build(ctx); err != nil {
+		t.Errorf("Expected non-strict mode to build, got: %v", err)
+	}
+
+	t.Logf("✓ Strict grouping: %s", sql)
+}
+
+// TestNowCondition 测试 Now() 渲染为数据库时间函数且不绑定参数
+func TestNowCondition(t *testing.T) {
+	schema := NewBaseSchema("sessions")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("expires_at", TypeTime).Build())
+
+	tests := []struct {
+		dialect  SQLDialect
+		expected string
+	}{
+		{NewMySQLDialect(), "WHERE `expires_at` < CURRENT_TIMESTAMP"},
+		{NewSQLiteDialect(), "WHERE `expires_at` < CURRENT_TIMESTAMP"},
+		{NewPostgreSQLDialect(), `WHERE "expires_at" < NOW()`},
+		{NewSQLServerDialect(), "WHERE [expires_at] < CURRENT_TIMESTAMP"},
+	}
+
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, tt.dialect)
+		qc.Where(Lt("expires_at", Now()))
+
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if !strings.Contains(sql, tt.expected) {
+			t.Errorf("%s: expected %q in SQL: %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if len(args) != 0 {
+			t.Errorf("%s: expected no args, got %v", tt.dialect.Name(), args)
+		}
+	}
+
+	// 与普通参数混用时占位符编号保持连续
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Between("expires_at", Now(), "2030-01-01")).Where(Eq("id", 7))
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.Contains(sql, `"expires_at" BETWEEN NOW() AND $1 AND "id" = $2`) || len(args) != 2 {
+		t.Errorf("Unexpected SQL/args: %s %v", sql, args)
+	}
+}
+
+// TestLikeAnyCondition 测试 LikeAny/NotLikeAll 在 PostgreSQL 使用数组形式，其他方言展开为 OR/AND 组
+func TestLikeAnyCondition(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+
+	tests := []struct {
+		dialect   SQLDialect
+		condition Condition
+		expected  string
+	}{
+		{NewPostgreSQLDialect(), LikeAny("name", "A%", "B%"), `WHERE "name" LIKE ANY (ARRAY[$1, $2]) AND "id" > $3`},
+		{NewPostgreSQLDialect(), NotLikeAll("name", "A%", "B%"), `WHERE "name" NOT LIKE ALL (ARRAY[$1, $2]) AND "id" > $3`},
+		{NewMySQLDialect(), LikeAny("name", "A%", "B%"), "WHERE (`name` LIKE ? OR `name` LIKE ?) AND `id` > ?"},
+		{NewMySQLDialect(), NotLikeAll("name", "A%", "B%"), "WHERE (`name` NOT LIKE ? AND `name` NOT LIKE ?) AND `id` > ?"},
+		{NewSQLiteDialect(), LikeAny("name", "A%", "B%"), "WHERE (`name` LIKE ? OR `name` LIKE ?) AND `id` > ?"},
+		{NewSQLServerDialect(), LikeAny("name", "A%", "B%"), "WHERE ([name] LIKE @p1 OR [name] LIKE @p2) AND [id] > @p3"},
+	}
+
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, tt.dialect)
+		qc.Where(tt.condition).Where(Gt("id", 10))
+
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if !strings.Contains(sql, tt.expected) {
+			t.Errorf("%s: expected %q in SQL: %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if len(args) != 3 || args[0] != "A%" || args[1] != "B%" || args[2] != 10 {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+	}
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Where(LikeAny("name"))
+	if _, _, err := qc.Build(context.Background()); err == nil {
+		t.Error("Expected error for LikeAny without patterns")
+	}
+}
+
+// TestWithinBBoxCondition 测试经纬度列的包围盒范围条件
+func TestWithinBBoxCondition(t *testing.T) {
+	schema := NewBaseSchema("places")
+	schema.AddField(NewField("lat", TypeFloat).Build())
+	schema.AddField(NewField("lng", TypeFloat).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(WithinBBox("lat", "lng", 30.5, 120.0, 31.5, 122.0))
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `WHERE ("lat" BETWEEN $1 AND $2 AND "lng" BETWEEN $3 AND $4)`
+	if !strings.Contains(sql, expected) {
+		t.Errorf("Expected %q in SQL: %s", expected, sql)
+	}
+	if len(args) != 4 || args[0] != 30.5 || args[1] != 31.5 || args[2] != 120.0 || args[3] != 122.0 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// 跨越 180° 经线
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Where(WithinBBox("lat", "lng", -10, 170, 10, -170))
+	sql, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected = "WHERE (`lat` BETWEEN ? AND ? AND (`lng` >= ? OR `lng` <= ?))"
+	if !strings.Contains(sql, expected) {
+		t.Errorf("Expected %q in SQL: %s", expected, sql)
+	}
+}
+
+// TestGeomWithinCondition 测试 PostGIS 包围盒条件及不支持空间函数的方言
+func TestGeomWithinCondition(t *testing.T) {
+	schema := NewBaseSchema("places")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("geom", TypeBinary).Build())
+
+	bbox := BBox{MinLat: 30.5, MinLng: 120.0, MaxLat: 31.5, MaxLng: 122.0}
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Gt("id", 0)).Where(GeomWithin("geom", bbox))
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `"id" > $1 AND ST_Within("geom", ST_MakeEnvelope($2, $3, $4, $5, 4326))`
+	if !strings.Contains(sql, expected) {
+		t.Errorf("Expected %q in SQL: %s", expected, sql)
+	}
+	if len(args) != 5 || args[1] != 120.0 || args[2] != 30.5 || args[3] != 122.0 || args[4] != 31.5 {
+		t.Errorf("Unexpected args (expected lng/lat envelope order): %v", args)
+	}
+
+	bbox.SRID = 3857
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(GeomWithin("geom", bbox))
+	if sql, _, _ := qc.Build(context.Background()); !strings.Contains(sql, ", 3857))") {
+		t.Errorf("Expected custom SRID in SQL: %s", sql)
+	}
+
+	for _, dialect := range []SQLDialect{NewMySQLDialect(), NewSQLiteDialect(), NewSQLServerDialect()} {
+		qc := NewSQLQueryConstructor(schema, dialect)
+		qc.Where(GeomWithin("geom", bbox))
+		if _, _, err := qc.Build(context.Background()); err == nil {
+			t.Errorf("%s: expected GeomWithin to be unsupported", dialect.Name())
+		}
+	}
+}
+
+// TestSQLQueryConstructorSelectAllPlus 测试全部列加计算表达式
+func TestSQLQueryConstructorSelectAllPlus(t *testing.T) {
+	schema := NewBaseSchema("order_items")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("price", TypeDecimal).Build())
+	schema.AddField(NewField("qty", TypeInteger).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.SelectAllPlus("(price * qty) AS total").Where(Gt("qty", 0))
+
+	sql, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "SELECT `id`, `price`, `qty`, (price * qty) AS total FROM `order_items` WHERE `qty` > ?"
+	if sql != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.SelectAllPlus(`("price" * "qty") AS "total"`, "1 AS one")
+	sql, _, _ = qc.Build(context.Background())
+	if !strings.HasPrefix(sql, `SELECT "id", "price", "qty", ("price" * "qty") AS "total", 1 AS one FROM`) {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+}
+
+// TestTupleInCondition 测试行值 IN 条件：PostgreSQL/MySQL 生成行值语法，SQLite 展开为 OR 连接的 AND 组
+func TestTupleInCondition(t *testing.T) {
+	schema := NewBaseSchema("pages")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("tenant_id", TypeInteger).Build())
+	schema.AddField(NewField("slug", TypeString).Build())
+
+	rows := [][]interface{}{{1, "a"}, {1, "b"}}
+	tests := []struct {
+		dialect  SQLDialect
+		expected string
+	}{
+		{NewPostgreSQLDialect(), `SELECT "id", "tenant_id", "slug" FROM "pages" WHERE "id" > $1 AND ("tenant_id", "slug") IN (($2, $3), ($4, $5))`},
+		{NewMySQLDialect(), "SELECT `id`, `tenant_id`, `slug` FROM `pages` WHERE `id` > ? AND (`tenant_id`, `slug`) IN ((?, ?), (?, ?))"},
+		{NewSQLiteDialect(), "SELECT `id`, `tenant_id`, `slug` FROM `pages` WHERE `id` > ? AND ((`tenant_id` = ? AND `slug` = ?) OR (`tenant_id` = ? AND `slug` = ?))"},
+	}
+
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, tt.dialect)
+		qc.Where(Gt("id", 0)).Where(TupleIn([]string{"tenant_id", "slug"}, rows))
+
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		want := []interface{}{0, 1, "a", 1, "b"}
+		if len(args) != len(want) {
+			t.Fatalf("%s: expected args %v, got %v", tt.dialect.Name(), want, args)
+		}
+		for i := range want {
+			if args[i] != want[i] {
+				t.Errorf("%s: expected argument %v at index %d, got %v", tt.dialect.Name(), want[i], i, args[i])
+			}
+		}
+	}
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(TupleIn([]string{"tenant_id", "slug"}, [][]interface{}{{1}}))
+	if _, _, err := qc.Build(context.Background()); err == nil {
+		t.Error("Expected error for TupleIn row with mismatched width")
+	}
+}
+
+// TestSQLQueryConstructorArgCount 测试 ArgCount 与 Build 生成的参数个数一致，超过方言上限时报错
+func TestSQLQueryConstructorArgCount(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	schema.AddField(NewField("age", TypeInteger).Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Eq("name", "alice")).
+		WhereAll(In("id", 1, 2, 3), Between("age", 18, 65)).
+		Where(Not(Lt("created_at", Now())))
+
+	_, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if got := qc.ArgCount(); got != len(args) || got != 6 {
+		t.Errorf("Expected ArgCount to be %d, got %d", len(args), got)
+	}
+
+	ids := make([]interface{}, NewSQLServerDialect().MaxParameters()+1)
+	for i := range ids {
+		ids[i] = i
+	}
+	qc = NewSQLQueryConstructor(schema, NewSQLServerDialect())
+	qc.Where(In("id", ids...))
+	if qc.ArgCount() != len(ids) {
+		t.Errorf("Expected ArgCount %d, got %d", len(ids), qc.ArgCount())
+	}
+	_, _, err = qc.Build(context.Background())
+	var limitErr *ParameterLimitError
+	if !errors.As(err, &limitErr) || limitErr.Count != len(ids) || limitErr.Max != 2100 {
+		t.Errorf("Expected ParameterLimitError, got %v", err)
+	}
+}
+
+// TestSQLQueryConstructorCombineExisting 测试已有条件分组后与新条件用 OR 组合
+func TestSQLQueryConstructorCombineExisting(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("age", TypeInteger).Build())
+	schema.AddField(NewField("role", TypeString).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Eq("status", "active")).Where(Gt("age", 18))
+	qc.CombineExisting("or", Eq("role", "admin"))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "status", "age", "role" FROM "users" WHERE (("status" = $1 AND "age" > $2) OR "role" = $3)`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if len(args) != 3 || args[0] != "active" || args[1] != 18 || args[2] != "admin" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// 之后的 Where 仍以 AND 追加到组合结果上
+	qc.Where(Lt("age", 65))
+	sql, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.HasSuffix(sql, `OR "role" = $3) AND "age" < $4`) {
+		t.Errorf("Unexpected SQL after Where: %s", sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.CombineExisting("OR", Eq("role", "admin"), Eq("role", "owner"))
+	if sql, _, _ = qc.Build(context.Background()); sql != "SELECT `status`, `age`, `role` FROM `users` WHERE (`role` = ? OR `role` = ?)" {
+		t.Errorf("Unexpected SQL without existing conditions: %s", sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Eq("status", "active"))
+	qc.CombineExisting("XOR", Eq("role", "admin"))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "XOR") {
+		t.Errorf("Expected error for unknown connective, got %v", err)
+	}
+}
+
+// TestNullConditions 测试 IS NULL / IS NOT NULL 条件不生成参数
+func TestNullConditions(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("deleted_at", TypeTime).Null(true).Build())
+	schema.AddField(NewField("email", TypeString).Null(true).Build())
+
+	tests := []struct {
+		dialect  SQLDialect
+		expected string
+	}{
+		{NewPostgreSQLDialect(), `SELECT "deleted_at", "email" FROM "users" WHERE "deleted_at" IS NULL AND "email" IS NOT NULL`},
+		{NewMySQLDialect(), "SELECT `deleted_at`, `email` FROM `users` WHERE `deleted_at` IS NULL AND `email` IS NOT NULL"},
+		{NewSQLServerDialect(), "SELECT [deleted_at], [email] FROM [users] WHERE [deleted_at] IS NULL AND [email] IS NOT NULL"},
+	}
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, tt.dialect)
+		qc.Where(IsNull("deleted_at")).Where(IsNotNull("email"))
+
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if len(args) != 0 || qc.ArgCount() != 0 {
+			t.Errorf("%s: expected no args, got %v", tt.dialect.Name(), args)
+		}
+	}
+
+	// 后续条件的占位符编号不受影响
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.WhereAll(IsNull("deleted_at"), Eq("email", "a@example.com"))
+	sql, args, _ := qc.Build(context.Background())
+	if !strings.HasSuffix(sql, `("deleted_at" IS NULL AND "email" = $1)`) || len(args) != 1 {
+		t.Errorf("Unexpected SQL %s with args %v", sql, args)
+	}
+}
+
+// TestNotInCondition 测试 NOT IN 条件的占位符，以及空列表生成恒假条件
+func TestNotInCondition(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("age", TypeInteger).Build())
+
+	tests := []struct {
+		dialect  SQLDialect
+		expected string
+	}{
+		{NewMySQLDialect(), "SELECT `id`, `age` FROM `users` WHERE `id` NOT IN (?, ?, ?) AND `age` > ?"},
+		{NewPostgreSQLDialect(), `SELECT "id", "age" FROM "users" WHERE "id" NOT IN ($1, $2, $3) AND "age" > $4`},
+	}
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, tt.dialect)
+		qc.Where(NotIn("id", 1, 2, 3)).Where(Gt("age", 18))
+
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if len(args) != 4 || args[0] != 1 || args[2] != 3 || args[3] != 18 {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+	}
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(NotIn("id")).Where(Gt("age", 18))
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if sql != `SELECT "id", "age" FROM "users" WHERE 1=1 AND "age" > $1` || len(args) != 1 {
+		t.Errorf("Expected always-true predicate for empty NotIn, got %s %v", sql, args)
+	}
+}
+
+// TestILikeCondition 测试大小写不敏感匹配按方言渲染
+func TestILikeCondition(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+
+	tests := []struct {
+		dialect  SQLDialect
+		expected string
+	}{
+		{NewPostgreSQLDialect(), `SELECT "id", "name" FROM "users" WHERE "id" > $1 AND "name" ILIKE $2`},
+		{NewMySQLDialect(), "SELECT `id`, `name` FROM `users` WHERE `id` > ? AND LOWER(`name`) LIKE LOWER(?)"},
+		{NewSQLiteDialect(), "SELECT `id`, `name` FROM `users` WHERE `id` > ? AND LOWER(`name`) LIKE LOWER(?)"},
+		{NewSQLServerDialect(), "SELECT [id], [name] FROM [users] WHERE [id] > @p1 AND LOWER([name]) LIKE LOWER(@p2)"},
+	}
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, tt.dialect)
+		qc.Where(Gt("id", 0)).Where(ILike("name", "%Alice%"))
+
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if len(args) != 2 || args[1] != "%Alice%" {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+	}
+}
+
+// TestSQLQueryConstructorFacet 测试分面统计查询结构以及 WHERE 条件
+func TestSQLQueryConstructorFacet(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Gt("amount", 100)).(*SQLQueryConstructor).StrictGrouping(true).Facet("status")
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "status", COUNT(*) AS "count" FROM "orders" WHERE "amount" > $1 GROUP BY "status" ORDER BY "count" DESC`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if len(args) != 1 || args[0] != 100 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+// TestSQLQueryConstructorSelectAggregate 测试聚合函数选择列
+func TestSQLQueryConstructorSelectAggregate(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.SelectAggregate("COUNT", "id", "total")
+	sql, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "SELECT COUNT(`id`) AS `total` FROM `orders`"; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Select("status")
+	qc.SelectAggregate("count", "", "orders").SelectAggregate("sum", "amount", "revenue").GroupBy("status")
+	sql, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "status", COUNT(*) AS "orders", SUM("amount") AS "revenue" FROM "orders" GROUP BY "status"`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	for _, bad := range []struct{ fn, field string }{{"MEDIAN", "amount"}, {"SUM", ""}} {
+		qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+		qc.SelectAggregate(bad.fn, bad.field, "x")
+		if _, _, err := qc.Build(context.Background()); err == nil {
+			t.Errorf("Expected error for %s(%s)", bad.fn, bad.field)
+		}
+	}
+
+	// 连接查询中的限定列按 表.列 分别转义
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Select("status")
+	qc.Join("refunds", EqCol("refunds.order_id", "orders.id")).
+		SelectAggregate("SUM", "refunds.amount", "").GroupBy("status")
+	sql, _, err = qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "SELECT `status`, SUM(`refunds`.`amount`) AS `sum_refunds_amount` FROM `orders` INNER JOIN `refunds` ON `refunds`.`order_id` = `orders`.`id` GROUP BY `status`"; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+// TestSQLQueryConstructorDistinctOn 测试 DISTINCT ON 仅在 PostgreSQL 上可用
+func TestSQLQueryConstructorDistinctOn(t *testing.T) {
+	schema := NewBaseSchema("events")
+	schema.AddField(NewField("user_id", TypeInteger).Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).DistinctOn("user_id")
+	qc.OrderBy("user_id", "ASC").OrderBy("created_at", "DESC")
+	sql, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT DISTINCT ON ("user_id") "user_id", "created_at" FROM "events" ORDER BY "user_id" ASC, "created_at" DESC`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	for _, dialect := range []SQLDialect{NewMySQLDialect(), NewSQLiteDialect(), NewSQLServerDialect()} {
+		if _, _, err := NewSQLQueryConstructor(schema, dialect).DistinctOn("user_id").Build(context.Background()); err == nil {
+			t.Errorf("%s: expected DISTINCT ON to be rejected", dialect.Name())
+		}
+	}
+
+	// 连接查询中的限定列按 表.列 分别转义
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).DistinctOn("events.user_id")
+	qc.Join("users", EqCol("users.id", "events.user_id"))
+	if sql, _, err = qc.Build(context.Background()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.HasPrefix(sql, `SELECT DISTINCT ON ("events"."user_id") `) {
+		t.Errorf("Expected qualified DISTINCT ON column, got %s", sql)
+	}
+}
+
+// TestInSubqueryCondition 测试子查询参数合并以及 PostgreSQL 占位符连续编号
+func TestInSubqueryCondition(t *testing.T) {
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("status", TypeString).Build())
+	orders := NewBaseSchema("orders")
+	orders.AddField(NewField("user_id", TypeInteger).Build())
+	orders.AddField(NewField("amount", TypeFloat).Build())
+
+	dialect := NewPostgreSQLDialect()
+	sub := NewSQLQueryConstructor(orders, dialect).Select("user_id").Where(Gt("amount", 100)).Where(Eq("user_id", 7))
+	qc := NewSQLQueryConstructor(users, dialect).
+		Where(Eq("status", "active")).
+		Where(InSubquery("id", sub)).
+		Where(Ne("id", 9))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "id", "status" FROM "users" WHERE "status" = $1 AND "id" IN (SELECT "user_id" FROM "orders" WHERE "amount" > $2 AND "user_id" = $3) AND "id" != $4`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"active", 100, 7, 9}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	if got := qc.(*SQLQueryConstructor).ArgCount(); got != 4 {
+		t.Errorf("Expected ArgCount 4, got %d", got)
+	}
+
+	// 子查询本身独立构建时仍从 $1 开始
+	subSQL, _, err := sub.Build(context.Background())
+	if err != nil || !strings.Contains(subSQL, `"amount" > $1`) {
+		t.Errorf("Expected standalone subquery to start at $1, got %s (%v)", subSQL, err)
+	}
+}
+
+// TestRawCondition 测试 Raw 片段加括号输出并按方言改写占位符
+func TestRawCondition(t *testing.T) {
+	schema := NewBaseSchema("events")
+	schema.AddField(NewField("kind", TypeString).Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+
+	raw := Raw("date_trunc('day', created_at) = ? AND note <> 'why?'", "2024-01-01")
+	tests := []struct {
+		dialect  SQLDialect
+		expected string
+	}{
+		{NewMySQLDialect(), "SELECT `kind`, `created_at` FROM `events` WHERE `kind` = ? AND (date_trunc('day', created_at) = ? AND note <> 'why?')"},
+		{NewPostgreSQLDialect(), `SELECT "kind", "created_at" FROM "events" WHERE "kind" = $1 AND (date_trunc('day', created_at) = $2 AND note <> 'why?')`},
+	}
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, tt.dialect).Where(Eq("kind", "click")).Where(raw)
+		sql, args, err := qc.Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"click", "2024-01-01"}) {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+	}
+
+	argIndex := 1
+	if _, _, err := NewPostgreSQLDialect().TranslateCondition(Raw("a = ? AND b = ?", 1), &argIndex); err == nil {
+		t.Error("Expected error when placeholder count does not match args")
+	}
+}
+
+// TestRawConditionWithOr 测试含 OR 的 Raw 片段与其他条件组合时不会打破 AND 的优先级
+func TestRawConditionWithOr(t *testing.T) {
+	schema := NewBaseSchema("docs")
+	schema.AddField(NewField("tenant_id", TypeInteger).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Eq("tenant_id", 7)).Where(Raw("owner = ? OR public = ?", 1, true))
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "tenant_id" FROM "docs" WHERE "tenant_id" = $1 AND (owner = $2 OR public = $3)`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{7, 1, true}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(And(Eq("tenant_id", 7), Raw("a = ? OR b = ?", 1, 2)))
+	if sql, _, err = qc.Build(ctx); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "tenant_id" FROM "docs" WHERE ("tenant_id" = $1 AND (a = $2 OR b = $3))`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+// TestSQLQueryConstructorLeftJoinLateral 测试 LATERAL 连接语法和参数顺序
+func TestSQLQueryConstructorLeftJoinLateral(t *testing.T) {
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("status", TypeString).Build())
+	orders := NewBaseSchema("orders")
+	orders.AddField(NewField("user_id", TypeInteger).Build())
+	orders.AddField(NewField("amount", TypeFloat).Build())
+	orders.AddField(NewField("created_at", TypeTime).Build())
+
+	dialect := NewPostgreSQLDialect()
+	recent := NewSQLQueryConstructor(orders, dialect)
+	recent.Where(Raw(`"orders"."user_id" = "users"."id"`)).Where(Gt("amount", 10)).OrderBy("created_at", "DESC").Limit(3)
+
+	qc := NewSQLQueryConstructor(users, dialect).
+		LeftJoinLateral(recent, "recent", nil).
+		LeftJoinLateral(NewSQLQueryConstructor(orders, dialect).SelectAggregate("count", "", "total"), "stats", Raw(`"stats"."total" > ?`, 1))
+	qc.Where(Eq("status", "active"))
+
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "users"."id", "users"."status" FROM "users"` +
+		` LEFT JOIN LATERAL (SELECT "user_id", "amount", "created_at" FROM "orders" WHERE ("orders"."user_id" = "users"."id") AND "amount" > $1 ORDER BY "created_at" DESC LIMIT 3) "recent" ON TRUE` +
+		` LEFT JOIN LATERAL (SELECT COUNT(*) AS "total" FROM "orders") "stats" ON ("stats"."total" > $2)` +
+		` WHERE "status" = $3`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{10, 1, "active"}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	if got := qc.ArgCount(); got != 3 {
+		t.Errorf("Expected ArgCount 3, got %d", got)
+	}
+
+	for _, d := range []SQLDialect{NewMySQLDialect(), NewSQLiteDialect()} {
+		sub := NewSQLQueryConstructor(orders, d)
+		if _, _, err := NewSQLQueryConstructor(users, d).LeftJoinLateral(sub, "o", nil).Build(context.Background()); err == nil {
+			t.Errorf("%s: expected LATERAL to be rejected", d.Name())
+		}
+	}
+}
+
+// TestColumnComparisonConditions 测试列对列比较不产生参数且两侧列名都按方言转义
+func TestColumnComparisonConditions(t *testing.T) {
+	tests := []struct {
+		dialect  SQLDialect
+		cond     Condition
+		expected string
+	}{
+		{NewMySQLDialect(), GtCol("a.price", "b.cost"), "`a`.`price` > `b`.`cost`"},
+		{NewPostgreSQLDialect(), EqCol("a.id", "b.parent_id"), `"a"."id" = "b"."parent_id"`},
+		{NewSQLiteDialect(), LteCol("start_at", "end_at"), "`start_at` <= `end_at`"},
+		{NewSQLServerDialect(), NeCol("a.owner", "b.owner"), "[a].[owner] != [b].[owner]"},
+	}
+	for _, tt := range tests {
+		argIndex := 1
+		sql, args, err := tt.dialect.TranslateCondition(tt.cond, &argIndex)
+		if err != nil {
+			t.Fatalf("%s: translate failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if len(args) != 0 || argIndex != 1 {
+			t.Errorf("%s: expected no args, got %v (next index %d)", tt.dialect.Name(), args, argIndex)
+		}
+	}
+
+	schema := NewBaseSchema("products")
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(GteCol("price", "cost")).Where(Eq("active", true))
+	sql, args, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT * FROM "products" WHERE "price" >= "cost" AND "active" = $1`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if len(args) != 1 || qc.ArgCount() != 1 {
+		t.Errorf("Expected a single arg, got %v", args)
+	}
+}
+
+// TestSQLQueryConstructorAggregateAliases 测试未指定别名的聚合自动生成可预测且不冲突的列名
+func TestSQLQueryConstructorAggregateAliases(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Select("status")
+	qc.SelectAggregate("count", "", "").SelectAggregate("sum", "amount", "").GroupBy("status")
+	sql, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "status", COUNT(*) AS "count", SUM("amount") AS "sum_amount" FROM "orders" GROUP BY "status"`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	repo := newSQLiteTestRepository(t, "aggregate_alias.db")
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "CREATE TABLE orders (status TEXT, amount REAL)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO orders VALUES ('paid', 10), ('paid', 5)"); err != nil {
+		t.Fatal(err)
+	}
+	qc = NewSQLQueryConstructor(schema, NewSQLiteDialect())
+	qc.SelectAggregate("count", "", "").SelectAggregate("sum", "amount", "")
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	rows, err := repo.Query(ctx, sql, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	result, err := ScanMaps(rows, nil)
+	if err != nil || len(result) != 1 {
+		t.Fatalf("ScanMaps failed: %v %v", result, err)
+	}
+	if result[0]["count"] != int64(2) || result[0]["sum_amount"] != float64(15) {
+		t.Errorf("Expected count and sum_amount keys, got %v", result[0])
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.SelectAggregate("count", "", "").SelectAggregate("count", "", "")
+	if _, _, err := qc.Build(context.Background()); err == nil {
+		t.Error("Expected error for duplicate aggregate aliases")
+	}
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Select("status")
+	qc.SelectAggregate("max", "amount", "status")
+	if _, _, err := qc.Build(context.Background()); err == nil {
+		t.Error("Expected error for alias colliding with a selected column")
+	}
+}
+
+// TestSQLQueryConstructorPaginateAndCount 测试分页偏移和去掉排序、保留条件参数的计数查询
+func TestSQLQueryConstructorPaginateAndCount(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Eq("status", "paid"))
+	qc.OrderBy("amount", "DESC")
+	qc.Paginate(3, 20)
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "status", "amount" FROM "orders" WHERE "status" = $1 ORDER BY "amount" DESC LIMIT 20 OFFSET 40`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	sql, args, err = qc.BuildCount(ctx)
+	if err != nil {
+		t.Fatalf("BuildCount failed: %v", err)
+	}
+	if expected := `SELECT COUNT(*) FROM "orders" WHERE "status" = $1`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"paid"}) {
+		t.Errorf("Expected WHERE args to be kept, got %v", args)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Select("status")
+	qc.Distinct().Where(Gt("amount", 10))
+	sql, _, err = qc.BuildCount(ctx)
+	if err != nil {
+		t.Fatalf("BuildCount failed: %v", err)
+	}
+	if expected := `SELECT COUNT(*) FROM (SELECT DISTINCT "status" FROM "orders" WHERE "amount" > $1) "count_query"`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Paginate(0, 20)
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for page < 1")
+	}
+}
+
+// TestSQLQueryConstructorReset 测试每个 Reset 方法只清除对应子句
+func TestSQLQueryConstructorReset(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+
+	newQuery := func() *SQLQueryConstructor {
+		qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+		qc.Select("status", "amount")
+		qc.Where(Eq("status", "paid"))
+		qc.OrderBy("amount", "DESC")
+		qc.Limit(10)
+		qc.Offset(20)
+		return qc
+	}
+
+	tests := []struct {
+		name     string
+		reset    func(qc *SQLQueryConstructor) *SQLQueryConstructor
+		expected string
+	}{
+		{"where", (*SQLQueryConstructor).ResetWhere, `SELECT "status", "amount" FROM "orders" ORDER BY "amount" DESC LIMIT 10 OFFSET 20`},
+		{"order by", (*SQLQueryConstructor).ResetOrderBy, `SELECT "status", "amount" FROM "orders" WHERE "status" = $1 LIMIT 10 OFFSET 20`},
+		{"select", (*SQLQueryConstructor).ResetSelect, `SELECT "status", "amount" FROM "orders" WHERE "status" = $1 ORDER BY "amount" DESC LIMIT 10 OFFSET 20`},
+		{"limit", (*SQLQueryConstructor).ResetLimit, `SELECT "status", "amount" FROM "orders" WHERE "status" = $1 ORDER BY "amount" DESC`},
+	}
+	for _, tt := range tests {
+		sql, _, err := tt.reset(newQuery()).Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.name, err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, sql)
+		}
+	}
+}
+
+// TestSQLQueryConstructorClone 测试修改克隆不会影响原构造器
+func TestSQLQueryConstructorClone(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+	ctx := context.Background()
+
+	original := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	original.Select("status")
+	original.Where(Eq("status", "paid"))
+	original.OrderBy("amount", "DESC")
+	original.Limit(10)
+	before, beforeArgs, err := original.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	clone := original.Clone()
+	clone.Where(Gt("amount", 100)).Select("amount").OrderBy("status", "ASC").Limit(1)
+	cloned, _, err := clone.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build clone failed: %v", err)
+	}
+	if expected := `SELECT "status", "amount" FROM "orders" WHERE "status" = $1 AND "amount" > $2 ORDER BY "amount" DESC, "status" ASC LIMIT 1`; cloned != expected {
+		t.Errorf("Expected %s, got %s", expected, cloned)
+	}
+
+	after, afterArgs, err := original.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if after != before || !reflect.DeepEqual(afterArgs, beforeArgs) {
+		t.Errorf("Original changed after mutating clone: %s %v", after, afterArgs)
+	}
+}
+
+// TestInSubquerySingleColumn 测试子查询必须只选择一列，且与普通条件一起时占位符连续编号
+func TestInSubquerySingleColumn(t *testing.T) {
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("status", TypeString).Build())
+	orders := NewBaseSchema("orders")
+	orders.AddField(NewField("user_id", TypeInteger).Build())
+	orders.AddField(NewField("amount", TypeFloat).Build())
+
+	dialect := NewSQLServerDialect()
+	sub := NewSQLQueryConstructor(orders, dialect).Select("user_id").Where(Gt("amount", 100))
+	sql, args, err := NewSQLQueryConstructor(users, dialect).
+		Where(Eq("status", "active")).
+		Where(InSubquery("id", sub)).
+		Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT [id], [status] FROM [users] WHERE [status] = @p1 AND [id] IN (SELECT [user_id] FROM [orders] WHERE [amount] > @p2)`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"active", 100}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	for _, sub := range []QueryConstructor{
+		NewSQLQueryConstructor(orders, dialect).Select("user_id", "amount"),
+		NewSQLQueryConstructor(orders, dialect),
+	} {
+		_, _, err := NewSQLQueryConstructor(users, dialect).Where(InSubquery("id", sub)).Build(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "exactly one column") {
+			t.Errorf("Expected single-column error, got %v", err)
+		}
+	}
+}
+
+// TestExistsCondition 测试 EXISTS / NOT EXISTS 不输出左侧列，参数按顺序传递并接着编号
+func TestExistsCondition(t *testing.T) {
+	users := NewBaseSchema("users")
+	users.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	users.AddField(NewField("status", TypeString).Build())
+	orders := NewBaseSchema("orders")
+	orders.AddField(NewField("user_id", TypeInteger).Build())
+	orders.AddField(NewField("amount", TypeFloat).Build())
+
+	dialect := NewPostgreSQLDialect()
+	sub := NewSQLQueryConstructor(orders, dialect).
+		Select("user_id").
+		Where(EqCol("orders.user_id", "users.id")).
+		Where(Gt("amount", 100))
+	sql, args, err := NewSQLQueryConstructor(users, dialect).
+		Where(Eq("status", "active")).
+		Where(Exists(sub)).
+		Where(NotExists(NewSQLQueryConstructor(orders, dialect).Select("user_id").Where(Lt("amount", 0)))).
+		Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "id", "status" FROM "users" WHERE "status" = $1 AND EXISTS (SELECT "user_id" FROM "orders" WHERE "orders"."user_id" = "users"."id" AND "amount" > $2) AND NOT EXISTS (SELECT "user_id" FROM "orders" WHERE "amount" < $3)`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"active", 100, 0}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+// TestJSONExtractCondition 测试各方言的 JSON 路径提取语法，比较值作为参数绑定
+func TestJSONExtractCondition(t *testing.T) {
+	schema := NewBaseSchema("events")
+	schema.AddField(NewField("meta", TypeJSON).Build())
+
+	tests := []struct {
+		dialect  SQLDialect
+		path     string
+		expected string
+	}{
+		{NewPostgreSQLDialect(), "$.status", `SELECT "meta" FROM "events" WHERE "meta"->>'status' = $1`},
+		{NewPostgreSQLDialect(), "$.owner.tags[0]", `SELECT "meta" FROM "events" WHERE "meta"#>>'{owner,tags,0}' = $1`},
+		{NewMySQLDialect(), "$.status", "SELECT `meta` FROM `events` WHERE JSON_UNQUOTE(JSON_EXTRACT(`meta`, '$.status')) = ?"},
+		{NewSQLiteDialect(), "$.status", "SELECT `meta` FROM `events` WHERE json_extract(`meta`, '$.status') = ?"},
+		{NewSQLServerDialect(), "$.status", "SELECT [meta] FROM [events] WHERE JSON_VALUE([meta], '$.status') = @p1"},
+	}
+	for _, tt := range tests {
+		sql, args, err := NewSQLQueryConstructor(schema, tt.dialect).
+			Where(Eq(JSONExtract("meta", tt.path), "active")).
+			Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"active"}) {
+			t.Errorf("%s: expected parameterized value, got %v", tt.dialect.Name(), args)
+		}
+	}
+
+	_, _, err := NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).
+		Where(Eq(JSONExtract("meta", "$.status'; DROP TABLE events; --"), "x")).
+		Build(context.Background())
+	if err == nil {
+		t.Error("Expected error for invalid JSON path")
+	}
+}
+
+// TestConditionTranslationCache 测试共享的条件子树只翻译一次且结果与不缓存时一致
+func TestConditionTranslationCache(t *testing.T) {
+	schema := NewBaseSchema("docs")
+	schema.AddField(NewField("tenant_id", TypeInteger).Build())
+	schema.AddField(NewField("deleted", TypeBoolean).Build())
+	schema.AddField(NewField("public", TypeBoolean).Build())
+
+	tenant := And(Eq("tenant_id", 1), Not(Eq("deleted", true)))
+	shared := Or(tenant, Eq("public", true))
+	sql, args, err := NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).
+		Where(tenant).
+		Where(shared).
+		Where(Or(shared, Eq("tenant_id", 2))).
+		Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "tenant_id", "deleted", "public" FROM "docs" WHERE ("tenant_id" = $1 AND NOT ("deleted" = $2)) AND (("tenant_id" = $3 AND NOT ("deleted" = $4)) OR "public" = $5) AND ((("tenant_id" = $6 AND NOT ("deleted" = $7)) OR "public" = $8) OR "tenant_id" = $9)`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, true, 1, true, true, 1, true, true, 2}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	argIndex := 1
+	cached := &DefaultSQLTranslator{dialect: NewPostgreSQLDialect(), argIndex: &argIndex, cache: make(map[Condition]cachedTranslation)}
+	if _, _, err := cached.translate(shared); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cached.cache[tenant]; !ok {
+		t.Error("Expected nested composite condition to be cached")
+	}
+	plainIndex := 1
+	plain := &DefaultSQLTranslator{dialect: NewPostgreSQLDialect(), argIndex: &plainIndex}
+	plainSQL, _, _ := shared.Translate(plain)
+	plainSQL, _, _ = shared.Translate(plain)
+	cachedSQL, _, _ := cached.translate(shared)
+	if cachedSQL != plainSQL {
+		t.Errorf("Expected cached translation %s to match %s", cachedSQL, plainSQL)
+	}
+}
+
+// BenchmarkSharedConditionTree 深层共享条件树的构建
+func BenchmarkSharedConditionTree(b *testing.B) {
+	schema := NewBaseSchema("docs")
+	schema.AddField(NewField("tenant_id", TypeInteger).Build())
+
+	tree := Eq("tenant_id", 0)
+	for i := 1; i <= 8; i++ {
+		tree = Or(And(tree, Gt("tenant_id", i)), And(tree, Lt("tenant_id", -i)))
+	}
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(tree)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := qc.Build(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestArrayConditions 测试 PostgreSQL 的 @> / && 谓词，不支持数组的方言返回错误
+func TestArrayConditions(t *testing.T) {
+	schema := NewBaseSchema("posts")
+	schema.AddField(NewField("tags", TypeArray).Build())
+
+	sql, args, err := NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).
+		Where(ArrayContains("tags", []string{"go", "sql"})).
+		Where(ArrayOverlaps("tags", "news")).
+		Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "tags" FROM "posts" WHERE "tags" @> ARRAY[$1, $2] AND "tags" && ARRAY[$3]`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"go", "sql", "news"}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	for _, dialect := range []SQLDialect{NewMySQLDialect(), NewSQLiteDialect()} {
+		_, _, err := NewSQLQueryConstructor(schema, dialect).Where(ArrayContains("tags", "go")).Build(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "PostgreSQL only") {
+			t.Errorf("%s: expected array support error, got %v", dialect.Name(), err)
+		}
+	}
+}
+
+// TestSQLQueryConstructorOrderByValidation 测试排序方向和列名校验，以及 OrderByRaw
+func TestSQLQueryConstructorOrderByValidation(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.OrderBy("amount", "desc; DROP TABLE orders")
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for invalid direction")
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.OrderBy("amount`; DROP TABLE orders; --", "ASC")
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.SelectAggregate("sum", "amount", "total").GroupBy("status")
+	qc.Select("status")
+	qc.OrderBy("total", "desc")
+	qc.OrderBy("status", "Asc")
+	qc.OrderByRaw("FIELD(`status`, 'new', 'done')")
+	sql, _, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "SELECT SUM(`amount`) AS `total`, `status` FROM `orders` GROUP BY `status` ORDER BY `total` DESC, `status` ASC, FIELD(`status`, 'new', 'done')"
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+// TestSQLQueryConstructorLimitOffsetValidation 测试负数 LIMIT/OFFSET 和 LIMIT 0 返回错误，OFFSET 0 合法
+func TestSQLQueryConstructorLimitOffsetValidation(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		configure func(qc *SQLQueryConstructor)
+	}{
+		{"negative limit", func(qc *SQLQueryConstructor) { qc.Limit(-5) }},
+		{"zero limit", func(qc *SQLQueryConstructor) { qc.Limit(0) }},
+		{"negative offset", func(qc *SQLQueryConstructor) { qc.Limit(10).Offset(-1) }},
+	}
+	for _, tt := range tests {
+		qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+		tt.configure(qc)
+		if _, _, err := qc.Build(ctx); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Limit(10).Offset(0)
+	sql, _, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "id" FROM "orders" LIMIT 10 OFFSET 0`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+// TestSQLQueryConstructorOrderByCollate 测试按排序规则排序及名称校验
+func TestSQLQueryConstructorOrderByCollate(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("name", TypeString).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.OrderByCollate("name", "C", "desc")
+	sql, _, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "name" FROM "users" ORDER BY "name" COLLATE "C" DESC`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.OrderByCollate("name", "utf8mb4_unicode_ci", "ASC")
+	sql, _, err = qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "SELECT `name` FROM `users` ORDER BY `name` COLLATE utf8mb4_unicode_ci ASC"; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.OrderByCollate("name", "x DESC; DROP TABLE users", "ASC")
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for invalid collation name")
+	}
+}
+
+// TestSQLServerPagination 测试 SQL Server 的方括号、@pN 占位符和 OFFSET/FETCH 分页
+func TestSQLServerPagination(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewSQLServerDialect())
+	qc.Where(Eq("name", "John")).Limit(10).Offset(20)
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "SELECT [id], [name] FROM [users] WHERE [name] = @p1 ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if len(args) != 1 || args[0] != "John" {
+		t.Errorf("Expected args [John], got %v", args)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewSQLServerDialect())
+	qc.OrderBy("id", "DESC").Limit(5)
+	sql, _, err = qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "SELECT [id], [name] FROM [users] ORDER BY [id] DESC OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY"; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+// TestEmptyInList 测试空 IN 列表生成永假条件、空 NOT IN 生成永真条件，且不占用占位符
+func TestEmptyInList(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("age", TypeInteger).Build())
+
+	tests := []struct {
+		dialect SQLDialect
+		in      string
+		notIn   string
+	}{
+		{NewMySQLDialect(), "SELECT `id`, `age` FROM `users` WHERE 1=0 AND `age` > ?", "SELECT `id`, `age` FROM `users` WHERE 1=1 AND `age` > ?"},
+		{NewPostgreSQLDialect(), `SELECT "id", "age" FROM "users" WHERE 1=0 AND "age" > $1`, `SELECT "id", "age" FROM "users" WHERE 1=1 AND "age" > $1`},
+		{NewSQLiteDialect(), "SELECT `id`, `age` FROM `users` WHERE 1=0 AND `age` > ?", "SELECT `id`, `age` FROM `users` WHERE 1=1 AND `age` > ?"},
+		{NewSQLServerDialect(), "SELECT [id], [age] FROM [users] WHERE 1=0 AND [age] > @p1", "SELECT [id], [age] FROM [users] WHERE 1=1 AND [age] > @p1"},
+	}
+	for _, tt := range tests {
+		var ids []interface{}
+		for cond, expected := range map[Condition]string{In("id", ids...): tt.in, NotIn("id", ids...): tt.notIn} {
+			qc := NewSQLQueryConstructor(schema, tt.dialect)
+			qc.Where(cond).Where(Gt("age", 18))
+			sql, args, err := qc.Build(context.Background())
+			if err != nil {
+				t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+			}
+			if sql != expected || len(args) != 1 || args[0] != 18 {
+				t.Errorf("%s: expected %s, got %s %v", tt.dialect.Name(), expected, sql, args)
+			}
+		}
+	}
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Where(Or(In("id"), NotIn("age")))
+	sql, _, err := qc.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "SELECT `id`, `age` FROM `users` WHERE (1=0 OR 1=1)"; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+// TestSQLQueryConstructorJoinRequiresOn 测试普通连接必须带 ON 条件，笛卡尔积需要显式 CrossJoin
+func TestSQLQueryConstructorJoinRequiresOn(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Join("posts", nil)
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for inner join without ON")
+	}
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.LeftJoin("posts", nil)
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for left join without ON")
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Join("posts", EqCol("posts.user_id", "users.id")).
+		LeftJoin("profiles", EqCol("profiles.user_id", "users.id")).
+		CrossJoin("regions")
+	qc.Where(Eq("status", "active"))
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "users"."id", "users"."status" FROM "users"` +
+		` INNER JOIN "posts" ON "posts"."user_id" = "users"."id"` +
+		` LEFT JOIN "profiles" ON "profiles"."user_id" = "users"."id"` +
+		` CROSS JOIN "regions" WHERE "status" = $1`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"active"}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	if got := qc.ArgCount(); got != 1 {
+		t.Errorf("Expected ArgCount 1, got %d", got)
+	}
+}
+
+// TestSQLQueryConstructorOrderByJoinedColumn 测试按连接表的限定列排序，主表前缀按 schema 校验
+func TestSQLQueryConstructorOrderByJoinedColumn(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Join("orders", EqCol("orders.user_id", "users.id")).OrderBy("orders.created_at", "DESC").OrderBy("users.name", "ASC")
+	sql, _, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.HasSuffix(sql, " ORDER BY `orders`.`created_at` DESC, `users`.`name` ASC") {
+		t.Errorf("Unexpected ORDER BY: %s", sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Join("orders", EqCol("orders.user_id", "users.id")).OrderBy("users.missing", "ASC")
+	if _, _, err := qc.Build(ctx); err == nil || !strings.Contains(err.Error(), "unknown column users.missing") {
+		t.Errorf("Expected unknown base table column error, got %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.OrderBy("orders.created_at", "DESC")
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for a table that is not joined")
+	}
+}
+
+// TestSQLQueryConstructorStrictColumns 测试严格模式下拒绝未声明的列，默认不检查
+func TestSQLQueryConstructorStrictColumns(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Where(Eq("nmae", "alice"))
+	if _, _, err := qc.Build(ctx); err != nil {
+		t.Errorf("Expected non-strict build to pass, got %v", err)
+	}
+
+	for _, cond := range []Condition{
+		Eq("nmae", "alice"),
+		And(Eq("id", 1), Not(Like("nmae", "a%"))),
+		EqCol("id", "users.owner_id"),
+		TupleIn([]string{"id", "nmae"}, [][]interface{}{{1, "a"}}),
+	} {
+		qc := NewSQLQueryConstructor(schema, NewMySQLDialect()).StrictColumns(true)
+		qc.Where(cond)
+		if _, _, err := qc.Build(ctx); err == nil {
+			t.Errorf("Expected unknown column error for %#v", cond)
+		}
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect()).StrictColumns(true)
+	qc.Join("posts", EqCol("posts.user_id", "users.id"))
+	qc.Where(Eq("name", "alice")).Where(Raw("LENGTH(`bio`) > ?", 10))
+	if _, _, err := qc.Build(ctx); err != nil {
+		t.Errorf("Expected declared, joined and raw columns to pass, got %v", err)
+	}
+}
+
+// TestExprComparison 测试计算表达式原样输出、{col} 转义，比较值的参数排在表达式参数之后
+func TestExprComparison(t *testing.T) {
+	schema := NewBaseSchema("order_items")
+	schema.AddField(NewField("price", TypeFloat).Build())
+	schema.AddField(NewField("quantity", TypeInteger).Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Where(Eq("status", "paid")).
+		Where(GtExpr(Expr("{price} * {quantity} * (1 - ?)", 0.1), 100)).
+		Where(LteExpr(Expr("{order_items.quantity}"), 5))
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `SELECT "price", "quantity", "status" FROM "order_items" WHERE "status" = $1 AND "price" * "quantity" * (1 - $2) > $3 AND "order_items"."quantity" <= $4`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"paid", 0.1, 100, 5}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	if got := qc.ArgCount(); got != 4 {
+		t.Errorf("Expected ArgCount 4, got %d", got)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect())
+	qc.Where(GtExpr(Expr("{price} * ?"), 100))
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected error for placeholder count mismatch")
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect()).StrictColumns(true)
+	qc.Where(GtExpr(Expr("{price} * {qty}"), 100))
+	if _, _, err := qc.Build(ctx); err == nil {
+		t.Error("Expected strict mode to reject unknown expression column")
+	}
+}
+
+// TestSchemaColumnList 测试可查询列按声明顺序排列、去掉只写字段，且默认 SELECT 展开为该列表
+func TestSchemaColumnList(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("email", TypeString).Build())
+	schema.AddField(NewField("password_hash", TypeString).WriteOnly().Build())
+	schema.AddField(NewField("name", TypeString).Build())
+
+	if got := schema.ColumnList(); !reflect.DeepEqual(got, []string{"id", "email", "name"}) {
+		t.Errorf("Unexpected column list: %v", got)
+	}
+	if got := schema.ColumnList("email"); !reflect.DeepEqual(got, []string{"id", "name"}) {
+		t.Errorf("Unexpected column list with exclude: %v", got)
+	}
+
+	sql, _, err := NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT "id", "email", "name" FROM "users"`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	// 有 JOIN 时加主表前缀，避免与连接表的同名列冲突
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.Join("profiles", EqCol("profiles.user_id", "users.id"))
+	if sql, _, _ = qc.Build(context.Background()); !strings.HasPrefix(sql, `SELECT "users"."id", "users"."email", "users"."name" FROM "users" INNER JOIN`) {
+		t.Errorf("Expected table-qualified column list, got %s", sql)
+	}
+
+	// 没有声明字段的 schema 仍使用 *
+	if sql, _, _ = NewSQLQueryConstructor(NewBaseSchema("logs"), NewPostgreSQLDialect()).Build(context.Background()); sql != `SELECT * FROM "logs"` {
+		t.Errorf("Expected SELECT * for field-less schema, got %s", sql)
+	}
+}
+
+// TestDistinctOrderByValidation 测试 PostgreSQL 的 SELECT DISTINCT 排序列必须在选择列表中
+func TestDistinctOrderByValidation(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("city", TypeString).Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).Distinct()
+	qc.Select("city")
+	qc.OrderBy("created_at", "DESC")
+	if _, _, err := qc.Build(ctx); err == nil || !strings.Contains(err.Error(), "SELECT DISTINCT") {
+		t.Errorf("Expected DISTINCT order-by error, got %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).Distinct()
+	qc.Select("city")
+	qc.OrderBy("city", "ASC")
+	sql, _, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := `SELECT DISTINCT "city" FROM "users" ORDER BY "city" ASC`; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect()).Distinct()
+	qc.OrderBy("created_at", "DESC")
+	if _, _, err := qc.Build(ctx); err != nil {
+		t.Errorf("Expected SELECT DISTINCT * to accept any column, got %v", err)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewMySQLDialect()).Distinct()
+	qc.Select("city")
+	qc.OrderBy("created_at", "DESC")
+	if _, _, err := qc.Build(ctx); err != nil {
+		t.Errorf("Expected check to apply only to PostgreSQL, got %v", err)
+	}
+}
+
+// TestSQLQueryConstructorEmptyWhereAllAny 测试空 WhereAll 不添加谓词、空 WhereAny 生成恒假条件
+func TestSQLQueryConstructorEmptyWhereAllAny(t *testing.T) {
+	schema := NewBaseSchema("users")
+	schema.AddField(NewField("age", TypeInteger).Build())
+	ctx := context.Background()
+
+	qc := NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.WhereAll()
+	sql, args, err := qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if sql != `SELECT "age" FROM "users"` || len(args) != 0 {
+		t.Errorf("Expected empty WhereAll to add no WHERE, got %s %v", sql, args)
+	}
+
+	qc = NewSQLQueryConstructor(schema, NewPostgreSQLDialect())
+	qc.WhereAny()
+	qc.Where(Gt("age", 18))
+	sql, args, err = qc.Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if sql != `SELECT "age" FROM "users" WHERE (1=0) AND "age" > $1` || len(args) != 1 {
+		t.Errorf("Expected empty WhereAny to be always false, got %s %v", sql, args)
+	}
+}
@@ -0,0 +1,90 @@
+package db
+
+import "fmt"
+
+// ==================== 查询复杂度限制 ====================
+
+// ComplexityLimit 查询复杂度预算
+// 复杂度得分 = 条件数 + JOIN 数 * 3 + IN 列表值总数，超过 MaxScore 时 Build 返回错误
+type ComplexityLimit struct {
+	MaxScore int // 允许的最大得分，<= 0 表示不限制
+}
+
+// QueryComplexity 查询复杂度统计
+type QueryComplexity struct {
+	Conditions int // 叶子条件数
+	Joins      int // JOIN 数
+	InValues   int // IN 列表值总数
+}
+
+// joinComplexityWeight 每个 JOIN 计入得分的权重
+const joinComplexityWeight = 3
+
+// Score 计算复杂度得分
+func (c QueryComplexity) Score() int {
+	return c.Conditions + c.Joins*joinComplexityWeight + c.InValues
+}
+
+// WithComplexityLimit 设置查询复杂度上限
+// 适用于把用户过滤条件翻译为查询的多租户 API 层，防止病态查询拖垮共享数据库
+func (qb *SQLQueryConstructor) WithComplexityLimit(limit ComplexityLimit) *SQLQueryConstructor {
+	qb.complexityLimit = &limit
+	return qb
+}
+
+// Complexity 统计当前查询的复杂度
+func (qb *SQLQueryConstructor) Complexity() QueryComplexity {
+	c := QueryComplexity{Joins: len(qb.joins)}
+	for _, cond := range qb.conditions {
+		countConditionComplexity(cond, &c)
+	}
+	return c
+}
+
+// checkComplexity 在 Build 时校验复杂度预算
+func (qb *SQLQueryConstructor) checkComplexity() error {
+	if qb.complexityLimit == nil || qb.complexityLimit.MaxScore <= 0 {
+		return nil
+	}
+
+	c := qb.Complexity()
+	if score := c.Score(); score > qb.complexityLimit.MaxScore {
+		return fmt.Errorf(
+			"query complexity %d exceeds limit %d (conditions: %d, joins: %d, IN values: %d)",
+			score, qb.complexityLimit.MaxScore, c.Conditions, c.Joins, c.InValues,
+		)
+	}
+	return nil
+}
+
+// countConditionComplexity 递归统计条件树
+func countConditionComplexity(cond Condition, c *QueryComplexity) {
+	switch v := cond.(type) {
+	case *CompositeCondition:
+		for _, child := range v.Conditions {
+			countConditionComplexity(child, c)
+		}
+	case *NotCondition:
+		countConditionComplexity(v.Condition, c)
+	case *SimpleCondition:
+		c.Conditions++
+		switch v.Operator {
+		case "in", "notin", "like_any", "not_like_all", "array_contains", "array_overlaps":
+			if values, ok := v.Value.([]interface{}); ok {
+				c.InValues += len(values)
+			}
+		}
+		if tuple, ok := v.Value.(tupleInValue); ok {
+			c.InValues += len(tuple.rows)
+		}
+		if sub, ok := v.Value.(QueryConstructor); ok && sub != nil {
+			if native, ok := sub.GetNativeBuilder().(*SQLQueryConstructor); ok {
+				for _, child := range native.conditions {
+					countConditionComplexity(child, c)
+				}
+			}
+		}
+	default:
+		c.Conditions++
+	}
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestComplexityLimit 测试查询复杂度上限
+func TestComplexityLimit(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("status", TypeString).Build())
+	schema.AddField(NewField("amount", TypeFloat).Build())
+
+	ctx := context.Background()
+
+	// 2 个条件 + 3 个 IN 值 = 5
+	under := NewSQLQueryConstructor(schema, NewMySQLDialect()).WithComplexityLimit(ComplexityLimit{MaxScore: 5})
+	under.Where(In("status", "new", "paid", "shipped")).Where(Gt("amount", 10))
+	if score := under.Complexity().Score(); score != 5 {
+		t.Errorf("Expected complexity score 5, got %d", score)
+	}
+	if _, _, err := under.Build(ctx); err != nil {
+		t.Fatalf("Expected query under the limit to build, got: %v", err)
+	}
+
+	over := NewSQLQueryConstructor(schema, NewMySQLDialect()).WithComplexityLimit(ComplexityLimit{MaxScore: 5})
+	over.WhereAny(
+		In("status", "new", "paid", "shipped", "refunded"),
+		Not(Lt("amount", 1)),
+	)
+	_, _, err := over.Build(ctx)
+	if err == nil {
+		t.Fatal("Expected query over the limit to fail")
+	}
+	if !strings.Contains(err.Error(), "exceeds limit 5") || !strings.Contains(err.Error(), "IN values: 4") {
+		t.Errorf("Expected descriptive complexity error, got: %v", err)
+	}
+
+	t.Logf("✓ Complexity limit error: %v", err)
+}
+
+// TestComplexityJoins 测试 JOIN 按权重 3 计入复杂度得分
+func TestComplexityJoins(t *testing.T) {
+	schema := NewBaseSchema("orders")
+	schema.AddField(NewField("status", TypeString).Build())
+
+	qc := NewSQLQueryConstructor(schema, NewMySQLDialect()).WithComplexityLimit(ComplexityLimit{MaxScore: 4})
+	qc.Join("users", EqCol("users.id", "orders.user_id"))
+	qc.Where(Eq("status", "new"))
+	c := qc.Complexity()
+	if c != (QueryComplexity{Conditions: 1, Joins: 1}) || c.Score() != 4 {
+		t.Errorf("Expected 1 condition and 1 join scoring 4, got %+v (%d)", c, c.Score())
+	}
+	if _, _, err := qc.Build(context.Background()); err != nil {
+		t.Fatalf("Expected query at the limit to build, got: %v", err)
+	}
+
+	qc.LeftJoin("regions", EqCol("regions.id", "orders.region_id"))
+	if _, _, err := qc.Build(context.Background()); err == nil || !strings.Contains(err.Error(), "joins: 2") {
+		t.Errorf("Expected join-heavy query to exceed the limit, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ==================== 执行计划代价门禁 ====================
+
+// QueryCostError 估算代价超过阈值时 GuardByCost 返回的错误
+type QueryCostError struct {
+	Cost    float64
+	MaxCost float64
+}
+
+func (e *QueryCostError) Error() string {
+	return fmt.Sprintf("estimated query cost %.2f exceeds limit %.2f", e.Cost, e.MaxCost)
+}
+
+// GuardByCost 在执行前通过 EXPLAIN 估算查询代价，超过 maxCost 时返回 *QueryCostError
+// 目前只支持 PostgreSQL（EXPLAIN (FORMAT JSON) 的顶层 Total Cost）
+func (r *Repository) GuardByCost(ctx context.Context, sql string, args []interface{}, maxCost float64) error {
+	dialect := r.sqlDialect()
+	if dialect == nil || dialect.Name() != "postgresql" {
+		return fmt.Errorf("GuardByCost is not supported by adapter %T", r.GetAdapter())
+	}
+
+	var plan []byte
+	if err := r.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+sql, args...).Scan(&plan); err != nil {
+		return fmt.Errorf("GuardByCost: failed to explain query: %w", err)
+	}
+	cost, err := parseExplainCost(plan)
+	if err != nil {
+		return fmt.Errorf("GuardByCost: %w", err)
+	}
+	if cost > maxCost {
+		return &QueryCostError{Cost: cost, MaxCost: maxCost}
+	}
+	return nil
+}
+
+// parseExplainCost 解析 PostgreSQL EXPLAIN (FORMAT JSON) 输出中顶层计划的 Total Cost
+func parseExplainCost(plan []byte) (float64, error) {
+	var explain []struct {
+		Plan struct {
+			TotalCost *float64 `json:"Total Cost"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explain); err != nil {
+		return 0, fmt.Errorf("invalid EXPLAIN output: %w", err)
+	}
+	if len(explain) == 0 || explain[0].Plan.TotalCost == nil {
+		return 0, fmt.Errorf("EXPLAIN output has no top-level Total Cost")
+	}
+	return *explain[0].Plan.TotalCost, nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestGuardByCost 测试解析 EXPLAIN 估算代价并在超过阈值时拒绝查询
+func TestGuardByCost(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+
+	plan := `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "orders", "Startup Cost": 0.00, "Total Cost": 1234.50, "Plan Rows": 50000}}]`
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{columns: []string{"QUERY PLAN"}, values: [][]driver.Value{{plan}}}, nil
+	}
+
+	ctx := context.Background()
+	query := "SELECT * FROM orders WHERE status = $1"
+	if err := repo.GuardByCost(ctx, query, []interface{}{"new"}, 5000); err != nil {
+		t.Fatalf("Expected query under threshold to pass, got %v", err)
+	}
+	stmts := fake.Statements()
+	if stmts[len(stmts)-1] != "EXPLAIN (FORMAT JSON) "+query {
+		t.Errorf("Unexpected EXPLAIN statement: %s", stmts[len(stmts)-1])
+	}
+	if args := fake.LastArgs(); len(args) != 1 || args[0] != "new" {
+		t.Errorf("Expected query args to be forwarded, got %v", args)
+	}
+
+	err := repo.GuardByCost(ctx, query, []interface{}{"new"}, 1000)
+	var costErr *QueryCostError
+	if !errors.As(err, &costErr) {
+		t.Fatalf("Expected QueryCostError above threshold, got %v", err)
+	}
+	if costErr.Cost != 1234.50 || costErr.MaxCost != 1000 {
+		t.Errorf("Unexpected cost error: %+v", costErr)
+	}
+
+	mysqlRepo, _ := newFakeRepository(NewMySQLDialect())
+	defer mysqlRepo.Close()
+	if err := mysqlRepo.GuardByCost(ctx, "SELECT 1", nil, 1000); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected not-supported error for MySQL, got %v", err)
+	}
+}
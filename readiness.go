@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// WaitReadyOptions WaitReady 的重试参数
+type WaitReadyOptions struct {
+	InitialBackoff time.Duration // 首次重试间隔，默认 100ms
+	MaxBackoff     time.Duration // 最大重试间隔，默认 5s
+	Jitter         float64       // 抖动比例 (0~1)，默认 0.2
+	MaxAttempts    int           // 最大尝试次数，0 表示仅由 ctx 控制
+}
+
+// WaitReady 循环 Ping 直到数据库可用或 ctx 结束，返回等待耗时
+// 重试间隔按指数退避增长并叠加随机抖动，适用于编排系统的就绪检查
+func (r *Repository) WaitReady(ctx context.Context, opts *WaitReadyOptions) (time.Duration, error) {
+	o := WaitReadyOptions{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         0.2,
+	}
+	if opts != nil {
+		if opts.InitialBackoff > 0 {
+			o.InitialBackoff = opts.InitialBackoff
+		}
+		if opts.MaxBackoff > 0 {
+			o.MaxBackoff = opts.MaxBackoff
+		}
+		if opts.Jitter > 0 {
+			o.Jitter = opts.Jitter
+		}
+		o.MaxAttempts = opts.MaxAttempts
+	}
+
+	start := time.Now()
+	backoff := o.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := r.Ping(ctx)
+		if err == nil {
+			return time.Since(start), nil
+		}
+		if o.MaxAttempts > 0 && attempt >= o.MaxAttempts {
+			return time.Since(start), fmt.Errorf("database not ready after %d attempts: %w", attempt, err)
+		}
+
+		wait := backoff
+		if o.Jitter > 0 {
+			wait += time.Duration((rand.Float64()*2 - 1) * o.Jitter * float64(backoff))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return time.Since(start), fmt.Errorf("database not ready after %d attempts: %w (last error: %v)", attempt, ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > o.MaxBackoff {
+			backoff = o.MaxBackoff
+		}
+	}
+}
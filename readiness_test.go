@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestWaitReadyEventuallySucceeds 测试 Ping 失败数次后成功
+func TestWaitReadyEventuallySucceeds(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+
+	failures := 3
+	fake.pingFn = func() error {
+		if failures > 0 {
+			failures--
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	elapsed, err := repo.WaitReady(ctx, &WaitReadyOptions{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		MaxAttempts:    10,
+	})
+	if err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+	if fake.pings != 4 {
+		t.Errorf("Expected 4 ping attempts, got %d", fake.pings)
+	}
+	if elapsed <= 0 {
+		t.Errorf("Expected positive elapsed time, got %v", elapsed)
+	}
+}
+
+// TestWaitReadyBounded 测试尝试次数和 ctx 取消
+func TestWaitReadyBounded(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+
+	fake.pingFn = func() error { return errors.New("connection refused") }
+
+	_, err := repo.WaitReady(context.Background(), &WaitReadyOptions{
+		InitialBackoff: time.Millisecond,
+		MaxAttempts:    3,
+	})
+	if err == nil {
+		t.Fatal("Expected WaitReady to give up")
+	}
+	if fake.pings != 3 {
+		t.Errorf("Expected exactly 3 attempts, got %d", fake.pings)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := repo.WaitReady(ctx, &WaitReadyOptions{InitialBackoff: 5 * time.Millisecond}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline error, got: %v", err)
+	}
+}
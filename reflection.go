@@ -182,12 +182,32 @@ func toSnakeCase(s string) string {
 	return strings.ToLower(string(result))
 }
 
-// ScanStruct 从 sql.Row 扫描单个结构体
-func ScanStruct(row *sql.Row, dest interface{}) error {
+// RowScanner 单行扫描接口，*sql.Row 和 *Row 都实现了该接口
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// isNilScanner row 是否为 nil，包括装在接口里的 nil 指针（如 (*sql.Row)(nil)）
+func isNilScanner(row RowScanner) bool {
+	if row == nil {
+		return true
+	}
+	v := reflect.ValueOf(row)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// ScanStruct 从单行结果扫描单个结构体，row 为 nil（包括 nil 指针）时返回 ErrNotConnected
+func ScanStruct(row RowScanner, dest interface{}) error {
+	if isNilScanner(row) {
+		return ErrNotConnected
+	}
 	val := reflect.ValueOf(dest)
 	if val.Kind() != reflect.Ptr {
 		return fmt.Errorf("ScanStruct: dest must be a pointer")
 	}
+	if val.IsNil() {
+		return fmt.Errorf("ScanStruct: dest must not be nil")
+	}
 
 	elem := val.Elem()
 	if elem.Kind() != reflect.Struct {
@@ -201,6 +221,10 @@ func ScanStruct(row *sql.Row, dest interface{}) error {
 		if !field.CanSet() {
 			continue
 		}
+		if target, ok := timeScanDest(field, nil); ok {
+			scanDest = append(scanDest, target)
+			continue
+		}
 		scanDest = append(scanDest, field.Addr().Interface())
 	}
 
@@ -209,10 +233,25 @@ func ScanStruct(row *sql.Row, dest interface{}) error {
 
 // ScanStructs 从 sql.Rows 扫描多个结构体
 func ScanStructs(rows *sql.Rows, dest interface{}) error {
+	return scanStructs(rows, dest, nil)
+}
+
+// ScanStructsWithSchema 从 sql.Rows 扫描多个结构体，并对带序列化钩子的字段调用 load
+func ScanStructsWithSchema(rows *sql.Rows, schema Schema, dest interface{}) error {
+	return scanStructs(rows, dest, schema)
+}
+
+func scanStructs(rows *sql.Rows, dest interface{}, schema Schema) error {
+	if rows == nil {
+		return fmt.Errorf("ScanStructs: rows must not be nil")
+	}
 	destVal := reflect.ValueOf(dest)
 	if destVal.Kind() != reflect.Ptr {
 		return fmt.Errorf("ScanStructs: dest must be a pointer")
 	}
+	if destVal.IsNil() {
+		return fmt.Errorf("ScanStructs: dest must not be nil")
+	}
 
 	sliceVal := destVal.Elem()
 	if sliceVal.Kind() != reflect.Slice {
@@ -236,40 +275,15 @@ func ScanStructs(rows *sql.Rows, dest interface{}) error {
 	}
 
 	// 构建字段名到索引的映射
-	fieldMap := make(map[string]int)
-	for i := 0; i < elemType.NumField(); i++ {
-		field := elemType.Field(i)
-		if !field.IsExported() {
-			continue
-		}
-
-		dbTag := field.Tag.Get("db")
-		columnName, _ := parseDBTag(dbTag, field.Name)
-		fieldMap[columnName] = i
-	}
+	fieldMap := structColumnIndex(elemType)
 
 	// 遍历行
 	for rows.Next() {
 		// 创建新元素
 		elemVal := reflect.New(elemType).Elem()
 
-		// 准备扫描目标
-		scanDest := make([]interface{}, len(columns))
-		for i, colName := range columns {
-			if fieldIdx, ok := fieldMap[colName]; ok {
-				field := elemVal.Field(fieldIdx)
-				if field.CanSet() {
-					scanDest[i] = field.Addr().Interface()
-					continue
-				}
-			}
-			// 未映射的列使用占位符
-			var placeholder interface{}
-			scanDest[i] = &placeholder
-		}
-
 		// 扫描行
-		if err := rows.Scan(scanDest...); err != nil {
+		if err := rows.Scan(structScanTargets(elemVal, columns, fieldMap, schema)...); err != nil {
 			return fmt.Errorf("ScanStructs: failed to scan row: %w", err)
 		}
 
@@ -288,6 +302,95 @@ func ScanStructs(rows *sql.Rows, dest interface{}) error {
 	return nil
 }
 
+// structColumnIndex 列名到结构体导出字段下标的映射，列名取 db 标签，没有标签时为字段名的蛇形形式
+func structColumnIndex(elemType reflect.Type) map[string]int {
+	fieldMap := make(map[string]int)
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		dbTag := field.Tag.Get("db")
+		columnName, _ := parseDBTag(dbTag, field.Name)
+		fieldMap[columnName] = i
+	}
+	return fieldMap
+}
+
+// structScanTargets 按列准备一行的扫描目标，未映射的列扫描到占位符
+func structScanTargets(elemVal reflect.Value, columns []string, fieldMap map[string]int, schema Schema) []interface{} {
+	scanDest := make([]interface{}, len(columns))
+	for i, colName := range columns {
+		if fieldIdx, ok := fieldMap[colName]; ok {
+			field := elemVal.Field(fieldIdx)
+			if field.CanSet() {
+				if schema != nil {
+					if f := schema.GetField(colName); f != nil && f.Serializer != nil && f.Serializer.Load != nil {
+						scanDest[i] = &serializedScanTarget{field: f, dest: field}
+						continue
+					}
+				}
+				if target, ok := timeScanDest(field, nil); ok {
+					scanDest[i] = target
+					continue
+				}
+				scanDest[i] = field.Addr().Interface()
+				continue
+			}
+		}
+		// 未映射的列使用占位符
+		var placeholder interface{}
+		scanDest[i] = &placeholder
+	}
+	return scanDest
+}
+
+// ScanRows 按列名把结果集扫描到 dest，可独立于查询构造器使用
+// dest 为结构体切片指针（*[]T / *[]*T，扫描所有行）或结构体指针（只扫描下一行，没有行时返回 sql.ErrNoRows）。
+// 列按 db 标签匹配，没有标签时使用字段名的蛇形形式；NULL 列需要扫描到 *T 或 sql.Null* 字段，
+// 时间列按 DefaultTimeScanner 解析，其余类型转换由 database/sql 完成（例如 int64 到 int、0/1 到 bool）
+func ScanRows(rows *sql.Rows, dest interface{}) error {
+	if rows == nil {
+		return fmt.Errorf("ScanRows: rows must not be nil")
+	}
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+		return fmt.Errorf("ScanRows: dest must be a non-nil pointer")
+	}
+	if destVal.Elem().Kind() == reflect.Slice {
+		return ScanStructs(rows, dest)
+	}
+	elemVal := destVal.Elem()
+	if elemVal.Kind() != reflect.Struct {
+		return fmt.Errorf("ScanRows: dest must be a pointer to struct or slice")
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("ScanRows: failed to get columns: %w", err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("ScanRows: rows error: %w", err)
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(structScanTargets(elemVal, columns, structColumnIndex(elemVal.Type()), nil)...); err != nil {
+		return fmt.Errorf("ScanRows: failed to scan row: %w", err)
+	}
+	return nil
+}
+
+// ScanRow 把单行结果（*sql.Row 或 Repository.QueryRow 返回的 *Row）扫描到结构体指针
+// 单行结果不提供列名，查询的列需要按结构体导出字段的声明顺序返回，需要按列名匹配时使用 ScanRows
+func ScanRow(row RowScanner, dest interface{}) error {
+	if isNilScanner(row) {
+		return fmt.Errorf("ScanRow: row must not be nil")
+	}
+	return ScanStruct(row, dest)
+}
+
 // GetStructFields 获取结构体的字段名列表（按 db tag 顺序）
 func GetStructFields(v interface{}) []string {
 	typ := reflect.TypeOf(v)
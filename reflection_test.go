@@ -2,7 +2,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"testing"
+	"time"
 )
 
 // TestStruct 测试用的结构体
@@ -230,3 +234,110 @@ func TestSQLiteReflectionIntegration(t *testing.T) {
 
 	t.Log("✓ SQLite reflection integration test passed")
 }
+
+type scanRowsTestItem struct {
+	ID        int            `db:"id"`
+	Title     string         `db:"title"`
+	Price     float64        // 无标签时按蛇形名匹配 price
+	InStock   bool           // in_stock
+	Note      *string        `db:"note"`
+	Summary   sql.NullString `db:"summary"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// TestScanRows 测试按列名扫描混合类型，NULL 列扫描到指针和 sql.Null* 字段
+func TestScanRows(t *testing.T) {
+	repo, fake := newFakeRepository(NewSQLiteDialect())
+	defer repo.Close()
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{
+			columns: []string{"created_at", "id", "title", "price", "in_stock", "note", "summary", "extra"},
+			values: [][]driver.Value{
+				{"2024-05-01 10:00:00", int64(1), []byte("pen"), 1.5, int64(1), nil, nil, "ignored"},
+				{"2024-05-02", int64(2), "book", 12.0, int64(0), "signed", "classic", nil},
+			},
+		}, nil
+	}
+	db := repo.GetAdapter().GetRawConn().(*sql.DB)
+
+	rows, err := db.Query("SELECT items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []scanRowsTestItem
+	if err := ScanRows(rows, &items); err != nil {
+		t.Fatalf("ScanRows failed: %v", err)
+	}
+	rows.Close()
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	first := items[0]
+	if first.ID != 1 || first.Title != "pen" || first.Price != 1.5 || !first.InStock || first.Note != nil || first.Summary.Valid {
+		t.Errorf("Unexpected first item: %+v", first)
+	}
+	if !first.CreatedAt.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected created_at: %v", first.CreatedAt)
+	}
+	if second := items[1]; second.InStock || second.Note == nil || *second.Note != "signed" || second.Summary.String != "classic" {
+		t.Errorf("Unexpected second item: %+v", second)
+	}
+
+	rows, err = db.Query("SELECT items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item scanRowsTestItem
+	if err := ScanRows(rows, &item); err != nil || item.ID != 1 {
+		t.Errorf("Expected first row scanned into struct, got %+v (%v)", item, err)
+	}
+	rows.Close()
+
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{columns: []string{"id", "title"}}, nil
+	}
+	rows, err = db.Query("SELECT none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if err := ScanRows(rows, &item); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+// TestScanRow 测试单行按字段顺序扫描
+func TestScanRow(t *testing.T) {
+	repo, fake := newFakeRepository(NewSQLiteDialect())
+	defer repo.Close()
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{
+			columns: []string{"id", "name", "nickname"},
+			values:  [][]driver.Value{{int64(3), "carol", nil}},
+		}, nil
+	}
+	db := repo.GetAdapter().GetRawConn().(*sql.DB)
+
+	var dest struct {
+		ID       int64
+		Name     string
+		Nickname *string
+	}
+	if err := ScanRow(db.QueryRow("SELECT one"), &dest); err != nil {
+		t.Fatalf("ScanRow failed: %v", err)
+	}
+	if dest.ID != 3 || dest.Name != "carol" || dest.Nickname != nil {
+		t.Errorf("Unexpected row: %+v", dest)
+	}
+	if err := ScanRow(nil, &dest); err == nil {
+		t.Error("Expected error for nil row")
+	}
+
+	dest.ID = 0
+	if err := ScanRow(repo.QueryRow(context.Background(), "SELECT one"), &dest); err != nil {
+		t.Fatalf("ScanRow with repository row failed: %v", err)
+	}
+	if dest.ID != 3 || dest.Name != "carol" {
+		t.Errorf("Unexpected row from Repository.QueryRow: %+v", dest)
+	}
+}
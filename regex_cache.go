@@ -0,0 +1,76 @@
+package db
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+)
+
+// RegexCacheSize 正则缓存的最大条目数，超出后淘汰最久未使用的模式
+const RegexCacheSize = 256
+
+// regexCache 按模式字符串缓存编译结果（包括编译错误）的 LRU 缓存
+type regexCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type regexCacheEntry struct {
+	pattern string
+	re      *regexp.Regexp
+	err     error
+}
+
+var defaultRegexCache = newRegexCache(RegexCacheSize)
+
+func newRegexCache(size int) *regexCache {
+	return &regexCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// CompileRegex 返回缓存的编译结果，首次使用时编译
+// ValidateFormat、PatternValidator 和 EmailValidator 共用此缓存；
+// 无效模式的编译错误同样会被缓存，后续调用直接返回该错误
+func CompileRegex(pattern string) (*regexp.Regexp, error) {
+	return defaultRegexCache.compile(pattern)
+}
+
+func (c *regexCache) compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[pattern]; ok {
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*regexCacheEntry)
+		c.mu.Unlock()
+		return entry.re, entry.err
+	}
+	c.mu.Unlock()
+
+	// 编译放在锁外，避免慢模式阻塞其他调用
+	re, err := regexp.Compile(pattern)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[pattern]; ok {
+		entry := elem.Value.(*regexCacheEntry)
+		return entry.re, entry.err
+	}
+	c.entries[pattern] = c.order.PushFront(&regexCacheEntry{pattern: pattern, re: re, err: err})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*regexCacheEntry).pattern)
+	}
+	return re, err
+}
+
+// len 返回当前缓存条目数
+func (c *regexCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+// TestCompileRegexCachesResult 测试同一模式返回同一个编译结果
+func TestCompileRegexCachesResult(t *testing.T) {
+	first, err := CompileRegex(`^[a-z]+$`)
+	if err != nil {
+		t.Fatalf("CompileRegex failed: %v", err)
+	}
+	second, err := CompileRegex(`^[a-z]+$`)
+	if err != nil {
+		t.Fatalf("CompileRegex failed: %v", err)
+	}
+	if first != second {
+		t.Error("Expected cached *regexp.Regexp to be reused")
+	}
+}
+
+// TestCompileRegexInvalidPattern 测试无效模式返回编译错误，且错误同样被缓存
+func TestCompileRegexInvalidPattern(t *testing.T) {
+	cache := newRegexCache(4)
+
+	_, err1 := cache.compile(`[unclosed`)
+	if err1 == nil {
+		t.Fatal("Expected compile error for invalid pattern")
+	}
+	_, err2 := cache.compile(`[unclosed`)
+	if err2 != err1 {
+		t.Errorf("Expected cached compile error, got %v and %v", err1, err2)
+	}
+	if cache.len() != 1 {
+		t.Errorf("Expected 1 cached entry, got %d", cache.len())
+	}
+
+	cs := FromMap(nil, map[string]interface{}{"code": "abc"})
+	cs.ValidateFormat("code", `[unclosed`)
+	if cs.IsValid() {
+		t.Error("Expected changeset to be invalid for invalid pattern")
+	}
+
+	if err := (&PatternValidator{Pattern: `[unclosed`}).Validate("abc"); err == nil {
+		t.Error("Expected PatternValidator to report invalid pattern")
+	}
+}
+
+// TestRegexCacheBounded 测试缓存有上限并淘汰最久未使用的模式
+func TestRegexCacheBounded(t *testing.T) {
+	cache := newRegexCache(2)
+	a, _ := cache.compile("a")
+	cache.compile("b")
+	cache.compile("a") // a 变为最近使用
+	cache.compile("c") // 淘汰 b
+
+	if cache.len() != 2 {
+		t.Fatalf("Expected 2 cached entries, got %d", cache.len())
+	}
+	if again, _ := cache.compile("a"); again != a {
+		t.Error("Expected recently used pattern to stay cached")
+	}
+	if _, ok := cache.entries["b"]; ok {
+		t.Error("Expected least recently used pattern to be evicted")
+	}
+}
+
+// TestCompileRegexConcurrent 测试并发访问安全
+func TestCompileRegexConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			re, err := CompileRegex(fmt.Sprintf(`^item-%d$`, i%4))
+			if err != nil || !re.MatchString(fmt.Sprintf("item-%d", i%4)) {
+				t.Errorf("Unexpected result for goroutine %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestPatternValidator 测试正则验证器
+func TestPatternValidator(t *testing.T) {
+	v := &PatternValidator{Pattern: `^\d{3}-\d{4}$`}
+	if err := v.Validate("555-1234"); err != nil {
+		t.Errorf("Expected valid value, got %v", err)
+	}
+	if err := v.Validate("5551234"); err == nil {
+		t.Error("Expected mismatch error")
+	}
+	if err := v.Validate(123); err == nil {
+		t.Error("Expected type error for non-string value")
+	}
+}
+
+const benchmarkPattern = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
+
+// BenchmarkRegexCompileEachCall 基准测试每次调用都编译正则
+func BenchmarkRegexCompileEachCall(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		re, _ := regexp.Compile(benchmarkPattern)
+		re.MatchString("user@example.com")
+	}
+}
+
+// BenchmarkRegexCompileCached 基准测试使用缓存的正则
+func BenchmarkRegexCompileCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		re, _ := CompileRegex(benchmarkPattern)
+		re.MatchString("user@example.com")
+	}
+}
+
+// BenchmarkValidateFormat 基准测试 ValidateFormat
+func BenchmarkValidateFormat(b *testing.B) {
+	params := map[string]interface{}{"email": "user@example.com"}
+	for i := 0; i < b.N; i++ {
+		FromMap(nil, params).ValidateFormat("email", benchmarkPattern)
+	}
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -32,8 +33,11 @@ type Field struct {
 	Autoinc      bool
 	Index        bool
 	Unique       bool
+	Collation    string // 列排序规则，为空时使用数据库默认值
+	WriteOnly    bool   // 只写字段（如密码哈希），默认的查询列表不包含
 	Validators   []Validator
 	Transformers []Transformer
+	Serializer   *FieldSerializer
 }
 
 // Schema 定义数据模式接口 (参考 Ecto.Schema)
@@ -53,9 +57,19 @@ type Schema interface {
 
 // BaseSchema 基础模式实现
 type BaseSchema struct {
-	tableName string
-	fields    map[string]*Field
-	fieldList []*Field
+	tableName     string
+	fields        map[string]*Field
+	fieldList     []*Field
+	uniqueIndexes [][]string
+	indexes       []*IndexDefinition
+}
+
+// IndexDefinition 通过 Schema 声明的索引
+type IndexDefinition struct {
+	Name      string
+	Columns   []string
+	Unique    bool
+	Predicate Condition // 非空时为部分索引（CREATE INDEX ... WHERE ...）
 }
 
 // NewBaseSchema 创建基础模式
@@ -79,11 +93,61 @@ func (s *BaseSchema) AddField(field *Field) *BaseSchema {
 	return s
 }
 
+// AddUniqueIndex 声明（多列）唯一索引，单列唯一约束使用 FieldBuilder.Unique
+func (s *BaseSchema) AddUniqueIndex(columns ...string) *BaseSchema {
+	if len(columns) > 0 {
+		s.uniqueIndexes = append(s.uniqueIndexes, append([]string(nil), columns...))
+	}
+	return s
+}
+
+// UniqueIndexes 返回通过 AddUniqueIndex 声明的唯一索引
+func (s *BaseSchema) UniqueIndexes() [][]string {
+	return s.uniqueIndexes
+}
+
+// AddPartialIndex 声明部分索引，只索引满足 predicate 的行，例如软删除表的 deleted_at IS NULL
+// 需要方言支持部分索引（PostgreSQL、SQLite、SQL Server），MySQL 在生成 DDL 时报错
+func (s *BaseSchema) AddPartialIndex(name string, columns []string, predicate Condition) *BaseSchema {
+	s.indexes = append(s.indexes, &IndexDefinition{
+		Name:      name,
+		Columns:   append([]string(nil), columns...),
+		Predicate: predicate,
+	})
+	return s
+}
+
+// Indexes 返回通过 AddPartialIndex 声明的索引
+func (s *BaseSchema) Indexes() []*IndexDefinition {
+	return s.indexes
+}
+
 // Fields 返回所有字段
 func (s *BaseSchema) Fields() []*Field {
 	return s.fieldList
 }
 
+// ColumnList 返回可查询的列名：按声明顺序，去掉只写字段和 exclude 中的字段
+// 查询未指定 Select 时，默认 SELECT 使用该列表代替 *
+func (s *BaseSchema) ColumnList(exclude ...string) []string {
+	return selectableColumns(s, exclude...)
+}
+
+// selectableColumns 按声明顺序返回非只写、未排除的字段名
+func selectableColumns(schema Schema, exclude ...string) []string {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+	columns := make([]string, 0, len(schema.Fields()))
+	for _, field := range schema.Fields() {
+		if !field.WriteOnly && !skip[field.Name] {
+			columns = append(columns, field.Name)
+		}
+	}
+	return columns
+}
+
 // GetField 获取字段
 func (s *BaseSchema) GetField(name string) *Field {
 	return s.fields[name]
@@ -147,29 +211,76 @@ func (fb *FieldBuilder) Unique() *FieldBuilder {
 	return fb
 }
 
+// WriteOnly 标记为只写字段：可以插入和更新，默认查询不返回
+func (fb *FieldBuilder) WriteOnly() *FieldBuilder {
+	fb.field.WriteOnly = true
+	return fb
+}
+
 // Validate 添加验证器
 func (fb *FieldBuilder) Validate(validator Validator) *FieldBuilder {
 	fb.field.Validators = append(fb.field.Validators, validator)
 	return fb
 }
 
+// Collation 设置列排序规则，建表时生成 COLLATE <name>（PostgreSQL 按标识符转义）
+// 名称只能包含字母、数字、下划线、点和连字符，否则迁移时返回错误
+func (fb *FieldBuilder) Collation(name string) *FieldBuilder {
+	fb.field.Collation = name
+	return fb
+}
+
 // Transform 添加转换器
 func (fb *FieldBuilder) Transform(transformer Transformer) *FieldBuilder {
 	fb.field.Transformers = append(fb.field.Transformers, transformer)
 	return fb
 }
 
+// Serializer 设置序列化钩子
+// 写入（insert/update）时调用 store，扫描时调用 load，用于自定义 Go 类型
+func (fb *FieldBuilder) Serializer(store func(interface{}) (interface{}, error), load func(interface{}) (interface{}, error)) *FieldBuilder {
+	fb.field.Serializer = &FieldSerializer{Store: store, Load: load}
+	return fb
+}
+
 // Build 构建字段
 func (fb *FieldBuilder) Build() *Field {
 	return fb.field
 }
 
+// RunValidators 对 value 执行字段的必填检查（非 Null 字段）和所有验证器，返回全部错误
+// 可在 Changeset 之外做临时校验；nil 值只做必填检查，不会传给验证器
+func (f *Field) RunValidators(value interface{}) []error {
+	if !f.Null && (value == nil || value == "") {
+		return []error{NewValidationError("required", "字段为必填项")}
+	}
+	if value == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, validator := range f.Validators {
+		if err := validator.Validate(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 // Validator 验证器接口
 type Validator interface {
 	// 验证值，返回错误信息或 nil
 	Validate(value interface{}) error
 }
 
+// ValidatorFunc 把普通函数适配为 Validator
+type ValidatorFunc func(value interface{}) error
+
+// Validate 调用函数本身
+func (f ValidatorFunc) Validate(value interface{}) error {
+	return f(value)
+}
+
 // Transformer 转换器接口
 type Transformer interface {
 	// 转换值
@@ -216,7 +327,18 @@ type PatternValidator struct {
 }
 
 func (v *PatternValidator) Validate(value interface{}) error {
-	// 实现正则验证
+	str, ok := value.(string)
+	if !ok {
+		return NewValidationError("pattern", "字段类型必须为字符串")
+	}
+
+	re, err := CompileRegex(v.Pattern)
+	if err != nil {
+		return NewValidationError("pattern", "无效的正则表达式: "+err.Error())
+	}
+	if !re.MatchString(str) {
+		return NewValidationError("pattern", "字段格式不正确")
+	}
 	return nil
 }
 
@@ -241,8 +363,7 @@ func (t *TrimTransformer) Transform(value interface{}) (interface{}, error) {
 	if !ok {
 		return value, nil
 	}
-	// 修剪字符串
-	return str, nil
+	return strings.TrimSpace(str), nil
 }
 
 // LowercaseTransformer 小写转换器
@@ -253,8 +374,7 @@ func (t *LowercaseTransformer) Transform(value interface{}) (interface{}, error)
 	if !ok {
 		return value, nil
 	}
-	// 转换为小写
-	return str, nil
+	return strings.ToLower(str), nil
 }
 
 // TypeConversionError 类型转换错误
@@ -323,7 +443,12 @@ func valueToBoolean(value interface{}) (interface{}, error) {
 	case bool:
 		return v, nil
 	case string:
-		return v == "true" || v == "1" || v == "yes", nil
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "yes", "y", "on", "t":
+			return true, nil
+		default:
+			return false, nil
+		}
 	case int:
 		return v != 0, nil
 	default:
@@ -331,14 +456,17 @@ func valueToBoolean(value interface{}) (interface{}, error) {
 	}
 }
 
+// valueToTime 字符串按 DefaultTimeLayouts 依次尝试（RFC3339、日期时间、纯日期），整数视为 Unix 秒
 func valueToTime(value interface{}) (interface{}, error) {
 	switch v := value.(type) {
 	case time.Time:
 		return v, nil
-	case string:
-		// 尝试解析时间字符串
-		t, err := time.Parse(time.RFC3339, v)
-		return t, err
+	case string, []byte, int64:
+		return DefaultTimeScanner.Parse(v)
+	case int:
+		return DefaultTimeScanner.Parse(int64(v))
+	case int32:
+		return DefaultTimeScanner.Parse(int64(v))
 	default:
 		return nil, &TypeConversionError{From: reflect.TypeOf(value).String(), To: "time.Time"}
 	}
@@ -408,10 +536,10 @@ type QueryConstructor interface {
 	// 条件查询
 	Where(condition Condition) QueryConstructor
 	
-	// 多条件 AND 组合
+	// 多条件 AND 组合，没有条件时恒真（不添加谓词）
 	WhereAll(conditions ...Condition) QueryConstructor
 	
-	// 多条件 OR 组合
+	// 多条件 OR 组合，没有条件时恒假（1=0）
 	WhereAny(conditions ...Condition) QueryConstructor
 	
 	// 字段选择
@@ -559,6 +687,49 @@ func Lte(field string, value interface{}) Condition {
 	}
 }
 
+// columnRef 列对列比较中右侧的列名
+type columnRef string
+
+// EqCol 列等于列，例如 EqCol("a.price", "b.cost")，带点的列名按 表.列 分别转义，不产生绑定参数
+func EqCol(left, right string) Condition {
+	return &SimpleCondition{Field: left, Operator: "eq", Value: columnRef(right)}
+}
+
+// NeCol 列不等于列
+func NeCol(left, right string) Condition {
+	return &SimpleCondition{Field: left, Operator: "ne", Value: columnRef(right)}
+}
+
+// GtCol 列大于列
+func GtCol(left, right string) Condition {
+	return &SimpleCondition{Field: left, Operator: "gt", Value: columnRef(right)}
+}
+
+// GteCol 列大于等于列
+func GteCol(left, right string) Condition {
+	return &SimpleCondition{Field: left, Operator: "gte", Value: columnRef(right)}
+}
+
+// LtCol 列小于列
+func LtCol(left, right string) Condition {
+	return &SimpleCondition{Field: left, Operator: "lt", Value: columnRef(right)}
+}
+
+// LteCol 列小于等于列
+func LteCol(left, right string) Condition {
+	return &SimpleCondition{Field: left, Operator: "lte", Value: columnRef(right)}
+}
+
+// jsonExtractMarker JSONExtract 生成的字段名前缀，翻译时展开为方言的 JSON 提取表达式
+const jsonExtractMarker = "\x01json\x01"
+
+// JSONExtract JSON 列路径提取表达式，可作为条件的字段使用，例如 Eq(JSONExtract("meta", "$.status"), "active")
+// path 使用 $.key.sub[0] 形式；由方言决定渲染：PostgreSQL meta->>'status'，
+// MySQL JSON_UNQUOTE(JSON_EXTRACT(...))，SQLite json_extract(...)，SQL Server JSON_VALUE(...)
+func JSONExtract(field, path string) string {
+	return jsonExtractMarker + field + jsonExtractMarker + path
+}
+
 // In IN 条件
 func In(field string, values ...interface{}) Condition {
 	return &SimpleCondition{
@@ -568,6 +739,116 @@ func In(field string, values ...interface{}) Condition {
 	}
 }
 
+// NotIn NOT IN 条件
+// 空列表渲染为恒真的 1=1：不在空集合中的值总是成立，与 SQL 语义一致
+func NotIn(field string, values ...interface{}) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "notin",
+		Value:    values,
+	}
+}
+
+// InSubquery 子查询 IN 条件，例如 id IN (SELECT user_id FROM orders WHERE ...)
+// 子查询的占位符会接着外层查询的参数编号继续编号；SQLQueryConstructor 子查询必须只选择一列
+func InSubquery(field string, sub QueryConstructor) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "in_subquery",
+		Value:    sub,
+	}
+}
+
+// Exists 子查询存在条件，例如 EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)
+// 关联子查询可用 EqCol 引用外层表的列；占位符与 InSubquery 一样接着外层编号
+func Exists(sub QueryConstructor) Condition {
+	return &SimpleCondition{
+		Operator: "exists",
+		Value:    sub,
+	}
+}
+
+// NotExists 子查询不存在条件：NOT EXISTS (SELECT ...)
+func NotExists(sub QueryConstructor) Condition {
+	return &SimpleCondition{
+		Operator: "not_exists",
+		Value:    sub,
+	}
+}
+
+// rawValue Raw 条件的片段和参数
+type rawValue struct {
+	sql  string
+	args []interface{}
+}
+
+// Raw 原始 SQL 片段条件，用于构造器不支持的谓词，例如 Raw("date_trunc('day', created_at) = ?", day)
+// 片段加括号后写入 WHERE，其中的 ? 按顺序绑定 args，并按方言改写为 $n/@pN 等占位符。
+// 调用方需要自行保证片段本身是安全的（不要拼接用户输入），参数仍然通过绑定传递
+func Raw(sql string, args ...interface{}) Condition {
+	return &SimpleCondition{
+		Operator: "raw",
+		Value:    rawValue{sql: sql, args: args},
+	}
+}
+
+// Expression 比较条件左侧的计算表达式，见 Expr
+type Expression struct {
+	sql  string
+	args []interface{}
+}
+
+// Expr 非聚合的计算表达式，例如 GtExpr(Expr("{price} * {quantity}"), 100)
+// 片段原样输出：{col} 按方言转义为列名（支持 表.列），? 按顺序绑定 args。
+// 与 Raw 一样，片段本身不能拼接用户输入
+func Expr(sql string, args ...interface{}) Expression {
+	return Expression{sql: sql, args: args}
+}
+
+// exprComparison 表达式比较条件的表达式、比较运算符和右侧的值
+type exprComparison struct {
+	expr  Expression
+	op    string
+	value interface{}
+}
+
+func exprCondition(expr Expression, op string, value interface{}) Condition {
+	return &SimpleCondition{
+		Operator: "expr",
+		Value:    exprComparison{expr: expr, op: op, value: value},
+	}
+}
+
+// EqExpr 表达式等于值，值的参数排在表达式参数之后
+func EqExpr(expr Expression, value interface{}) Condition {
+	return exprCondition(expr, "eq", value)
+}
+
+// NeExpr 表达式不等于值
+func NeExpr(expr Expression, value interface{}) Condition {
+	return exprCondition(expr, "ne", value)
+}
+
+// GtExpr 表达式大于值
+func GtExpr(expr Expression, value interface{}) Condition {
+	return exprCondition(expr, "gt", value)
+}
+
+// GteExpr 表达式大于等于值
+func GteExpr(expr Expression, value interface{}) Condition {
+	return exprCondition(expr, "gte", value)
+}
+
+// LtExpr 表达式小于值
+func LtExpr(expr Expression, value interface{}) Condition {
+	return exprCondition(expr, "lt", value)
+}
+
+// LteExpr 表达式小于等于值
+func LteExpr(expr Expression, value interface{}) Condition {
+	return exprCondition(expr, "lte", value)
+}
+
 // Between BETWEEN 条件
 func Between(field string, min, max interface{}) Condition {
 	return &SimpleCondition{
@@ -586,6 +867,148 @@ func Like(field string, pattern string) Condition {
 	}
 }
 
+// IsNull IS NULL 条件（不绑定参数）
+func IsNull(field string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "isnull",
+	}
+}
+
+// IsNotNull IS NOT NULL 条件（不绑定参数）
+func IsNotNull(field string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "notnull",
+	}
+}
+
+// ILike 大小写不敏感的模糊匹配（PostgreSQL 为 ILIKE，其他数据库为 LOWER(col) LIKE LOWER(?)）
+func ILike(field string, pattern string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "ilike",
+		Value:    pattern,
+	}
+}
+
+// LikeAny 匹配任一模式（PostgreSQL 为 LIKE ANY (ARRAY[...])，其他数据库展开为 OR）
+func LikeAny(field string, patterns ...string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "like_any",
+		Value:    stringsToInterfaces(patterns),
+	}
+}
+
+// NotLikeAll 不匹配所有模式（PostgreSQL 为 NOT LIKE ALL (ARRAY[...])，其他数据库展开为 AND）
+func NotLikeAll(field string, patterns ...string) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "not_like_all",
+		Value:    stringsToInterfaces(patterns),
+	}
+}
+
+// ArrayContains 数组列包含给定的所有元素：field @> ARRAY[...]（仅 PostgreSQL）
+// value 可以是单个元素或切片，切片按元素分别绑定
+func ArrayContains(field string, value interface{}) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "array_contains",
+		Value:    arrayElements(value),
+	}
+}
+
+// ArrayOverlaps 数组列与给定元素有交集：field && ARRAY[...]（仅 PostgreSQL）
+func ArrayOverlaps(field string, value interface{}) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "array_overlaps",
+		Value:    arrayElements(value),
+	}
+}
+
+// arrayElements 把切片展开为元素列表，其他值（包括 []byte）作为单个元素
+func arrayElements(value interface{}) []interface{} {
+	if _, ok := value.([]byte); ok {
+		return []interface{}{value}
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	elements := make([]interface{}, v.Len())
+	for i := range elements {
+		elements[i] = v.Index(i).Interface()
+	}
+	return elements
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// tupleInValue TupleIn 的字段列表和值行
+type tupleInValue struct {
+	fields []string
+	rows   [][]interface{}
+}
+
+// TupleIn 行值 IN 条件，用于复合键查找，例如 (tenant_id, slug) IN ((1, 'a'), (1, 'b'))
+// 不支持行值比较的方言（SQLite、SQL Server）展开为 OR 连接的 AND 组
+func TupleIn(fields []string, rows [][]interface{}) Condition {
+	return &SimpleCondition{
+		Field:    strings.Join(fields, ","),
+		Operator: "tuple_in",
+		Value:    tupleInValue{fields: fields, rows: rows},
+	}
+}
+
+// DefaultSRID GeomWithin 默认使用的空间参考（WGS 84）
+const DefaultSRID = 4326
+
+// BBox 经纬度包围盒
+type BBox struct {
+	MinLat, MinLng float64
+	MaxLat, MaxLng float64
+	SRID           int // 为 0 时使用 DefaultSRID
+}
+
+// WithinBBox 普通经纬度列的包围盒条件
+// MinLng 大于 MaxLng 时视为跨越 180° 经线，经度条件改为两段范围的 OR
+func WithinBBox(latField, lngField string, minLat, minLng, maxLat, maxLng float64) Condition {
+	lat := Between(latField, minLat, maxLat)
+	if minLng > maxLng {
+		return And(lat, Or(Gte(lngField, minLng), Lte(lngField, maxLng)))
+	}
+	return And(lat, Between(lngField, minLng, maxLng))
+}
+
+// GeomWithin 几何列落在包围盒内（PostGIS：ST_Within + ST_MakeEnvelope）
+// 仅支持 SupportsGeo 的方言，其他方言在构建时报错
+func GeomWithin(field string, bbox BBox) Condition {
+	return &SimpleCondition{
+		Field:    field,
+		Operator: "geom_within",
+		Value:    bbox,
+	}
+}
+
+// NowValue 数据库当前时间哨兵值，见 Now
+type NowValue struct{}
+
+// Now 返回可作为比较操作数的当前时间哨兵值
+// 例如 Lt("expires_at", Now())，由数据库计算当前时间（渲染为 CURRENT_TIMESTAMP / NOW()），
+// 不绑定参数，避免应用与数据库之间的时钟偏差
+func Now() NowValue {
+	return NowValue{}
+}
+
 // And AND 条件
 func And(conditions ...Condition) Condition {
 	return &CompositeCondition{
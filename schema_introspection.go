@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ColumnDescription 从数据库读取到的列结构
+type ColumnDescription struct {
+	Name          string
+	Type          string // 数据库原生类型，例如 VARCHAR(255)、INTEGER
+	Nullable      bool
+	Default       *string // 默认值表达式（原样返回），无默认值时为 nil
+	Primary       bool
+	AutoIncrement bool
+}
+
+// TableDescription 从数据库读取到的表结构
+type TableDescription struct {
+	Name    string
+	Columns []*ColumnDescription
+}
+
+// PrimaryKey 返回主键列名（按列顺序）
+func (t *TableDescription) PrimaryKey() []string {
+	keys := make([]string, 0)
+	for _, col := range t.Columns {
+		if col.Primary {
+			keys = append(keys, col.Name)
+		}
+	}
+	return keys
+}
+
+// DescribeTable 读取表的列结构
+// SQLite 使用 PRAGMA table_info，PostgreSQL 使用系统目录，MySQL/SQL Server 使用 information_schema
+func (r *Repository) DescribeTable(ctx context.Context, table string) (*TableDescription, error) {
+	var (
+		desc *TableDescription
+		err  error
+	)
+
+	switch r.GetAdapter().(type) {
+	case *SQLiteAdapter:
+		desc, err = r.describeSQLiteTable(ctx, table)
+	case *PostgreSQLAdapter:
+		desc, err = r.describeTableWith(ctx, table, postgresDescribeTableSQL, table)
+	case *MySQLAdapter:
+		desc, err = r.describeTableWith(ctx, table, mysqlDescribeTableSQL, table)
+	case *SQLServerAdapter:
+		desc, err = r.describeTableWith(ctx, table, sqlServerDescribeTableSQL, table)
+	default:
+		return nil, fmt.Errorf("DescribeTable is not supported by adapter %T", r.GetAdapter())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table %s: %w", table, err)
+	}
+	if len(desc.Columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+	return desc, nil
+}
+
+const postgresDescribeTableSQL = `
+SELECT a.attname,
+       format_type(a.atttypid, a.atttypmod),
+       NOT a.attnotnull,
+       pg_get_expr(d.adbin, d.adrelid),
+       EXISTS (SELECT 1 FROM pg_index i WHERE i.indrelid = a.attrelid AND i.indisprimary AND a.attnum = ANY(i.indkey)),
+       COALESCE(pg_get_expr(d.adbin, d.adrelid) LIKE 'nextval(%', false)
+FROM pg_attribute a
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = $1::regclass AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`
+
+const mysqlDescribeTableSQL = `
+SELECT COLUMN_NAME,
+       COLUMN_TYPE,
+       IS_NULLABLE = 'YES',
+       COLUMN_DEFAULT,
+       COLUMN_KEY = 'PRI',
+       EXTRA LIKE '%auto_increment%'
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`
+
+const sqlServerDescribeTableSQL = `
+SELECT c.COLUMN_NAME,
+       c.DATA_TYPE + CASE
+           WHEN c.CHARACTER_MAXIMUM_LENGTH = -1 THEN '(MAX)'
+           WHEN c.CHARACTER_MAXIMUM_LENGTH IS NOT NULL THEN '(' + CAST(c.CHARACTER_MAXIMUM_LENGTH AS VARCHAR(10)) + ')'
+           WHEN c.DATA_TYPE IN ('decimal', 'numeric') THEN '(' + CAST(c.NUMERIC_PRECISION AS VARCHAR(10)) + ',' + CAST(c.NUMERIC_SCALE AS VARCHAR(10)) + ')'
+           ELSE '' END,
+       CASE WHEN c.IS_NULLABLE = 'YES' THEN 1 ELSE 0 END,
+       c.COLUMN_DEFAULT,
+       CASE WHEN k.COLUMN_NAME IS NULL THEN 0 ELSE 1 END,
+       COALESCE(COLUMNPROPERTY(OBJECT_ID(c.TABLE_NAME), c.COLUMN_NAME, 'IsIdentity'), 0)
+FROM INFORMATION_SCHEMA.COLUMNS c
+LEFT JOIN (
+    SELECT ku.COLUMN_NAME
+    FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+    JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE ku ON tc.CONSTRAINT_NAME = ku.CONSTRAINT_NAME
+    WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY' AND tc.TABLE_NAME = @p1
+) k ON k.COLUMN_NAME = c.COLUMN_NAME
+WHERE c.TABLE_NAME = @p1
+ORDER BY c.ORDINAL_POSITION`
+
+// describeTableWith 执行返回 (name, type, nullable, default, primary, autoinc) 的查询
+func (r *Repository) describeTableWith(ctx context.Context, table, query string, args ...interface{}) (*TableDescription, error) {
+	rows, err := r.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	desc := &TableDescription{Name: table, Columns: make([]*ColumnDescription, 0)}
+	for rows.Next() {
+		col := &ColumnDescription{}
+		var def sql.NullString
+		if err := rows.Scan(&col.Name, &col.Type, &col.Nullable, &def, &col.Primary, &col.AutoIncrement); err != nil {
+			return nil, err
+		}
+		if def.Valid {
+			col.Default = &def.String
+		}
+		desc.Columns = append(desc.Columns, col)
+	}
+	return desc, rows.Err()
+}
+
+var sqliteAutoincrementPattern = regexp.MustCompile(`(?i)\bAUTOINCREMENT\b`)
+
+// describeSQLiteTable 通过 PRAGMA table_info 读取 SQLite 表结构
+func (r *Repository) describeSQLiteTable(ctx context.Context, table string) (*TableDescription, error) {
+	rows, err := r.Query(ctx, fmt.Sprintf("PRAGMA table_info(%s)", quoteSQLiteIdent(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	desc := &TableDescription{Name: table, Columns: make([]*ColumnDescription, 0)}
+	for rows.Next() {
+		var (
+			cid     int
+			notNull int
+			pk      int
+			def     sql.NullString
+		)
+		col := &ColumnDescription{}
+		if err := rows.Scan(&cid, &col.Name, &col.Type, &notNull, &def, &pk); err != nil {
+			return nil, err
+		}
+		col.Nullable = notNull == 0 && pk == 0
+		col.Primary = pk > 0
+		if def.Valid {
+			col.Default = &def.String
+		}
+		desc.Columns = append(desc.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// AUTOINCREMENT 只能从建表语句中识别，且只适用于单列 INTEGER 主键
+	pk := desc.PrimaryKey()
+	if len(pk) == 1 {
+		var createSQL sql.NullString
+		row := r.QueryRow(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table)
+		if row != nil && row.Scan(&createSQL) == nil && sqliteAutoincrementPattern.MatchString(createSQL.String) {
+			for _, col := range desc.Columns {
+				if col.Primary && strings.EqualFold(col.Type, "INTEGER") {
+					col.AutoIncrement = true
+				}
+			}
+		}
+	}
+	return desc, nil
+}
+
+func quoteSQLiteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ==================== SQL 录制 ====================
+// 测试/调试工具：开启后记录查询、INSERT、UPDATE 构造器每次 Build 成功生成的 SQL，
+// 便于做 SQL 快照测试或确认没有生成意料之外的查询。只记录参数个数，不记录参数值
+
+// SQLRecorder 录制生成的 SQL
+type SQLRecorder struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+// activeRecorder 当前生效的录制器，为 nil 时不录制
+var activeRecorder atomic.Pointer[SQLRecorder]
+
+// EnableSQLRecording 开启全局 SQL 录制并返回新的录制器，之前的录制器停止接收
+func EnableSQLRecording() *SQLRecorder {
+	recorder := &SQLRecorder{}
+	activeRecorder.Store(recorder)
+	return recorder
+}
+
+// Disable 停止录制，已录制的内容仍可通过 Recorded 读取
+func (r *SQLRecorder) Disable() {
+	activeRecorder.CompareAndSwap(r, nil)
+}
+
+// Recorded 按生成顺序返回录制的 SQL，每条格式为 "<sql> -- args: <n>"
+func (r *SQLRecorder) Recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.entries...)
+}
+
+// recordSQL 录制一次 Build 的输出
+func recordSQL(sql string, args []interface{}) {
+	recorder := activeRecorder.Load()
+	if recorder == nil {
+		return
+	}
+	recorder.mu.Lock()
+	recorder.entries = append(recorder.entries, fmt.Sprintf("%s -- args: %d", sql, len(args)))
+	recorder.mu.Unlock()
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestSQLRecording 测试开启录制后 Build 生成的 SQL 按顺序被记录，关闭后不再记录
+func TestSQLRecording(t *testing.T) {
+	schema := newInsertTestSchema()
+	dialect := NewPostgreSQLDialect()
+	ctx := context.Background()
+
+	recorder := EnableSQLRecording()
+	defer recorder.Disable()
+
+	if _, _, err := NewSQLQueryConstructor(schema, dialect).Where(Eq("id", 1)).Build(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewSQLUpdateConstructor(schema, dialect).Set("name", "bob").Set("age", 3).Where(Eq("id", 1)).Build(ctx); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Disable()
+	if _, _, err := NewSQLQueryConstructor(schema, dialect).Build(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`SELECT "id", "name", "age" FROM "users" WHERE "id" = $1 -- args: 1`,
+		`UPDATE "users" SET "name" = $1, "age" = $2 WHERE "id" = $3 -- args: 3`,
+	}
+	if got := recorder.Recorded(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -145,26 +145,33 @@ func (a *SQLiteAdapter) ListScheduledTasks(ctx context.Context) ([]*ScheduledTas
 
 // SQLiteTx SQLite 事务实现
 type SQLiteTx struct {
-	tx *sql.Tx
+	tx    *sql.Tx
+	state txState
 }
 
-// Commit 提交事务
+// Commit 提交事务，重复提交是空操作
 func (t *SQLiteTx) Commit(ctx context.Context) error {
-	return t.tx.Commit()
+	return t.state.finish(true, t.tx.Commit)
 }
 
-// Rollback 回滚事务
+// Rollback 回滚事务，事务已结束时是空操作
 func (t *SQLiteTx) Rollback(ctx context.Context) error {
-	return t.tx.Rollback()
+	return t.state.finish(false, t.tx.Rollback)
 }
 
 // Exec 在事务中执行
 func (t *SQLiteTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	return t.tx.ExecContext(ctx, query, args...)
 }
 
 // Query 在事务中查询
 func (t *SQLiteTx) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	return t.tx.QueryContext(ctx, query, args...)
 }
 
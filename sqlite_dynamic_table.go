@@ -140,6 +140,9 @@ func (h *SQLiteDynamicTableHook) ListCreatedDynamicTables(ctx context.Context, c
 		ORDER BY name
 	`
 
+	if !h.connected() {
+		return nil, ErrNotConnected
+	}
 	rows, err := h.adapter.Query(ctx, query, prefix)
 	if err != nil {
 		return nil, err
@@ -211,15 +214,8 @@ func (h *SQLiteDynamicTableHook) handleAfterCreateCallback(db *gorm.DB, config *
 
 // shouldCreateDynamicTable 判断是否应该创建动态表
 func (h *SQLiteDynamicTableHook) shouldCreateDynamicTable(record interface{}, config *DynamicTableConfig) bool {
-	if config.TriggerCondition == "" {
-		// 如果没有条件，总是创建
-		return true
-	}
-
-	// 简单的条件判断：检查字段值
-	// 例如：TriggerCondition = "type = 'custom'"
-	// 这里只是示例，实际可能需要更复杂的条件评估
-	return true
+	// 没有条件时总是创建，否则按 TriggerCondition 对记录的字段值求值，例如 Eq("type", "custom")
+	return config.MatchesRecord(record)
 }
 
 // extractParamsFromRecord 从记录中提取参数
@@ -241,41 +237,12 @@ func (h *SQLiteDynamicTableHook) extractParamsFromRecord(record interface{}, con
 
 // createTable 创建动态表
 func (h *SQLiteDynamicTableHook) createTable(ctx context.Context, config *DynamicTableConfig, tableName string) error {
-	var sql strings.Builder
-	sql.WriteString("CREATE TABLE IF NOT EXISTS ")
-	sql.WriteString(h.quoteIdentifier(tableName))
-	sql.WriteString(" (")
-
-	for i, field := range config.Fields {
-		if i > 0 {
-			sql.WriteString(", ")
-		}
-
-		sql.WriteString(h.quoteIdentifier(field.Name))
-		sql.WriteString(" ")
-		sql.WriteString(h.mapFieldType(field.Type))
-
-		if field.Autoinc && field.Primary {
-			sql.WriteString(" PRIMARY KEY AUTOINCREMENT")
-		} else if field.Primary {
-			sql.WriteString(" PRIMARY KEY")
-		}
-
-		if !field.Null {
-			sql.WriteString(" NOT NULL")
-		}
-		if field.Default != nil {
-			sql.WriteString(" DEFAULT ")
-			sql.WriteString(fmt.Sprint(field.Default))
-		}
-		if field.Unique {
-			sql.WriteString(" UNIQUE")
-		}
-	}
-
-	sql.WriteString(")")
+	return h.executeSQL(ctx, NewDDLBuilder(NewSQLiteDialect()).CreateTable(config.ToSchema(tableName)))
+}
 
-	return h.executeSQL(ctx, sql.String())
+// connected 适配器是否已连接
+func (h *SQLiteDynamicTableHook) connected() bool {
+	return h.adapter != nil && h.adapter.sqlDB != nil
 }
 
 // tableExists 检查表是否存在
@@ -287,6 +254,9 @@ func (h *SQLiteDynamicTableHook) tableExists(ctx context.Context, tableName stri
 		AND name = ?
 	`
 
+	if !h.connected() {
+		return false, ErrNotConnected
+	}
 	var exists bool
 	row := h.adapter.QueryRow(ctx, query, tableName)
 	if err := row.Scan(&exists); err != nil {
@@ -300,9 +270,14 @@ func (h *SQLiteDynamicTableHook) tableExists(ctx context.Context, tableName stri
 func (h *SQLiteDynamicTableHook) generateTableName(config *DynamicTableConfig, params map[string]interface{}) string {
 	// 简单实现：使用 id 参数作为后缀
 	if id, ok := params["id"]; ok {
-		return fmt.Sprintf("%s_%v", config.TableName, id)
+		return h.shortenName(fmt.Sprintf("%s_%v", config.TableName, id))
 	}
-	return config.TableName
+	return h.shortenName(config.TableName)
+}
+
+// shortenName 按方言的标识符长度上限截断生成的名称
+func (h *SQLiteDynamicTableHook) shortenName(name string) string {
+	return shortenIdentifier(name, NewSQLiteDialect().MaxIdentifierLength())
 }
 
 // quoteIdentifier 引用标识符
@@ -310,32 +285,6 @@ func (h *SQLiteDynamicTableHook) quoteIdentifier(name string) string {
 	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
 
-// mapFieldType 将字段类型映射到 SQLite 类型
-func (h *SQLiteDynamicTableHook) mapFieldType(fieldType FieldType) string {
-	switch fieldType {
-	case TypeString:
-		return "TEXT"
-	case TypeInteger:
-		return "INTEGER"
-	case TypeFloat:
-		return "REAL"
-	case TypeBoolean:
-		return "INTEGER" // SQLite 使用 0/1 表示布尔值
-	case TypeTime:
-		return "TEXT" // SQLite 使用文本存储时间
-	case TypeBinary:
-		return "BLOB"
-	case TypeDecimal:
-		return "REAL"
-	case TypeJSON:
-		return "TEXT"
-	case TypeArray:
-		return "TEXT"
-	default:
-		return "TEXT"
-	}
-}
-
 // executeSQL 执行 SQL
 func (h *SQLiteDynamicTableHook) executeSQL(ctx context.Context, sql string) error {
 	_, err := h.adapter.Exec(ctx, sql)
@@ -72,7 +72,7 @@ func (a *SQLServerAdapter) Connect(ctx context.Context, config *Config) error {
 	db, err := gorm.Open(sqlserver.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return fmt.Errorf("failed to connect to SQL Server (host=%s, port=%d, user=%s, db=%s): %w",
-			config.Host, config.Port, config.Username, config.Database, err)
+			config.Host, config.Port, config.Username, config.Database, redactError(err, password))
 	}
 
 	a.db = db
@@ -187,26 +187,33 @@ func (a *SQLServerAdapter) ListScheduledTasks(ctx context.Context) ([]*Scheduled
 
 // SQLServerTx SQL Server 事务实现
 type SQLServerTx struct {
-	tx *sql.Tx
+	tx    *sql.Tx
+	state txState
 }
 
-// Commit 提交事务
+// Commit 提交事务，重复提交是空操作
 func (t *SQLServerTx) Commit(ctx context.Context) error {
-	return t.tx.Commit()
+	return t.state.finish(true, t.tx.Commit)
 }
 
-// Rollback 回滚事务
+// Rollback 回滚事务，事务已结束时是空操作
 func (t *SQLServerTx) Rollback(ctx context.Context) error {
-	return t.tx.Rollback()
+	return t.state.finish(false, t.tx.Rollback)
 }
 
 // Exec 在事务中执行
 func (t *SQLServerTx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	return t.tx.ExecContext(ctx, query, args...)
 }
 
 // Query 在事务中查询
 func (t *SQLServerTx) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if err := t.state.check(); err != nil {
+		return nil, err
+	}
 	return t.tx.QueryContext(ctx, query, args...)
 }
 
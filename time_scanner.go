@@ -0,0 +1,205 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayouts 默认的时间解析格式（按顺序尝试）
+// 覆盖 RFC3339、SQLite 驱动写入的格式以及常见的 DATETIME/DATE 文本格式
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// TimeScanner 时间列扫描器
+// 不同驱动返回的时间表示不一致：SQLite 返回字符串，MySQL 可能返回 []byte，
+// PostgreSQL 返回 time.Time。TimeScanner 将它们统一转换为 time.Time
+type TimeScanner struct {
+	Layouts  []string       // 解析格式，为空时使用 DefaultTimeLayouts
+	Location *time.Location // 不带时区的文本所使用的时区，默认 UTC
+}
+
+// DefaultTimeScanner 扫描辅助函数默认使用的时间扫描器
+var DefaultTimeScanner = NewTimeScanner()
+
+// NewTimeScanner 创建时间扫描器
+func NewTimeScanner(layouts ...string) *TimeScanner {
+	return &TimeScanner{Layouts: layouts}
+}
+
+// Parse 将驱动返回的值转换为 time.Time
+func (s *TimeScanner) Parse(src interface{}) (time.Time, error) {
+	switch v := src.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return s.parseString(v)
+	case []byte:
+		return s.parseString(string(v))
+	case int64:
+		return time.Unix(v, 0).UTC(), nil
+	case nil:
+		return time.Time{}, fmt.Errorf("TimeScanner: cannot convert NULL to time.Time")
+	default:
+		return time.Time{}, fmt.Errorf("TimeScanner: unsupported source type %T", src)
+	}
+}
+
+func (s *TimeScanner) parseString(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	layouts := s.Layouts
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("TimeScanner: cannot parse %q as time (tried layouts: %s)",
+		value, strings.Join(layouts, ", "))
+}
+
+// Target 返回写入 dest 的 sql.Scanner，可直接传给 rows.Scan
+func (s *TimeScanner) Target(dest *time.Time) sql.Scanner {
+	return &timeScanTarget{scanner: s, dest: reflect.ValueOf(dest).Elem()}
+}
+
+// timeScanTarget 把扫描结果写入 time.Time 或 *time.Time 字段
+type timeScanTarget struct {
+	scanner *TimeScanner
+	dest    reflect.Value
+}
+
+func (t *timeScanTarget) Scan(src interface{}) error {
+	if t.dest.Kind() == reflect.Ptr {
+		if src == nil {
+			t.dest.Set(reflect.Zero(t.dest.Type()))
+			return nil
+		}
+		parsed, err := t.scanner.Parse(src)
+		if err != nil {
+			return err
+		}
+		t.dest.Set(reflect.ValueOf(&parsed))
+		return nil
+	}
+
+	parsed, err := t.scanner.Parse(src)
+	if err != nil {
+		return err
+	}
+	t.dest.Set(reflect.ValueOf(parsed))
+	return nil
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
+// timeScanDest 如果字段是 time.Time 或 *time.Time，返回对应的扫描目标
+func timeScanDest(field reflect.Value, scanner *TimeScanner) (interface{}, bool) {
+	if field.Type() != timeType && field.Type() != timePtrType {
+		return nil, false
+	}
+	if scanner == nil {
+		scanner = DefaultTimeScanner
+	}
+	return &timeScanTarget{scanner: scanner, dest: field}, true
+}
+
+// ScanMaps 将 sql.Rows 扫描为 map 列表
+// 提供 schema 时，TypeTime 列会通过 DefaultTimeScanner 统一转换为 time.Time，
+// []byte 值转换为字符串（TypeBinary 列除外），带序列化钩子的字段调用 load；
+// 数据库类型登记在 DefaultTypeRegistry 中的列使用其 Scan 函数转换
+func ScanMaps(rows *sql.Rows, schema Schema) ([]map[string]interface{}, error) {
+	if rows == nil {
+		return nil, fmt.Errorf("ScanMaps: rows must not be nil")
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("ScanMaps: failed to get columns: %w", err)
+	}
+
+	typeNames := columnTypeNames(rows)
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		row, err := scanMapRow(rows, columns, typeNames, schema)
+		if err != nil {
+			return nil, fmt.Errorf("ScanMaps: %w", err)
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ScanMaps: rows error: %w", err)
+	}
+	return result, nil
+}
+
+// scanMapRow 把当前行扫描为 map，typeNames 为各列的数据库类型名（可为 nil）
+func scanMapRow(rows *sql.Rows, columns, typeNames []string, schema Schema) (map[string]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		typeName := ""
+		if i < len(typeNames) {
+			typeName = typeNames[i]
+		}
+		value, err := normalizeScannedValue(schema, col, typeName, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col, err)
+		}
+		row[col] = value
+	}
+	return row, nil
+}
+
+// normalizeScannedValue 按 schema 字段类型规范化扫描到的原始值
+func normalizeScannedValue(schema Schema, column, typeName string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var field *Field
+	if schema != nil {
+		field = schema.GetField(column)
+	}
+
+	if field != nil && field.Serializer != nil && field.Serializer.Load != nil {
+		return field.LoadValue(value)
+	}
+	if customType, ok := DefaultTypeRegistry.Lookup(typeName); ok && typeName != "" {
+		return customType.Scan(value)
+	}
+	if field != nil && field.Type == TypeTime {
+		return DefaultTimeScanner.Parse(value)
+	}
+	if b, ok := value.([]byte); ok && (field == nil || field.Type != TypeBinary) {
+		return string(b), nil
+	}
+	return value, nil
+}
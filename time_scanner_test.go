@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+// TestTimeScannerRepresentations 测试不同驱动的时间表示都被统一转换
+func TestTimeScannerRepresentations(t *testing.T) {
+	expected := time.Date(2024, 3, 15, 10, 30, 45, 0, time.UTC)
+	scanner := NewTimeScanner()
+
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{"time.Time (PostgreSQL)", expected},
+		{"RFC3339 string", "2024-03-15T10:30:45Z"},
+		{"SQLite string", "2024-03-15 10:30:45+00:00"},
+		{"MySQL []byte", []byte("2024-03-15 10:30:45")},
+		{"unix seconds", expected.Unix()},
+	}
+
+	for _, tt := range tests {
+		got, err := scanner.Parse(tt.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !got.Equal(expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, expected, got)
+		}
+	}
+
+	if _, err := scanner.Parse("not a time"); err == nil {
+		t.Error("Expected error for unparseable input")
+	}
+
+	custom := NewTimeScanner("02/01/2006")
+	if got, err := custom.Parse("15/03/2024"); err != nil || got.Day() != 15 || got.Month() != time.March {
+		t.Errorf("Expected custom layout to parse, got %v (%v)", got, err)
+	}
+}
+
+// TestScanWithTimeScanner 测试扫描辅助函数对时间列的处理
+func TestScanWithTimeScanner(t *testing.T) {
+	repo, fake := newFakeRepository(NewSQLiteDialect())
+	defer repo.Close()
+
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{
+			columns: []string{"id", "created_at", "deleted_at"},
+			values: [][]driver.Value{
+				{int64(1), "2024-03-15 10:30:45", nil},
+				{int64(2), []byte("2024-03-16T08:00:00Z"), "2024-03-17"},
+			},
+		}, nil
+	}
+
+	type event struct {
+		ID        int64      `db:"id"`
+		CreatedAt time.Time  `db:"created_at"`
+		DeletedAt *time.Time `db:"deleted_at"`
+	}
+
+	var events []event
+	if err := repo.QueryStructs(context.Background(), &events, "SELECT * FROM events"); err != nil {
+		t.Fatalf("QueryStructs failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if !events[0].CreatedAt.Equal(time.Date(2024, 3, 15, 10, 30, 45, 0, time.UTC)) || events[0].DeletedAt != nil {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1].DeletedAt == nil || events[1].DeletedAt.Day() != 17 {
+		t.Errorf("Expected deleted_at to be parsed, got %v", events[1].DeletedAt)
+	}
+
+	schema := NewBaseSchema("events")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("created_at", TypeTime).Build())
+	schema.AddField(NewField("deleted_at", TypeTime).Null(true).Build())
+
+	rows, err := repo.Query(context.Background(), "SELECT * FROM events")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	defer rows.Close()
+
+	maps, err := ScanMaps(rows, schema)
+	if err != nil {
+		t.Fatalf("ScanMaps failed: %v", err)
+	}
+	if _, ok := maps[1]["created_at"].(time.Time); !ok {
+		t.Errorf("Expected created_at to be time.Time, got %T", maps[1]["created_at"])
+	}
+	if maps[0]["deleted_at"] != nil {
+		t.Errorf("Expected NULL deleted_at to stay nil, got %v", maps[0]["deleted_at"])
+	}
+}
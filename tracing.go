@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ==================== 链路追踪中间件 ====================
+// 核心包不直接依赖 OpenTelemetry：这里只定义最小的 Tracer/Span 接口，
+// 调用方用几行代码把 otel 的 trace.Tracer 适配进来即可，例如：
+//
+//	type otelTracer struct{ t trace.Tracer }
+//	func (o otelTracer) Start(ctx context.Context, name string) (context.Context, db.Span) {
+//		ctx, span := o.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{span}
+//	}
+
+// Span 追踪 span 的最小接口（与 otel trace.Span 的子集对应）
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracer 追踪器接口
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span 名称和属性键（遵循 OpenTelemetry 数据库语义约定）
+const (
+	SpanNameQuery = "db.query"
+	SpanNameExec  = "db.exec"
+
+	AttrDBSystem    = "db.system"
+	AttrDBStatement = "db.statement"
+	AttrDBArgsCount = "db.args_count"
+	AttrDBDuration  = "db.duration_ms"
+)
+
+// TracingMiddleware 创建链路追踪中间件
+// 每次调用创建一个 span（查询为 db.query，执行为 db.exec），
+// 记录脱敏后的 SQL、参数个数、适配器名称和耗时，出错时在 span 上记录错误
+func TracingMiddleware(tracer Tracer, adapterName string) func(next QueryFunc) QueryFunc {
+	return func(next QueryFunc) QueryFunc {
+		return func(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+			name := SpanNameQuery
+			if op.Kind == OpExec {
+				name = SpanNameExec
+			}
+
+			ctx, span := tracer.Start(ctx, name)
+			defer span.End()
+
+			span.SetAttribute(AttrDBSystem, adapterName)
+			span.SetAttribute(AttrDBStatement, SanitizeSQL(op.SQL))
+			span.SetAttribute(AttrDBArgsCount, len(op.Args))
+
+			start := time.Now()
+			res, err := next(ctx, op)
+			span.SetAttribute(AttrDBDuration, float64(time.Since(start).Microseconds())/1000)
+
+			if err != nil {
+				span.RecordError(err)
+			}
+			return res, err
+		}
+	}
+}
+
+var (
+	sqlStringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumericLiteral = regexp.MustCompile(`(^|[^\w$])\d+(?:\.\d+)?\b`)
+	sqlWhitespace     = regexp.MustCompile(`\s+`)
+)
+
+// SanitizeSQL 对 SQL 脱敏：把字符串和数字字面量替换为 ?，并压缩空白
+// 参数化查询的参数本身不会出现在语句中，这里只处理内联的字面量
+func SanitizeSQL(sql string) string {
+	sql = sqlStringLiteral.ReplaceAllString(sql, "?")
+	sql = sqlNumericLiteral.ReplaceAllString(sql, "${1}?")
+	return strings.TrimSpace(sqlWhitespace.ReplaceAllString(sql, " "))
+}
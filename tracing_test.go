@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type mockSpan struct {
+	name   string
+	attrs  map[string]interface{}
+	errs   []error
+	ended  bool
+	tracer *mockTracer
+}
+
+func (s *mockSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *mockSpan) RecordError(err error)                      { s.errs = append(s.errs, err) }
+func (s *mockSpan) End()                                       { s.ended = true }
+
+type mockTracer struct {
+	mu    sync.Mutex
+	spans []*mockSpan
+}
+
+func (t *mockTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &mockSpan{name: name, attrs: make(map[string]interface{}), tracer: t}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+// TestTracingMiddlewareExec 测试 exec 调用会创建并结束带属性的 span
+func TestTracingMiddlewareExec(t *testing.T) {
+	repo, _ := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+
+	tracer := &mockTracer{}
+	repo.Use(TracingMiddleware(tracer, "postgres"))
+
+	_, err := repo.Exec(context.Background(), "UPDATE users SET name = $1 WHERE status = 'active' AND age > 18", "alice")
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != SpanNameExec {
+		t.Errorf("Expected span name %s, got %s", SpanNameExec, span.name)
+	}
+	if !span.ended {
+		t.Error("Expected span to be ended")
+	}
+	if span.attrs[AttrDBSystem] != "postgres" {
+		t.Errorf("Unexpected db.system: %v", span.attrs[AttrDBSystem])
+	}
+	if span.attrs[AttrDBArgsCount] != 1 {
+		t.Errorf("Unexpected args count: %v", span.attrs[AttrDBArgsCount])
+	}
+	expectedSQL := "UPDATE users SET name = $1 WHERE status = ? AND age > ?"
+	if span.attrs[AttrDBStatement] != expectedSQL {
+		t.Errorf("Expected statement %q, got %q", expectedSQL, span.attrs[AttrDBStatement])
+	}
+	if _, ok := span.attrs[AttrDBDuration]; !ok {
+		t.Error("Expected duration attribute")
+	}
+	if len(span.errs) != 0 {
+		t.Errorf("Expected no recorded errors, got %v", span.errs)
+	}
+}
+
+// TestTracingMiddlewareRecordsError 测试失败的调用会在 span 上记录错误
+func TestTracingMiddlewareRecordsError(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	defer repo.Close()
+
+	failure := errors.New("deadlock")
+	fake.execFn = func(query string, args []interface{}) (driver.Result, error) {
+		return nil, failure
+	}
+
+	tracer := &mockTracer{}
+	repo.Use(TracingMiddleware(tracer, "mysql"))
+
+	if _, err := repo.Exec(context.Background(), "DELETE FROM users"); err == nil {
+		t.Fatal("Expected exec error")
+	}
+
+	span := tracer.spans[0]
+	if len(span.errs) != 1 || !errors.Is(span.errs[0], failure) {
+		t.Errorf("Expected recorded error %v, got %v", failure, span.errs)
+	}
+	if !span.ended {
+		t.Error("Expected span to be ended")
+	}
+}
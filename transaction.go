@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTransactionClosed 事务已经提交或回滚后继续执行语句（或回滚后再提交）时返回的错误
+var ErrTransactionClosed = errors.New("transaction is already committed or rolled back")
+
+// 事务状态
+const (
+	txActive = iota
+	txCommitted
+	txRolledBack
+)
+
+// txState 记录事务是否已经结束，供各适配器的 Tx 实现共用
+// 结束后 Query/Exec 返回 ErrTransactionClosed；重复 Commit、重复或提交后的 Rollback 是空操作，
+// 便于 defer tx.Rollback 的写法。QueryRow 无法返回错误，仍由驱动在 Scan 时报告
+type txState struct {
+	mu    sync.Mutex
+	state int
+}
+
+// check 事务已结束时返回 ErrTransactionClosed
+func (s *txState) check() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != txActive {
+		return ErrTransactionClosed
+	}
+	return nil
+}
+
+// finish 第一次结束事务时执行 fn，之后的调用按上述规则处理
+func (s *txState) finish(commit bool, fn func() error) error {
+	s.mu.Lock()
+	state := s.state
+	if state == txActive {
+		if commit {
+			s.state = txCommitted
+		} else {
+			s.state = txRolledBack
+		}
+	}
+	s.mu.Unlock()
+
+	switch {
+	case state == txActive:
+		return fn()
+	case commit && state == txRolledBack:
+		return ErrTransactionClosed
+	}
+	return nil
+}
+
+// TxOptions Repository.Transaction 的事务选项
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	// StatementTimeout 事务内单条语句的最长执行/等待时间，为 0 时不限制
+	// PostgreSQL 在事务开始时执行 SET LOCAL statement_timeout（毫秒，事务结束自动恢复），
+	// MySQL 执行 SET innodb_lock_wait_timeout（秒，向上取整）。该变量作用于整个会话，
+	// 因此事务开始时先读取原值，提交或回滚前恢复，避免影响归还连接池后的其它查询。
+	// SQLite 没有语句超时设置，该选项会被忽略，请改用带超时的 ctx
+	StatementTimeout time.Duration
+}
+
+// Transaction 在事务中执行 fn，fn 返回错误时回滚，否则提交
+func (r *Repository) Transaction(ctx context.Context, opts *TxOptions, fn func(tx Tx) error) error {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	tx, err := r.Begin(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+
+	var restore string
+	if stmt := statementTimeoutSQL(r.sqlDialect(), opts.StatementTimeout); stmt != "" {
+		if r.sqlDialect().Name() == "mysql" {
+			var previous int64
+			if err := tx.QueryRow(ctx, "SELECT @@SESSION.innodb_lock_wait_timeout").Scan(&previous); err != nil {
+				tx.Rollback(ctx)
+				return fmt.Errorf("failed to read lock wait timeout: %w", err)
+			}
+			restore = fmt.Sprintf("SET innodb_lock_wait_timeout = %d", previous)
+		}
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+	}
+
+	if err := fn(tx); err != nil {
+		if restore != "" {
+			tx.Exec(ctx, restore)
+		}
+		tx.Rollback(ctx)
+		return err
+	}
+	if restore != "" {
+		if _, err := tx.Exec(ctx, restore); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to restore lock wait timeout: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// statementTimeoutSQL 返回设置事务语句超时的语句，方言不支持或未设置超时时返回空字符串
+func statementTimeoutSQL(dialect SQLDialect, timeout time.Duration) string {
+	if dialect == nil || timeout <= 0 {
+		return ""
+	}
+	switch dialect.Name() {
+	case "postgresql":
+		ms := (timeout + time.Millisecond - 1) / time.Millisecond
+		return fmt.Sprintf("SET LOCAL statement_timeout = %d", ms)
+	case "mysql":
+		seconds := (timeout + time.Second - 1) / time.Second
+		return fmt.Sprintf("SET innodb_lock_wait_timeout = %d", seconds)
+	default:
+		return ""
+	}
+}
+
+// txRepository 返回绑定到 tx 的 Repository，沿用当前适配器的查询构造器（方言）和中间件
+func (r *Repository) txRepository(tx Tx) *Repository {
+	var provider QueryConstructorProvider
+	if adapter := r.GetAdapter(); adapter != nil {
+		provider = adapter.GetQueryBuilderProvider()
+	}
+	return &Repository{adapter: &txAdapter{tx: tx, provider: provider}, middlewares: r.middlewares}
+}
+
+// ExecResult ExecMany 中单条语句的执行结果
+type ExecResult struct {
+	Statement    string
+	RowsAffected int64 // 驱动不支持时为 -1
+}
+
+// ExecManyError ExecMany 中某条语句失败时返回的错误，Index 为失败语句的下标（从 0 开始）
+type ExecManyError struct {
+	Index     int
+	Statement string
+	Err       error
+}
+
+func (e *ExecManyError) Error() string {
+	return fmt.Sprintf("statement %d failed: %v", e.Index, e.Err)
+}
+
+func (e *ExecManyError) Unwrap() error {
+	return e.Err
+}
+
+// ExecMany 在同一个事务中按顺序执行多条语句，返回每条语句的影响行数
+// 任意一条失败时回滚整个事务并返回 *ExecManyError。
+// 注意 MySQL 的 DDL 会隐式提交，失败前已执行的 DDL 无法回滚
+func (r *Repository) ExecMany(ctx context.Context, statements []string) ([]ExecResult, error) {
+	results := make([]ExecResult, 0, len(statements))
+	err := r.Transaction(ctx, nil, func(tx Tx) error {
+		for i, stmt := range statements {
+			result, err := tx.Exec(ctx, stmt)
+			if err != nil {
+				return &ExecManyError{Index: i, Statement: stmt, Err: err}
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				affected = -1
+			}
+			results = append(results, ExecResult{Statement: stmt, RowsAffected: affected})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
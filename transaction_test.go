@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTransactionStatementTimeout 测试事务开始时按方言设置语句超时，MySQL 在结束前恢复会话原值
+func TestTransactionStatementTimeout(t *testing.T) {
+	const update = "UPDATE accounts SET balance = 0"
+	tests := []struct {
+		dialect SQLDialect
+		want    []string
+	}{
+		{NewPostgreSQLDialect(), []string{"BEGIN", "SET LOCAL statement_timeout = 1500", update, "COMMIT"}},
+		{NewMySQLDialect(), []string{
+			"BEGIN",
+			"SELECT @@SESSION.innodb_lock_wait_timeout",
+			"SET innodb_lock_wait_timeout = 2",
+			update,
+			"SET innodb_lock_wait_timeout = 50",
+			"COMMIT",
+		}},
+		{NewSQLiteDialect(), []string{"BEGIN", update, "COMMIT"}},
+	}
+
+	for _, tt := range tests {
+		repo, fake := newFakeRepository(tt.dialect)
+		fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+			return &fakeRows{columns: []string{"timeout"}, values: [][]driver.Value{{int64(50)}}}, nil
+		}
+		err := repo.Transaction(context.Background(), &TxOptions{StatementTimeout: 1500 * time.Millisecond}, func(tx Tx) error {
+			_, err := tx.Exec(context.Background(), update)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("%s: Transaction failed: %v", tt.dialect.Name(), err)
+		}
+
+		got := fake.Statements()
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: expected statements %v, got %v", tt.dialect.Name(), tt.want, got)
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: expected statement %d to be %q, got %q", tt.dialect.Name(), i, tt.want[i], got[i])
+			}
+		}
+	}
+}
+
+// TestTransactionStatementTimeoutRollback 测试 MySQL 事务回滚前同样恢复锁等待超时
+func TestTransactionStatementTimeoutRollback(t *testing.T) {
+	repo, fake := newFakeRepository(NewMySQLDialect())
+	fake.queryFn = func(query string, args []interface{}) (*fakeRows, error) {
+		return &fakeRows{columns: []string{"timeout"}, values: [][]driver.Value{{int64(50)}}}, nil
+	}
+	boom := errors.New("boom")
+	err := repo.Transaction(context.Background(), &TxOptions{StatementTimeout: time.Second}, func(tx Tx) error { return boom })
+	if !errors.Is(err, boom) {
+		t.Fatalf("Expected fn error, got %v", err)
+	}
+	got := fake.Statements()
+	if len(got) != 5 || got[3] != "SET innodb_lock_wait_timeout = 50" || got[4] != "ROLLBACK" {
+		t.Errorf("Expected timeout to be restored before ROLLBACK, got %v", got)
+	}
+}
+
+// TestTransactionRollback 测试 fn 返回错误时回滚
+func TestTransactionRollback(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	boom := errors.New("boom")
+	if err := repo.Transaction(context.Background(), nil, func(tx Tx) error { return boom }); !errors.Is(err, boom) {
+		t.Fatalf("Expected fn error, got %v", err)
+	}
+	got := fake.Statements()
+	if len(got) != 2 || got[0] != "BEGIN" || got[1] != "ROLLBACK" {
+		t.Errorf("Expected BEGIN, ROLLBACK, got %v", got)
+	}
+}
+
+// TestExecMany 测试按顺序执行、逐条返回影响行数以及中途失败时整体回滚
+func TestExecMany(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "exec_many.db")
+
+	results, err := repo.ExecMany(ctx, []string{
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO items (name) VALUES ('a'), ('b'), ('c')",
+		"UPDATE items SET name = 'z' WHERE id > 1",
+	})
+	if err != nil {
+		t.Fatalf("ExecMany failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[1].RowsAffected != 3 || results[2].RowsAffected != 2 {
+		t.Errorf("Unexpected rows affected: %+v", results)
+	}
+	if results[2].Statement != "UPDATE items SET name = 'z' WHERE id > 1" {
+		t.Errorf("Expected results in statement order, got %+v", results)
+	}
+
+	_, err = repo.ExecMany(ctx, []string{
+		"INSERT INTO items (name) VALUES ('d')",
+		"INSERT INTO missing (name) VALUES ('e')",
+		"DELETE FROM items",
+	})
+	var execErr *ExecManyError
+	if !errors.As(err, &execErr) || execErr.Index != 1 {
+		t.Fatalf("Expected ExecManyError at index 1, got %v", err)
+	}
+
+	var count int
+	if err := repo.QueryRow(ctx, "SELECT COUNT(*) FROM items").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("Expected failed script to be rolled back, got %d rows", count)
+	}
+}
+
+// TestTxClosedState 测试事务结束后的 Query/Exec 返回 ErrTransactionClosed，重复提交/回滚是空操作
+func TestTxClosedState(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "tx_closed.db")
+	if _, err := repo.Exec(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := repo.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO items (id) VALUES (1)"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO items (id) VALUES (2)"); !errors.Is(err, ErrTransactionClosed) {
+		t.Errorf("Expected ErrTransactionClosed from Exec after commit, got %v", err)
+	}
+	if _, err := tx.Query(ctx, "SELECT id FROM items"); !errors.Is(err, ErrTransactionClosed) {
+		t.Errorf("Expected ErrTransactionClosed from Query after commit, got %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Errorf("Expected double commit to be a no-op, got %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Errorf("Expected rollback after commit to be a no-op, got %v", err)
+	}
+
+	tx, err = repo.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Errorf("Expected double rollback to be a no-op, got %v", err)
+	}
+	if err := tx.Commit(ctx); !errors.Is(err, ErrTransactionClosed) {
+		t.Errorf("Expected ErrTransactionClosed from commit after rollback, got %v", err)
+	}
+}
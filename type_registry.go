@@ -0,0 +1,95 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CustomType 自定义列类型（PostgreSQL 枚举、hstore、citext 等）的读写方式
+type CustomType struct {
+	// Scan 把驱动返回的原始值转换为应用层的值
+	Scan func(src interface{}) (interface{}, error)
+	// Value 把应用层的值转换为驱动可以写入的值，为空时原样写入
+	Value func(value interface{}) (driver.Value, error)
+}
+
+// TypeRegistry 按数据库类型名（rows.ColumnTypes 的 DatabaseTypeName，大小写不敏感）登记自定义类型
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]*CustomType
+}
+
+// NewTypeRegistry 创建自定义类型注册表
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{types: make(map[string]*CustomType)}
+}
+
+// DefaultTypeRegistry 扫描辅助函数（ScanMaps、Cursor）使用的自定义类型注册表
+var DefaultTypeRegistry = NewTypeRegistry()
+
+// RegisterType 在 DefaultTypeRegistry 中登记自定义类型
+func RegisterType(name string, customType *CustomType) error {
+	return DefaultTypeRegistry.Register(name, customType)
+}
+
+// Register 登记自定义类型，重复登记会覆盖之前的定义
+func (r *TypeRegistry) Register(name string, customType *CustomType) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("custom type name must not be empty")
+	}
+	if customType == nil || customType.Scan == nil {
+		return fmt.Errorf("custom type %s requires a Scan function", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[name] = customType
+	return nil
+}
+
+// Lookup 查找自定义类型
+func (r *TypeRegistry) Lookup(name string) (*CustomType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	customType, ok := r.types[strings.ToUpper(name)]
+	return customType, ok
+}
+
+// Valuer 返回按自定义类型写入 value 的参数，可直接传给 Exec/Query
+func (r *TypeRegistry) Valuer(name string, value interface{}) (driver.Valuer, error) {
+	customType, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("custom type %s is not registered", name)
+	}
+	return customValue{customType: customType, value: value}, nil
+}
+
+// customValue 通过自定义类型的 Value 函数写入
+type customValue struct {
+	customType *CustomType
+	value      interface{}
+}
+
+func (v customValue) Value() (driver.Value, error) {
+	if v.customType.Value == nil {
+		return v.value, nil
+	}
+	return v.customType.Value(v.value)
+}
+
+// columnTypeNames 返回结果集各列的数据库类型名，驱动不提供时返回 nil
+func columnTypeNames(rows *sql.Rows) []string {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(columnTypes))
+	for i, ct := range columnTypes {
+		names[i] = ct.DatabaseTypeName()
+	}
+	return names
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestRegisterCustomType 测试自定义类型列通过登记的 Scan 函数扫描，Valuer 通过 Value 写入
+func TestRegisterCustomType(t *testing.T) {
+	original := DefaultTypeRegistry
+	DefaultTypeRegistry = NewTypeRegistry()
+	t.Cleanup(func() { DefaultTypeRegistry = original })
+
+	// 以 "k=v,k=v" 文本存储的简化 hstore
+	err := RegisterType("hstore", &CustomType{
+		Scan: func(src interface{}) (interface{}, error) {
+			text, _ := src.(string)
+			if b, ok := src.([]byte); ok {
+				text = string(b)
+			}
+			result := make(map[string]string)
+			for _, pair := range strings.Split(text, ",") {
+				if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+					result[kv[0]] = kv[1]
+				}
+			}
+			return result, nil
+		},
+		Value: func(value interface{}) (driver.Value, error) {
+			return "lang=" + value.(map[string]string)["lang"], nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterType failed: %v", err)
+	}
+	if err := RegisterType("citext", &CustomType{}); err == nil {
+		t.Error("Expected error for custom type without Scan")
+	}
+
+	repo := newSQLiteTestRepository(t, "custom_type.db")
+	ctx := context.Background()
+	if _, err := repo.Exec(ctx, "CREATE TABLE settings (id INTEGER PRIMARY KEY, attrs HSTORE, note TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := DefaultTypeRegistry.Valuer("HSTORE", map[string]string{"lang": "go"})
+	if err != nil {
+		t.Fatalf("Valuer failed: %v", err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO settings (attrs, note) VALUES (?, ?)", attrs, "lang=x"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := repo.Query(ctx, "SELECT attrs, note FROM settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	result, err := ScanMaps(rows, nil)
+	if err != nil {
+		t.Fatalf("ScanMaps failed: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(result))
+	}
+	if !reflect.DeepEqual(result[0]["attrs"], map[string]string{"lang": "go"}) {
+		t.Errorf("Expected attrs to scan through registered type, got %#v", result[0]["attrs"])
+	}
+	if result[0]["note"] != "lang=x" {
+		t.Errorf("Expected TEXT column to be left alone, got %#v", result[0]["note"])
+	}
+}
@@ -0,0 +1,299 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrNoChanges SkipUnchanged 过滤后没有需要更新的字段时 Build 返回的错误
+var ErrNoChanges = errors.New("update has no changed fields")
+
+// SQLUpdateConstructor UPDATE 语句构造器
+// SET 的参数在 WHERE 的参数之前，占位符连续编号
+type SQLUpdateConstructor struct {
+	schema     Schema
+	dialect    SQLDialect
+	sets       []updateAssignment
+	conditions []Condition
+	returning  []string
+	allowAll   bool
+
+	// SkipUnchanged 设置：跳过与当前行相同的赋值，current 为 nil 时由 ExecUpdate 查询
+	skipUnchanged bool
+	current       map[string]interface{}
+
+	// 链式调用中产生的错误，在 Build 时返回
+	err error
+}
+
+// updateAssignment SET 子句中的一项
+type updateAssignment struct {
+	field string
+	value interface{}
+}
+
+// NewSQLUpdateConstructor 创建 UPDATE 构造器
+func NewSQLUpdateConstructor(schema Schema, dialect SQLDialect) *SQLUpdateConstructor {
+	return &SQLUpdateConstructor{
+		schema:     schema,
+		dialect:    dialect,
+		sets:       make([]updateAssignment, 0),
+		conditions: make([]Condition, 0),
+	}
+}
+
+// Set 设置字段的新值，按调用顺序输出；重复设置同一字段时以最后一次为准
+func (uc *SQLUpdateConstructor) Set(field string, value interface{}) *SQLUpdateConstructor {
+	if uc.schema.GetField(field) == nil {
+		uc.setErr(fmt.Errorf("update %s: unknown field %s", uc.schema.TableName(), field))
+		return uc
+	}
+	for i := range uc.sets {
+		if uc.sets[i].field == field {
+			uc.sets[i].value = value
+			return uc
+		}
+	}
+	uc.sets = append(uc.sets, updateAssignment{field: field, value: value})
+	return uc
+}
+
+// Where 添加条件，多个条件之间使用 AND
+func (uc *SQLUpdateConstructor) Where(condition Condition) *SQLUpdateConstructor {
+	uc.conditions = append(uc.conditions, condition)
+	return uc
+}
+
+// Returning 追加 RETURNING 子句返回更新后的行，"*" 表示所有列
+// 仅支持 RETURNING 的方言（PostgreSQL、SQLite）可用，其他方言在 Build 时返回错误
+func (uc *SQLUpdateConstructor) Returning(fields ...string) *SQLUpdateConstructor {
+	uc.returning = append(uc.returning, fields...)
+	return uc
+}
+
+// AllowFullTableUpdate 允许不带 WHERE 的 UPDATE，默认 Build 会拒绝以免误更新整张表
+func (uc *SQLUpdateConstructor) AllowFullTableUpdate() *SQLUpdateConstructor {
+	uc.allowAll = true
+	return uc
+}
+
+// SkipUnchanged 跳过新值与当前行相同的赋值，避免无意义的写入（例如触发 updated_at 变化）
+// current 为当前行的字段值；传 nil 时 Repository.ExecUpdate 会先按 WHERE 查询当前行。
+// 所有赋值都被跳过时 Build 返回 ErrNoChanges，ExecUpdate 不执行任何语句
+func (uc *SQLUpdateConstructor) SkipUnchanged(current map[string]interface{}) *SQLUpdateConstructor {
+	uc.skipUnchanged = true
+	uc.current = current
+	return uc
+}
+
+// changedSets 返回需要写入的赋值，SkipUnchanged 时去掉与当前行相同的项
+func (uc *SQLUpdateConstructor) changedSets() []updateAssignment {
+	if !uc.skipUnchanged || uc.current == nil {
+		return uc.sets
+	}
+	sets := make([]updateAssignment, 0, len(uc.sets))
+	for _, set := range uc.sets {
+		if current, ok := uc.current[set.field]; ok && valuesEqual(current, set.value) {
+			continue
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// valuesEqual 比较数据库中的值与新值，数字按数值比较（int 与 int64 视为相同）
+func valuesEqual(a, b interface{}) bool {
+	if cmp, ok := compareLiterals(a, b); ok {
+		return cmp == 0
+	}
+	if ab, ok := a.([]byte); ok {
+		if bs, ok := b.(string); ok {
+			return string(ab) == bs
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// setErr 记录链式调用中的第一个错误
+func (uc *SQLUpdateConstructor) setErr(err error) {
+	if uc.err == nil {
+		uc.err = err
+	}
+}
+
+// Build 构建 UPDATE 语句
+// 绑定参数个数超过方言的 MaxParameters 时返回 *ParameterLimitError
+func (uc *SQLUpdateConstructor) Build(ctx context.Context) (string, []interface{}, error) {
+	if uc.err != nil {
+		return "", nil, uc.err
+	}
+	if len(uc.sets) == 0 {
+		return "", nil, fmt.Errorf("update %s requires at least one Set", uc.schema.TableName())
+	}
+	sets := uc.changedSets()
+	if len(sets) == 0 {
+		return "", nil, ErrNoChanges
+	}
+	if len(uc.conditions) == 0 && !uc.allowAll {
+		return "", nil, fmt.Errorf("update %s without WHERE would change every row, call AllowFullTableUpdate to confirm", uc.schema.TableName())
+	}
+	returning, err := returningClause(uc.dialect, uc.returning)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sql strings.Builder
+	var args []interface{}
+	argIndex := 1
+	translator := &DefaultSQLTranslator{dialect: uc.dialect, argIndex: &argIndex}
+
+	sql.WriteString("UPDATE ")
+	sql.WriteString(uc.dialect.QuoteIdentifier(uc.schema.TableName()))
+	sql.WriteString(" SET ")
+	for i, set := range sets {
+		value, err := uc.schema.GetField(set.field).StoreValue(set.value)
+		if err != nil {
+			return "", nil, err
+		}
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(uc.dialect.QuoteIdentifier(set.field) + " = ")
+		args = translator.writeValue(&sql, args, value)
+	}
+
+	if len(uc.conditions) > 0 {
+		sql.WriteString(" WHERE ")
+		for i, condition := range uc.conditions {
+			if i > 0 {
+				sql.WriteString(" AND ")
+			}
+			condSQL, condArgs, err := condition.Translate(translator)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to translate condition: %w", err)
+			}
+			sql.WriteString(condSQL)
+			args = append(args, condArgs...)
+		}
+	}
+	sql.WriteString(returning)
+
+	if max := uc.dialect.MaxParameters(); max > 0 && len(args) > max {
+		return "", nil, &ParameterLimitError{Count: len(args), Max: max}
+	}
+	recordSQL(sql.String(), args)
+	return sql.String(), args, nil
+}
+
+// ExecUpdate 构建并执行 UPDATE
+// 使用 SkipUnchanged(nil) 时先按 WHERE 查询当前行（只在恰好匹配一行时比较）；
+// 没有字段发生变化时不执行语句，返回影响行数为 0 的结果
+func (r *Repository) ExecUpdate(ctx context.Context, uc *SQLUpdateConstructor) (sql.Result, error) {
+	if uc.skipUnchanged && uc.current == nil && uc.err == nil && len(uc.conditions) > 0 {
+		current, err := r.currentRow(ctx, uc)
+		if err != nil {
+			return nil, err
+		}
+		uc.current = current
+	}
+
+	query, args, err := uc.Build(ctx)
+	if errors.Is(err, ErrNoChanges) {
+		return driver.RowsAffected(0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Exec(ctx, query, args...)
+}
+
+// currentRow 查询 UPDATE 将要修改的行中被赋值的字段，匹配的行数不是 1 时返回 nil
+func (r *Repository) currentRow(ctx context.Context, uc *SQLUpdateConstructor) (map[string]interface{}, error) {
+	fields := make([]string, len(uc.sets))
+	for i, set := range uc.sets {
+		fields[i] = set.field
+	}
+	qc := NewSQLQueryConstructor(uc.schema, uc.dialect)
+	qc.Select(fields...)
+	for _, condition := range uc.conditions {
+		qc.Where(condition)
+	}
+	qc.Limit(2)
+
+	query, args, err := qc.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current row: %w", err)
+	}
+	defer rows.Close()
+
+	current, err := ScanMaps(rows, uc.schema)
+	if err != nil {
+		return nil, err
+	}
+	if len(current) != 1 {
+		return nil, nil
+	}
+	return current[0], nil
+}
+
+// UpdateOptions Repository.UpdateWithOptions 的更新选项
+type UpdateOptions struct {
+	// SkipUnchanged 先与当前行比较，去掉值没有变化的字段；全部相同时不执行 UPDATE，返回影响行数为 0 的结果
+	SkipUnchanged bool
+	// Current 当前行的字段值，为 nil 时按主键查询（见 SQLUpdateConstructor.SkipUnchanged）
+	Current map[string]interface{}
+}
+
+// Update 按 changeset 更新一行：SET 只包含已变更的字段，WHERE 为主键 = cs.Get(主键)
+// changeset 无效、没有主键值或没有变更（主键本身不会写入 SET）时返回错误，不执行语句
+func (r *Repository) Update(ctx context.Context, cs *Changeset) (sql.Result, error) {
+	return r.UpdateWithOptions(ctx, cs, nil)
+}
+
+// UpdateWithOptions 与 Update 相同，opts 为 nil 时使用默认选项
+func (r *Repository) UpdateWithOptions(ctx context.Context, cs *Changeset, opts *UpdateOptions) (sql.Result, error) {
+	if opts == nil {
+		opts = &UpdateOptions{}
+	}
+	if !cs.IsValid() {
+		return nil, fmt.Errorf("changeset 验证失败: %v", cs.Errors())
+	}
+	table := cs.schema.TableName()
+	pk := cs.schema.PrimaryKeyField()
+	if pk == nil {
+		return nil, fmt.Errorf("update %s: table has no primary key", table)
+	}
+	id := cs.Get(pk.Name)
+	if id == nil {
+		return nil, fmt.Errorf("update %s: changeset has no value for primary key %s", table, pk.Name)
+	}
+	dialect := r.sqlDialect()
+	if dialect == nil {
+		return nil, fmt.Errorf("Update: adapter %T does not provide a SQL dialect", r.GetAdapter())
+	}
+
+	changes := cs.Changes()
+	uc := NewSQLUpdateConstructor(cs.schema, dialect)
+	for _, field := range cs.schema.Fields() {
+		if value, ok := changes[field.Name]; ok && field.Name != pk.Name {
+			uc.Set(field.Name, value)
+		}
+	}
+	if len(uc.sets) == 0 {
+		return nil, fmt.Errorf("update %s: %w", table, ErrNoChanges)
+	}
+	uc.Where(Eq(pk.Name, id))
+	if opts.SkipUnchanged {
+		uc.SkipUnchanged(opts.Current)
+	}
+	return r.ExecUpdate(ctx, uc)
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSQLUpdateConstructor 测试 SET 参数在 WHERE 参数之前且占位符连续编号
+func TestSQLUpdateConstructor(t *testing.T) {
+	schema := newInsertTestSchema()
+
+	tests := []struct {
+		dialect  SQLDialect
+		expected string
+	}{
+		{NewMySQLDialect(), "UPDATE `users` SET `name` = ?, `age` = ? WHERE `id` = ? AND `age` < ?"},
+		{NewPostgreSQLDialect(), `UPDATE "users" SET "name" = $1, "age" = $2 WHERE "id" = $3 AND "age" < $4`},
+	}
+	for _, tt := range tests {
+		sql, args, err := NewSQLUpdateConstructor(schema, tt.dialect).
+			Set("name", "alice").
+			Set("age", 31).
+			Where(Eq("id", 7)).
+			Where(Lt("age", 31)).
+			Build(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.dialect.Name(), tt.expected, sql)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"alice", 31, 7, 31}) {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+	}
+}
+
+// TestSQLUpdateConstructorGuards 测试缺少 SET、缺少 WHERE 和未知字段
+func TestSQLUpdateConstructorGuards(t *testing.T) {
+	schema := newInsertTestSchema()
+	dialect := NewPostgreSQLDialect()
+	ctx := context.Background()
+
+	if _, _, err := NewSQLUpdateConstructor(schema, dialect).Where(Eq("id", 1)).Build(ctx); err == nil {
+		t.Error("Expected error for update without Set")
+	}
+	if _, _, err := NewSQLUpdateConstructor(schema, dialect).Set("age", 1).Build(ctx); err == nil {
+		t.Error("Expected error for update without WHERE")
+	}
+	if _, _, err := NewSQLUpdateConstructor(schema, dialect).Set("nickname", "x").Where(Eq("id", 1)).Build(ctx); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+
+	sql, args, err := NewSQLUpdateConstructor(schema, dialect).Set("age", 0).AllowFullTableUpdate().Build(ctx)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if sql != `UPDATE "users" SET "age" = $1` || len(args) != 1 {
+		t.Errorf("Unexpected full table update: %s %v", sql, args)
+	}
+}
+
+// TestExecUpdateSkipUnchanged 测试没有变化时不执行 UPDATE，部分变化时只更新不同的列
+func TestExecUpdateSkipUnchanged(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "skip_unchanged.db")
+	if _, err := repo.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO users (id, name, age) VALUES (1, 'alice', 30)"); err != nil {
+		t.Fatal(err)
+	}
+
+	var updates []string
+	repo.Use(func(next QueryFunc) QueryFunc {
+		return func(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+			if strings.HasPrefix(op.SQL, "UPDATE") {
+				updates = append(updates, op.SQL)
+			}
+			return next(ctx, op)
+		}
+	})
+
+	schema := newInsertTestSchema()
+	dialect := NewSQLiteDialect()
+	result, err := repo.ExecUpdate(ctx, NewSQLUpdateConstructor(schema, dialect).
+		Set("name", "alice").Set("age", 30).Where(Eq("id", 1)).SkipUnchanged(nil))
+	if err != nil {
+		t.Fatalf("ExecUpdate failed: %v", err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 0 || len(updates) != 0 {
+		t.Errorf("Expected no UPDATE for unchanged row, got %d rows and %v", affected, updates)
+	}
+
+	result, err = repo.ExecUpdate(ctx, NewSQLUpdateConstructor(schema, dialect).
+		Set("name", "alice").Set("age", 31).Where(Eq("id", 1)).SkipUnchanged(nil))
+	if err != nil {
+		t.Fatalf("ExecUpdate failed: %v", err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 1 {
+		t.Errorf("Expected 1 row affected, got %d", affected)
+	}
+	if len(updates) != 1 || updates[0] != "UPDATE `users` SET `age` = ? WHERE `id` = ?" {
+		t.Errorf("Expected only age to be updated, got %v", updates)
+	}
+
+	_, _, err = NewSQLUpdateConstructor(schema, dialect).
+		Set("name", "bob").Where(Eq("id", 1)).
+		SkipUnchanged(map[string]interface{}{"name": "bob"}).Build(ctx)
+	if !errors.Is(err, ErrNoChanges) {
+		t.Errorf("Expected ErrNoChanges for supplied row, got %v", err)
+	}
+}
+
+// TestRepositoryUpdateChangeset 测试由 changeset 更新：只写入变更字段，按主键定位
+func TestRepositoryUpdateChangeset(t *testing.T) {
+	ctx := context.Background()
+	schema := newInsertTestSchema()
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+
+	cs := FromMap(schema, map[string]interface{}{"id": int64(7), "name": "alice", "age": int64(30)}).
+		Cast(map[string]interface{}{"age": 31})
+	if _, err := repo.Update(ctx, cs); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	expected := []string{`UPDATE "users" SET "age" = $1 WHERE "id" = $2`}
+	if statements := fake.Statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected %v, got %v", expected, statements)
+	}
+	if args := fake.LastArgs(); !reflect.DeepEqual(args, []interface{}{int64(31), int64(7)}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	repo, fake = newFakeRepository(NewPostgreSQLDialect())
+	unchanged := FromMap(schema, map[string]interface{}{"id": int64(7), "name": "alice"})
+	if _, err := repo.Update(ctx, unchanged); !errors.Is(err, ErrNoChanges) {
+		t.Errorf("Expected ErrNoChanges, got %v", err)
+	}
+	missingPK := NewChangeset(schema).Cast(map[string]interface{}{"name": "bob"})
+	if _, err := repo.Update(ctx, missingPK); err == nil {
+		t.Error("Expected error without a primary key value")
+	}
+	if statements := fake.Statements(); len(statements) != 0 {
+		t.Errorf("Expected no statements, got %v", statements)
+	}
+}
+
+// TestRepositoryUpdateSkipUnchanged 测试 changeset 更新跳过与当前行相同的字段
+func TestRepositoryUpdateSkipUnchanged(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t, "update_skip_unchanged.db")
+	if _, err := repo.Exec(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Exec(ctx, "INSERT INTO users (id, name, age) VALUES (1, 'alice', 30)"); err != nil {
+		t.Fatal(err)
+	}
+
+	var updates []string
+	repo.Use(func(next QueryFunc) QueryFunc {
+		return func(ctx context.Context, op *QueryOperation) (*QueryResult, error) {
+			if strings.HasPrefix(op.SQL, "UPDATE") {
+				updates = append(updates, op.SQL)
+			}
+			return next(ctx, op)
+		}
+	})
+
+	schema := newInsertTestSchema()
+	opts := &UpdateOptions{SkipUnchanged: true}
+	noop := NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "name": "alice", "age": 30})
+	result, err := repo.UpdateWithOptions(ctx, noop, opts)
+	if err != nil {
+		t.Fatalf("UpdateWithOptions failed: %v", err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 0 || len(updates) != 0 {
+		t.Errorf("Expected no UPDATE for unchanged changeset, got %d rows and %v", affected, updates)
+	}
+
+	partial := NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "name": "alice", "age": 31})
+	if _, err := repo.UpdateWithOptions(ctx, partial, opts); err != nil {
+		t.Fatalf("UpdateWithOptions failed: %v", err)
+	}
+	if len(updates) != 1 || updates[0] != "UPDATE `users` SET `age` = ? WHERE `id` = ?" {
+		t.Errorf("Expected only age to be updated, got %v", updates)
+	}
+
+	supplied := &UpdateOptions{SkipUnchanged: true, Current: map[string]interface{}{"name": "bob"}}
+	renamed := NewChangeset(schema).Cast(map[string]interface{}{"id": 1, "name": "bob"})
+	if result, err := repo.UpdateWithOptions(ctx, renamed, supplied); err != nil {
+		t.Fatalf("UpdateWithOptions failed: %v", err)
+	} else if affected, _ := result.RowsAffected(); affected != 0 || len(updates) != 1 {
+		t.Errorf("Expected supplied row to skip the UPDATE, got %d rows and %v", affected, updates)
+	}
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ==================== UPSERT ====================
+
+// UniqueIndexSchema 可以声明多列唯一索引的 Schema（BaseSchema 已实现）
+type UniqueIndexSchema interface {
+	Schema
+	UniqueIndexes() [][]string
+}
+
+// UpsertClause INSERT 的冲突处理子句
+// PostgreSQL/SQLite 渲染为 ON CONFLICT (...) DO UPDATE SET ...，
+// MySQL 渲染为 ON DUPLICATE KEY UPDATE ...
+type UpsertClause struct {
+	ConflictColumns []string
+	UpdateColumns   []string // 为空时表示冲突时什么都不做
+}
+
+// OnConflict 创建 UPSERT 子句，columns 为冲突目标列
+func OnConflict(columns ...string) *UpsertClause {
+	return &UpsertClause{ConflictColumns: columns}
+}
+
+// DoUpdate 冲突时用新值更新这些列
+func (u *UpsertClause) DoUpdate(columns ...string) *UpsertClause {
+	u.UpdateColumns = append(u.UpdateColumns, columns...)
+	return u
+}
+
+// DoNothing 冲突时忽略新行
+func (u *UpsertClause) DoNothing() *UpsertClause {
+	u.UpdateColumns = nil
+	return u
+}
+
+// Build 生成冲突处理子句
+// 提供 schema 时先校验冲突目标：必须与主键或某个唯一索引完全对应，
+// 否则 PostgreSQL 会在执行时报 "no unique or exclusion constraint matching the ON CONFLICT specification"
+func (u *UpsertClause) Build(schema Schema, dialect SQLDialect) (string, error) {
+	if schema != nil {
+		if len(u.ConflictColumns) > 0 {
+			if err := ValidateConflictTarget(schema, u.ConflictColumns); err != nil {
+				return "", err
+			}
+		}
+		for _, col := range u.UpdateColumns {
+			if schema.GetField(col) == nil {
+				return "", fmt.Errorf("upsert: update column %s is not a field of %s", col, schema.TableName())
+			}
+		}
+	}
+	return dialect.GenerateUpsert(u.ConflictColumns, u.UpdateColumns)
+}
+
+// ValidateConflictTarget 校验冲突目标列与 schema 声明的主键或唯一索引一致（与顺序无关）
+// 可作为冲突目标的有：全部主键字段、单个 Unique 字段、AddUniqueIndex 声明的索引
+func ValidateConflictTarget(schema Schema, columns []string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("upsert: conflict target cannot be empty")
+	}
+	for _, col := range columns {
+		if schema.GetField(col) == nil {
+			return fmt.Errorf("upsert: conflict column %s is not a field of %s", col, schema.TableName())
+		}
+	}
+
+	target := columnSetKey(columns)
+	for _, candidate := range uniqueKeyCandidates(schema) {
+		if columnSetKey(candidate) == target {
+			return nil
+		}
+	}
+	return fmt.Errorf("upsert: conflict target (%s) does not match the primary key or a unique index of %s",
+		strings.Join(columns, ", "), schema.TableName())
+}
+
+// uniqueKeyCandidates 返回 schema 中所有可作为冲突目标的列组合
+func uniqueKeyCandidates(schema Schema) [][]string {
+	candidates := make([][]string, 0)
+
+	primary := make([]string, 0)
+	for _, field := range schema.Fields() {
+		if field.Primary {
+			primary = append(primary, field.Name)
+		}
+		if field.Unique {
+			candidates = append(candidates, []string{field.Name})
+		}
+	}
+	if len(primary) > 0 {
+		candidates = append(candidates, primary)
+	}
+	if indexed, ok := schema.(UniqueIndexSchema); ok {
+		candidates = append(candidates, indexed.UniqueIndexes()...)
+	}
+	return candidates
+}
+
+func columnSetKey(columns []string) string {
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "\x00")
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func newUpsertTestSchema() *BaseSchema {
+	schema := NewBaseSchema("memberships")
+	schema.AddField(NewField("id", TypeInteger).PrimaryKey().Build())
+	schema.AddField(NewField("email", TypeString).Unique().Build())
+	schema.AddField(NewField("org_id", TypeInteger).Build())
+	schema.AddField(NewField("user_id", TypeInteger).Build())
+	schema.AddField(NewField("role", TypeString).Build())
+	schema.AddUniqueIndex("org_id", "user_id")
+	return schema
+}
+
+// TestValidateConflictTarget 测试冲突目标必须对应主键或唯一索引
+func TestValidateConflictTarget(t *testing.T) {
+	schema := newUpsertTestSchema()
+
+	valid := [][]string{
+		{"id"},
+		{"email"},
+		{"org_id", "user_id"},
+		{"user_id", "org_id"}, // 与顺序无关
+	}
+	for _, cols := range valid {
+		if err := ValidateConflictTarget(schema, cols); err != nil {
+			t.Errorf("Expected %v to be a valid conflict target, got %v", cols, err)
+		}
+	}
+
+	invalid := [][]string{
+		{"role"},
+		{"org_id"},
+		{"email", "role"},
+		{"missing"},
+		{},
+	}
+	for _, cols := range invalid {
+		if err := ValidateConflictTarget(schema, cols); err == nil {
+			t.Errorf("Expected %v to be rejected as conflict target", cols)
+		}
+	}
+}
+
+// TestUpsertClauseBuild 测试各方言的冲突处理子句
+func TestUpsertClauseBuild(t *testing.T) {
+	schema := newUpsertTestSchema()
+
+	tests := []struct {
+		dialect  SQLDialect
+		clause   *UpsertClause
+		expected string
+	}{
+		{NewPostgreSQLDialect(), OnConflict("org_id", "user_id").DoUpdate("role"),
+			`ON CONFLICT ("org_id", "user_id") DO UPDATE SET "role" = EXCLUDED."role"`},
+		{NewPostgreSQLDialect(), OnConflict("email").DoNothing(), `ON CONFLICT ("email") DO NOTHING`},
+		{NewSQLiteDialect(), OnConflict("email").DoUpdate("role"),
+			"ON CONFLICT (`email`) DO UPDATE SET `role` = EXCLUDED.`role`"},
+		{NewMySQLDialect(), OnConflict("email").DoUpdate("role", "org_id"),
+			"ON DUPLICATE KEY UPDATE `role` = VALUES(`role`), `org_id` = VALUES(`org_id`)"},
+		{NewMySQLDialect(), OnConflict("email"), "ON DUPLICATE KEY UPDATE `email` = `email`"},
+	}
+
+	for _, tt := range tests {
+		sql, err := tt.clause.Build(schema, tt.dialect)
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tt.dialect.Name(), err)
+		}
+		if sql != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.dialect.Name(), tt.expected, sql)
+		}
+	}
+
+	// 任意列作为冲突目标在构建时报错
+	_, err := OnConflict("role").DoUpdate("email").Build(schema, NewPostgreSQLDialect())
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected conflict target error, got %v", err)
+	}
+
+	// 没有 schema 时不做校验
+	if _, err := OnConflict("role").DoUpdate("email").Build(nil, NewPostgreSQLDialect()); err != nil {
+		t.Errorf("Expected build without schema to skip validation, got %v", err)
+	}
+
+	if _, err := OnConflict("id").DoUpdate("role").Build(schema, NewSQLServerDialect()); err == nil {
+		t.Error("Expected SQL Server upsert to be unsupported")
+	}
+}
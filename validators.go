@@ -2,11 +2,13 @@ package db
 
 import (
 	"fmt"
-	"regexp"
 )
 
 // ==================== 常用验证器 ====================
 
+// emailPattern EmailValidator 使用的邮箱格式
+const emailPattern = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
+
 // EmailValidator 邮箱格式验证器
 type EmailValidator struct{}
 
@@ -16,9 +18,8 @@ func (v *EmailValidator) Validate(value interface{}) error {
 		return NewValidationError("email", "邮箱必须是字符串")
 	}
 
-	pattern := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
-	matched, _ := regexp.MatchString(pattern, str)
-	if !matched {
+	re, err := CompileRegex(emailPattern)
+	if err != nil || !re.MatchString(str) {
 		return NewValidationError("email", "邮箱格式不正确")
 	}
 	return nil
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// sqlDB 返回适配器底层的 *sql.DB（直接持有或通过 GORM 的 DB() 获取），非 SQL 适配器返回 nil
+func (r *Repository) sqlDB() *sql.DB {
+	adapter := r.GetAdapter()
+	if adapter == nil {
+		return nil
+	}
+	switch raw := adapter.GetRawConn().(type) {
+	case *sql.DB:
+		return raw
+	case interface{ DB() (*sql.DB, error) }:
+		db, err := raw.DB()
+		if err != nil {
+			return nil
+		}
+		return db
+	}
+	return nil
+}
+
+// defaultMaxIdleConns database/sql 默认的空闲连接上限
+const defaultMaxIdleConns = 2
+
+// idleLimits 记录通过 Repository.SetMaxIdleConns / WarmPool 设置的空闲连接上限（*sql.DB -> int），
+// database/sql 不提供读取该配置的方法
+var idleLimits sync.Map
+
+// SetMaxIdleConns 设置连接池的空闲连接上限，并记录该值供 WarmPool 判断是否需要调高
+func (r *Repository) SetMaxIdleConns(n int) error {
+	db := r.sqlDB()
+	if db == nil {
+		return fmt.Errorf("SetMaxIdleConns: adapter %T does not expose a connection pool", r.GetAdapter())
+	}
+	db.SetMaxIdleConns(n)
+	idleLimits.Store(db, n)
+	return nil
+}
+
+// maxIdleConns 返回已记录的空闲连接上限，未设置过时为 database/sql 的默认值
+func maxIdleConns(db *sql.DB) int {
+	if n, ok := idleLimits.Load(db); ok {
+		return n.(int)
+	}
+	return defaultMaxIdleConns
+}
+
+// WarmPool 预先建立 n 个连接并放回连接池，避免冷启动后的前 n 个请求承担建连延迟
+// n 不超过连接池的最大连接数。database/sql 默认只保留 2 个空闲连接，空闲上限小于 n 时会调高到 n，
+// 不会降低已配置的上限（直接在 *sql.DB 上设置的值无法读取，需要通过 Repository.SetMaxIdleConns 设置）。
+// 所有连接并发建立（每个执行一次 Ping），ctx 取消时立即返回错误
+func (r *Repository) WarmPool(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	db := r.sqlDB()
+	if db == nil {
+		return fmt.Errorf("WarmPool: adapter %T does not expose a connection pool", r.GetAdapter())
+	}
+	if max := db.Stats().MaxOpenConnections; max > 0 && n > max {
+		n = max
+	}
+	if n > maxIdleConns(db) {
+		db.SetMaxIdleConns(n)
+		idleLimits.Store(db, n)
+	}
+
+	conns := make([]*sql.Conn, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			conns[i] = conn
+			errs[i] = conn.PingContext(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	// 所有连接都建立后再一起归还，否则先归还的连接会被后面的请求复用
+	for _, conn := range conns {
+		if conn != nil {
+			conn.Close()
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("WarmPool: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// TestWarmPool 测试预热建立 n 个连接并保留为空闲连接，且不超过最大连接数
+func TestWarmPool(t *testing.T) {
+	repo, fake := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+	db := repo.GetAdapter().GetRawConn().(*sql.DB)
+
+	if err := repo.WarmPool(context.Background(), 4); err != nil {
+		t.Fatalf("WarmPool failed: %v", err)
+	}
+	if fake.pings != 4 {
+		t.Errorf("Expected 4 pings, got %d", fake.pings)
+	}
+	if stats := db.Stats(); stats.OpenConnections != 4 || stats.Idle != 4 {
+		t.Errorf("Expected 4 idle connections, got %d open / %d idle", stats.OpenConnections, stats.Idle)
+	}
+
+	repo, fake = newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+	db = repo.GetAdapter().GetRawConn().(*sql.DB)
+	db.SetMaxOpenConns(3)
+	if err := repo.WarmPool(context.Background(), 10); err != nil {
+		t.Fatalf("WarmPool failed: %v", err)
+	}
+	if fake.pings != 3 {
+		t.Errorf("Expected pings bounded by max connections 3, got %d", fake.pings)
+	}
+	if open := db.Stats().OpenConnections; open > 3 {
+		t.Errorf("Expected at most 3 open connections, got %d", open)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := repo.WarmPool(ctx, 2); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+}
+
+// TestWarmPoolKeepsIdleLimit 测试预热不会降低已配置的空闲连接上限
+func TestWarmPoolKeepsIdleLimit(t *testing.T) {
+	repo, _ := newFakeRepository(NewPostgreSQLDialect())
+	defer repo.Close()
+	db := repo.GetAdapter().GetRawConn().(*sql.DB)
+
+	if err := repo.SetMaxIdleConns(10); err != nil {
+		t.Fatalf("SetMaxIdleConns failed: %v", err)
+	}
+	if err := repo.WarmPool(context.Background(), 3); err != nil {
+		t.Fatalf("WarmPool failed: %v", err)
+	}
+	if limit := maxIdleConns(db); limit != 10 {
+		t.Errorf("Expected idle limit to stay at 10, got %d", limit)
+	}
+	if err := repo.WarmPool(context.Background(), 12); err != nil {
+		t.Fatalf("WarmPool failed: %v", err)
+	}
+	if limit := maxIdleConns(db); limit != 12 {
+		t.Errorf("Expected idle limit to be raised to 12, got %d", limit)
+	}
+}